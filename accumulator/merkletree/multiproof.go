@@ -0,0 +1,378 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkletree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"sort"
+)
+
+var (
+	ErrNoLeaves      = errors.New("merkletree: the tree should contain at least one leaf")
+	ErrIndexRange    = errors.New("merkletree: leaf index out of range")
+	ErrNodeSize      = errors.New("merkletree: the nodes of a proof should have the same size")
+	ErrProofTooLarge = errors.New("merkletree: the proof is too large")
+)
+
+// MemoryTree is a Merkle tree whose nodes are all kept in memory. It is the same tree as the
+// Tree built with the same leaves, but it can prove the membership of several leaves at once,
+// with MultiProof, and commit to a Merkle cap instead of the root.
+//
+// The Merkle cap of height k is the first layer of the tree, from the leaves, with at most 2ᵏ
+// nodes. The cap of height 0 is the Merkle root. Proofs against a cap of height k are k nodes
+// shorter than proofs against the root.
+type MemoryTree struct {
+	h hash.Hash
+
+	// layers[0] contains the hashes of the leaves, and layers[len(layers)-1] the root.
+	// An odd node at the end of a layer is moved up to the next layer, as in Tree.
+	layers [][][]byte
+}
+
+// MultiProof proves the membership of several leaves in a Merkle tree, the nodes shared by
+// the Merkle paths of the leaves appearing once.
+type MultiProof struct {
+
+	// Leaves data of the leaves, sorted by index, without duplicates
+	Leaves [][]byte
+
+	// Nodes the siblings of the Merkle paths that can't be computed from the leaves,
+	// layer by layer from the leaves to the cap, sorted by index in each layer
+	Nodes [][]byte
+}
+
+// NewMemoryTree builds the Merkle tree of the leaves
+func NewMemoryTree(h hash.Hash, leaves [][]byte) (*MemoryTree, error) {
+	if len(leaves) == 0 {
+		return nil, ErrNoLeaves
+	}
+	t := &MemoryTree{h: h}
+	layer := make([][]byte, len(leaves))
+	for i := range leaves {
+		layer[i] = leafSum(h, leaves[i])
+	}
+	t.layers = append(t.layers, layer)
+	for len(layer) > 1 {
+		next := make([][]byte, (len(layer)+1)/2)
+		for i := range next {
+			if 2*i+1 < len(layer) {
+				next[i] = nodeSum(h, layer[2*i], layer[2*i+1])
+			} else {
+				next[i] = layer[2*i]
+			}
+		}
+		t.layers = append(t.layers, next)
+		layer = next
+	}
+	return t, nil
+}
+
+// Root returns the Merkle root of the tree
+func (t *MemoryTree) Root() []byte {
+	return t.layers[len(t.layers)-1][0]
+}
+
+// NumLeaves returns the number of leaves of the tree
+func (t *MemoryTree) NumLeaves() uint64 {
+	return uint64(len(t.layers[0]))
+}
+
+// Cap returns the Merkle cap of height capHeight
+func (t *MemoryTree) Cap(capHeight int) [][]byte {
+	return t.layers[capLayer(t.NumLeaves(), capHeight)]
+}
+
+// Prove returns the Merkle path [leaf ∥ node_1 ∥ ..] of the leaf at index, against the Merkle
+// cap of height capHeight. If capHeight is 0, it is the proof set of Tree.Prove, which can be
+// verified with VerifyProof.
+func (t *MemoryTree) Prove(leaf []byte, index uint64, capHeight int) ([][]byte, error) {
+	proof, err := t.ProveMulti([][]byte{leaf}, []uint64{index}, capHeight)
+	if err != nil {
+		return nil, err
+	}
+	return append(proof.Leaves, proof.Nodes...), nil
+}
+
+// ProveMulti returns the MultiProof of the leaves at indices, against the Merkle cap of height
+// capHeight. leaves[i] is the data of the leaf at indices[i]; the indices can be in any order,
+// and contain duplicates.
+func (t *MemoryTree) ProveMulti(leaves [][]byte, indices []uint64, capHeight int) (MultiProof, error) {
+	var proof MultiProof
+	if len(leaves) != len(indices) {
+		return proof, ErrIndexRange
+	}
+
+	// sort the leaves by index, without duplicates
+	sorted, order := sortIndices(indices)
+	proof.Leaves = make([][]byte, len(sorted))
+	for i, j := range order {
+		if indices[j] >= t.NumLeaves() {
+			return proof, ErrIndexRange
+		}
+		proof.Leaves[i] = leaves[j]
+	}
+
+	// at each layer, the siblings which are not known are added to the proof
+	top := capLayer(t.NumLeaves(), capHeight)
+	for l := 0; l < top; l++ {
+		layer := t.layers[l]
+		for i := 0; i < len(sorted); i++ {
+			sibling := sorted[i] ^ 1
+			switch {
+			case sibling >= uint64(len(layer)):
+				// the node is moved up
+			case i+1 < len(sorted) && sorted[i+1] == sibling:
+				// the sibling is known
+				i++
+			default:
+				proof.Nodes = append(proof.Nodes, layer[sibling])
+			}
+		}
+		sorted = parents(sorted)
+	}
+
+	return proof, nil
+}
+
+// VerifyMultiProof returns true if proof proves that proof.Leaves are the leaves at indices
+// of a Merkle tree with numLeaves leaves, whose Merkle cap is merkleCap. The indices can be
+// in any order and contain duplicates, proof.Leaves being sorted by index without duplicates,
+// see ProveMulti.
+func VerifyMultiProof(h hash.Hash, merkleCap [][]byte, indices []uint64, proof MultiProof, numLeaves uint64) bool {
+	if numLeaves == 0 {
+		return false
+	}
+	sorted, _ := sortIndices(indices)
+	if len(sorted) == 0 || len(proof.Leaves) != len(sorted) || sorted[len(sorted)-1] >= numLeaves {
+		return false
+	}
+
+	// the cap should be a full layer of the tree
+	capHeight := 0
+	for uint64(1)<<capHeight < uint64(len(merkleCap)) {
+		capHeight++
+	}
+	top := capLayer(numLeaves, capHeight)
+	if layerSize(numLeaves, top) != uint64(len(merkleCap)) {
+		return false
+	}
+
+	// recompute the nodes, layer by layer
+	nodes := make([][]byte, len(sorted))
+	for i := range proof.Leaves {
+		nodes[i] = leafSum(h, proof.Leaves[i])
+	}
+	next := 0
+	for l := 0; l < top; l++ {
+		size := layerSize(numLeaves, l)
+		parentNodes := make([][]byte, 0, len(nodes))
+		for i := 0; i < len(sorted); i++ {
+			sibling := sorted[i] ^ 1
+			switch {
+			case sibling >= size:
+				parentNodes = append(parentNodes, nodes[i])
+			case i+1 < len(sorted) && sorted[i+1] == sibling:
+				parentNodes = append(parentNodes, nodeSum(h, nodes[i], nodes[i+1]))
+				i++
+			default:
+				if next >= len(proof.Nodes) {
+					return false
+				}
+				if sibling < sorted[i] {
+					parentNodes = append(parentNodes, nodeSum(h, proof.Nodes[next], nodes[i]))
+				} else {
+					parentNodes = append(parentNodes, nodeSum(h, nodes[i], proof.Nodes[next]))
+				}
+				next++
+			}
+		}
+		nodes = parentNodes
+		sorted = parents(sorted)
+	}
+	if next != len(proof.Nodes) {
+		return false
+	}
+
+	for i := range sorted {
+		if !bytes.Equal(nodes[i], merkleCap[sorted[i]]) {
+			return false
+		}
+	}
+	return true
+}
+
+// layerSize returns the number of nodes of the l-th layer of a tree with numLeaves leaves
+func layerSize(numLeaves uint64, l int) uint64 {
+	for ; l > 0; l-- {
+		numLeaves = (numLeaves + 1) / 2
+	}
+	return numLeaves
+}
+
+// capLayer returns the index of the first layer with at most 2^capHeight nodes
+func capLayer(numLeaves uint64, capHeight int) int {
+	l := 0
+	for capHeight < 64 && layerSize(numLeaves, l) > uint64(1)<<capHeight {
+		l++
+	}
+	return l
+}
+
+// sortIndices returns the sorted indices without duplicates, and order, such that
+// sorted[i] = indices[order[i]]
+func sortIndices(indices []uint64) (sorted []uint64, order []int) {
+	order = make([]int, len(indices))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return indices[order[i]] < indices[order[j]] })
+	sorted = make([]uint64, 0, len(indices))
+	unique := order[:0]
+	for _, j := range order {
+		if len(sorted) > 0 && sorted[len(sorted)-1] == indices[j] {
+			continue
+		}
+		sorted = append(sorted, indices[j])
+		unique = append(unique, j)
+	}
+	return sorted, unique
+}
+
+// parents returns the sorted indices of the parents of the sorted nodes, without duplicates
+func parents(sorted []uint64) []uint64 {
+	res := make([]uint64, 0, len(sorted))
+	for _, i := range sorted {
+		if len(res) == 0 || res[len(res)-1] != i/2 {
+			res = append(res, i/2)
+		}
+	}
+	return res
+}
+
+// WriteTo writes the binary encoding of the MultiProof: the number of leaves (uint32), each
+// leaf prefixed by its size (uint32), the number of nodes (uint32), the size of the nodes
+// (uint32), and the concatenated nodes. The integers are big endian.
+func (proof *MultiProof) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	write := func(b []byte) error {
+		written, err := w.Write(b)
+		n += int64(written)
+		return err
+	}
+	writeUint32 := func(v int) error {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], uint32(v))
+		return write(buf[:])
+	}
+
+	if err := writeUint32(len(proof.Leaves)); err != nil {
+		return n, err
+	}
+	for i := range proof.Leaves {
+		if err := writeUint32(len(proof.Leaves[i])); err != nil {
+			return n, err
+		}
+		if err := write(proof.Leaves[i]); err != nil {
+			return n, err
+		}
+	}
+
+	if err := writeUint32(len(proof.Nodes)); err != nil {
+		return n, err
+	}
+	if len(proof.Nodes) == 0 {
+		return n, nil
+	}
+	nodeSize := len(proof.Nodes[0])
+	for i := range proof.Nodes {
+		if len(proof.Nodes[i]) != nodeSize {
+			return n, ErrNodeSize
+		}
+	}
+	if err := writeUint32(nodeSize); err != nil {
+		return n, err
+	}
+	for i := range proof.Nodes {
+		if err := write(proof.Nodes[i]); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// maxProofSize upper bound on the lengths read while decoding a MultiProof
+const maxProofSize = 1 << 20
+
+// ReadFrom decodes a MultiProof from reader
+func (proof *MultiProof) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	read := func(b []byte) error {
+		_n, err := io.ReadFull(r, b)
+		n += int64(_n)
+		return err
+	}
+	readUint32 := func() (int, error) {
+		var buf [4]byte
+		if err := read(buf[:]); err != nil {
+			return 0, err
+		}
+		v := binary.BigEndian.Uint32(buf[:])
+		if v > maxProofSize {
+			return 0, ErrProofTooLarge
+		}
+		return int(v), nil
+	}
+
+	nbLeaves, err := readUint32()
+	if err != nil {
+		return n, err
+	}
+	proof.Leaves = make([][]byte, nbLeaves)
+	for i := range proof.Leaves {
+		size, err := readUint32()
+		if err != nil {
+			return n, err
+		}
+		proof.Leaves[i] = make([]byte, size)
+		if err := read(proof.Leaves[i]); err != nil {
+			return n, err
+		}
+	}
+
+	nbNodes, err := readUint32()
+	if err != nil {
+		return n, err
+	}
+	proof.Nodes = nil
+	if nbNodes == 0 {
+		return n, nil
+	}
+	nodeSize, err := readUint32()
+	if err != nil {
+		return n, err
+	}
+	proof.Nodes = make([][]byte, nbNodes)
+	for i := range proof.Nodes {
+		proof.Nodes[i] = make([]byte, nodeSize)
+		if err := read(proof.Nodes[i]); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merkletree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func randomLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = make([]byte, 10)
+		rand.Read(leaves[i])
+	}
+	return leaves
+}
+
+func TestMemoryTree(t *testing.T) {
+	h := sha256.New()
+	for n := 1; n <= 33; n++ {
+		leaves := randomLeaves(n)
+		tree, err := NewMemoryTree(h, leaves)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// same root and proofs as Tree
+		for index := 0; index < n; index++ {
+			streaming := New(h)
+			if err := streaming.SetIndex(uint64(index)); err != nil {
+				t.Fatal(err)
+			}
+			for i := range leaves {
+				streaming.Push(leaves[i])
+			}
+			root, proofSet, _, numLeaves := streaming.Prove()
+			if !bytes.Equal(root, tree.Root()) {
+				t.Fatal("the root should be the one of Tree")
+			}
+			_proofSet, err := tree.Prove(leaves[index], uint64(index), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(proofSet, _proofSet) {
+				t.Fatal("the proof should be the one of Tree")
+			}
+			if !VerifyProof(h, root, _proofSet, uint64(index), numLeaves) {
+				t.Fatal("the proof should be valid")
+			}
+		}
+	}
+}
+
+func TestMultiProof(t *testing.T) {
+	h := sha256.New()
+	for _, n := range []int{1, 6, 7, 64, 100} {
+		leaves := randomLeaves(n)
+		tree, err := NewMemoryTree(h, leaves)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// random indices, with duplicates
+		indices := make([]uint64, 10)
+		opened := make([][]byte, len(indices))
+		for i := range indices {
+			indices[i] = uint64(rand.Intn(n))
+			opened[i] = leaves[indices[i]]
+		}
+
+		for capHeight := 0; capHeight < 4; capHeight++ {
+			merkleCap := tree.Cap(capHeight)
+			if len(merkleCap) > 1<<capHeight {
+				t.Fatal("the cap is too large")
+			}
+			proof, err := tree.ProveMulti(opened, indices, capHeight)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !VerifyMultiProof(h, merkleCap, indices, proof, uint64(n)) {
+				t.Fatal("the multi proof should be valid")
+			}
+
+			// the shared nodes appear once
+			var nbNodes int
+			for i := range indices {
+				path, err := tree.Prove(leaves[indices[i]], indices[i], capHeight)
+				if err != nil {
+					t.Fatal(err)
+				}
+				nbNodes += len(path) - 1
+			}
+			if len(proof.Nodes) > nbNodes {
+				t.Fatal("the multi proof should not be larger than the paths")
+			}
+
+			// serialization
+			var buf bytes.Buffer
+			written, err := proof.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var _proof MultiProof
+			read, err := _proof.ReadFrom(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read != written || !reflect.DeepEqual(proof.Leaves, _proof.Leaves) || len(proof.Nodes) != len(_proof.Nodes) {
+				t.Fatal("the decoded proof doesn't match the original")
+			}
+			if !VerifyMultiProof(h, merkleCap, indices, _proof, uint64(n)) {
+				t.Fatal("the decoded multi proof should be valid")
+			}
+
+			// wrong leaf
+			proof.Leaves[0] = append([]byte{}, proof.Leaves[0]...)
+			proof.Leaves[0][0] ^= 1
+			if VerifyMultiProof(h, merkleCap, indices, proof, uint64(n)) {
+				t.Fatal("verifying a wrong leaf should fail")
+			}
+
+			// wrong cap
+			wrongCap := make([][]byte, len(merkleCap))
+			for i := range wrongCap {
+				wrongCap[i] = append([]byte{}, merkleCap[i]...)
+				wrongCap[i][0] ^= 1
+			}
+			if VerifyMultiProof(h, wrongCap, indices, _proof, uint64(n)) {
+				t.Fatal("verifying against a wrong cap should fail")
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package communicator
+
+import (
+	"sync"
+)
+
+// link is an unbounded byte stream from one party to another
+type link struct {
+	lock sync.Mutex
+	cond *sync.Cond
+	buf  []byte
+}
+
+func newLink() *link {
+	l := &link{}
+	l.cond = sync.NewCond(&l.lock)
+	return l
+}
+
+func (l *link) write(buf []byte) {
+	l.lock.Lock()
+	l.buf = append(l.buf, buf...)
+	l.lock.Unlock()
+	l.cond.Broadcast()
+}
+
+func (l *link) read(size int) []byte {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for len(l.buf) < size {
+		l.cond.Wait()
+	}
+	res := make([]byte, size)
+	copy(res, l.buf)
+	l.buf = l.buf[size:]
+	return res
+}
+
+// Channel is an in-process Communicator, the parties of the world
+// being goroutines of the same binary.
+type Channel struct {
+	rank  int
+	links [][]*link // links[i][j] carries the messages from i to j
+}
+
+// NewChannels returns the Communicators of a world of size in-process parties,
+// the i-th one having rank i.
+func NewChannels(size int) []*Channel {
+	links := make([][]*link, size)
+	for i := range links {
+		links[i] = make([]*link, size)
+		for j := range links[i] {
+			links[i][j] = newLink()
+		}
+	}
+	res := make([]*Channel, size)
+	for i := range res {
+		res[i] = &Channel{rank: i, links: links}
+	}
+	return res
+}
+
+// Rank implements Communicator
+func (c *Channel) Rank() int {
+	return c.rank
+}
+
+// Size implements Communicator
+func (c *Channel) Size() int {
+	return len(c.links)
+}
+
+// Send implements Communicator
+func (c *Channel) Send(buf []byte, to int) error {
+	if err := checkRank(c, to); err != nil {
+		return err
+	}
+	c.links[c.rank][to].write(buf)
+	return nil
+}
+
+// Receive implements Communicator
+func (c *Channel) Receive(size int, from int) ([]byte, error) {
+	if err := checkRank(c, from); err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		return nil, ErrInvalidBufferSize
+	}
+	return c.links[from][c.rank].read(size), nil
+}
+
+// Broadcast implements Communicator
+func (c *Channel) Broadcast(buf []byte, root int) error {
+	return treeBroadcast(c, buf, root)
+}
+
+// Gather implements Communicator
+func (c *Channel) Gather(buf []byte, root int) ([][]byte, error) {
+	return treeGather(c, buf, root)
+}
+
+// Reduce implements Communicator
+func (c *Channel) Reduce(buf []byte, root int, op ReduceOp) error {
+	return treeReduce(c, buf, root, op)
+}
+
+// AllReduce implements Communicator
+func (c *Channel) AllReduce(buf []byte, op ReduceOp) error {
+	return recursiveDoublingAllReduce(c, buf, op)
+}
+
+// AllToAll implements Communicator
+func (c *Channel) AllToAll(bufs [][]byte) ([][]byte, error) {
+	return pairwiseAllToAll(c, bufs)
+}
@@ -0,0 +1,232 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package communicator
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+// run executes f on each party of an in-process world of the given size
+func run(t *testing.T, size int, f func(c Communicator) error) {
+	t.Helper()
+	comms := NewChannels(size)
+	errs := make([]error, size)
+	var wg sync.WaitGroup
+	wg.Add(size)
+	for i := range comms {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(comms[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("party %d: %v", i, err)
+		}
+	}
+}
+
+func TestChannelSendReceive(t *testing.T) {
+	run(t, 3, func(c Communicator) error {
+		// ring: each party sends its rank to the next one, in two messages
+		next := (c.Rank() + 1) % c.Size()
+		prev := (c.Rank() + c.Size() - 1) % c.Size()
+		if err := c.Send([]byte{byte(c.Rank())}, next); err != nil {
+			return err
+		}
+		if err := c.Send([]byte{byte(c.Rank()), 42}, next); err != nil {
+			return err
+		}
+		// the stream is read with a different framing than the one used to write it
+		buf, err := c.Receive(3, prev)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(buf, []byte{byte(prev), byte(prev), 42}) {
+			t.Errorf("party %d received %v", c.Rank(), buf)
+		}
+		return nil
+	})
+}
+
+func TestChannelBroadcast(t *testing.T) {
+	const root = 1
+	run(t, 4, func(c Communicator) error {
+		buf := make([]byte, 4)
+		if c.Rank() == root {
+			copy(buf, []byte{1, 2, 3, 4})
+		}
+		if err := c.Broadcast(buf, root); err != nil {
+			return err
+		}
+		if !bytes.Equal(buf, []byte{1, 2, 3, 4}) {
+			t.Errorf("party %d received %v", c.Rank(), buf)
+		}
+		return nil
+	})
+}
+
+func TestChannelGather(t *testing.T) {
+	run(t, 4, func(c Communicator) error {
+		res, err := c.Gather([]byte{byte(c.Rank()), 7}, 0)
+		if err != nil {
+			return err
+		}
+		if c.Rank() != 0 {
+			if res != nil {
+				t.Errorf("party %d should not receive the gathered buffers", c.Rank())
+			}
+			return nil
+		}
+		for i := range res {
+			if !bytes.Equal(res[i], []byte{byte(i), 7}) {
+				t.Errorf("gathered %v from party %d", res[i], i)
+			}
+		}
+		return nil
+	})
+}
+
+func TestChannelInvalidRank(t *testing.T) {
+	c := NewChannels(2)[0]
+	if err := c.Send([]byte{0}, 2); err != ErrInvalidRank {
+		t.Fatal("sending to an invalid rank should fail")
+	}
+	if _, err := c.Receive(1, -1); err != ErrInvalidRank {
+		t.Fatal("receiving from an invalid rank should fail")
+	}
+}
+
+// sum adds the buffers as vectors of bytes, mod 256
+func sum(acc, buf []byte) error {
+	for i := range acc {
+		acc[i] += buf[i]
+	}
+	return nil
+}
+
+func TestChannelCollectives(t *testing.T) {
+	for size := 1; size <= 9; size++ {
+		for root := 0; root < size; root++ {
+			var expectedSum byte
+			for i := 0; i < size; i++ {
+				expectedSum += byte(i + 1)
+			}
+			run(t, size, func(c Communicator) error {
+				// broadcast
+				buf := []byte{0, 0}
+				if c.Rank() == root {
+					buf = []byte{byte(root), 42}
+				}
+				if err := c.Broadcast(buf, root); err != nil {
+					return err
+				}
+				if !bytes.Equal(buf, []byte{byte(root), 42}) {
+					t.Errorf("size %d, root %d: party %d received %v", size, root, c.Rank(), buf)
+				}
+
+				// gather
+				res, err := c.Gather([]byte{byte(c.Rank()), 7}, root)
+				if err != nil {
+					return err
+				}
+				if c.Rank() == root {
+					for i := range res {
+						if !bytes.Equal(res[i], []byte{byte(i), 7}) {
+							t.Errorf("size %d, root %d: gathered %v from party %d", size, root, res[i], i)
+						}
+					}
+				} else if res != nil {
+					t.Errorf("party %d should not receive the gathered buffers", c.Rank())
+				}
+
+				// reduce
+				buf = []byte{byte(c.Rank() + 1), 1}
+				if err := c.Reduce(buf, root, sum); err != nil {
+					return err
+				}
+				expected := []byte{expectedSum, byte(size)}
+				if c.Rank() != root {
+					expected = []byte{byte(c.Rank() + 1), 1}
+				}
+				if !bytes.Equal(buf, expected) {
+					t.Errorf("size %d, root %d: party %d reduced %v", size, root, c.Rank(), buf)
+				}
+
+				// all reduce
+				buf = []byte{byte(c.Rank() + 1), 1}
+				if err := c.AllReduce(buf, sum); err != nil {
+					return err
+				}
+				if !bytes.Equal(buf, []byte{expectedSum, byte(size)}) {
+					t.Errorf("size %d: party %d all reduced %v", size, c.Rank(), buf)
+				}
+				return nil
+			})
+		}
+	}
+}
+
+func TestChannelAllToAll(t *testing.T) {
+	for size := 1; size <= 9; size++ {
+		run(t, size, func(c Communicator) error {
+			bufs := make([][]byte, c.Size())
+			for i := range bufs {
+				bufs[i] = []byte{byte(c.Rank()), byte(i)}
+			}
+			res, err := c.AllToAll(bufs)
+			if err != nil {
+				return err
+			}
+			for i := range res {
+				if !bytes.Equal(res[i], []byte{byte(i), byte(c.Rank())}) {
+					t.Errorf("size %d: party %d received %v from party %d", size, c.Rank(), res[i], i)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// TestStarAllToAll checks the implementation of AllToAll used by the MPI transport
+func TestStarAllToAll(t *testing.T) {
+	for size := 1; size <= 5; size++ {
+		run(t, size, func(c Communicator) error {
+			bufs := make([][]byte, c.Size())
+			for i := range bufs {
+				bufs[i] = []byte{byte(c.Rank()), byte(i), 42}
+			}
+			res, err := starAllToAll(c, bufs, 0)
+			if err != nil {
+				return err
+			}
+			for i := range res {
+				if !bytes.Equal(res[i], []byte{byte(i), byte(c.Rank()), 42}) {
+					t.Errorf("size %d: party %d received %v from party %d", size, c.Rank(), res[i], i)
+				}
+			}
+			return nil
+		})
+	}
+	run(t, 2, func(c Communicator) error {
+		if _, err := starAllToAll(c, [][]byte{{1}}, 0); err != ErrInvalidBufferSize {
+			t.Error("a buffer per party is expected")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,247 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package communicator provides the transport used by the parties of a distributed KZG scheme.
+//
+// A Communicator connects the parties 0, 1, ..., Size()-1 of a world. Messages are
+// byte streams: Receive(n, from) returns the next n bytes sent by from to the caller.
+package communicator
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	ErrInvalidRank       = errors.New("communicator: invalid rank")
+	ErrUnsupportedRoute  = errors.New("communicator: route not supported by the transport")
+	ErrInvalidBufferSize = errors.New("communicator: invalid buffer size")
+)
+
+// Communicator connects a party to the other parties of a world
+type Communicator interface {
+	// Rank returns the rank of the party, in [0, Size())
+	Rank() int
+
+	// Size returns the number of parties in the world
+	Size() int
+
+	// Send sends buf to the party of rank to
+	Send(buf []byte, to int) error
+
+	// Receive receives size bytes from the party of rank from
+	Receive(size int, from int) ([]byte, error)
+
+	// Broadcast sends buf from root to all the parties.
+	// On the other parties, buf is overwritten with the received bytes.
+	Broadcast(buf []byte, root int) error
+
+	// Gather collects buf from all the parties on root, all the buffers have the same size.
+	// On root, the i-th entry of the result is the buffer of the party of rank i,
+	// the other parties get nil.
+	Gather(buf []byte, root int) ([][]byte, error)
+
+	// Reduce combines buf of all the parties with op, all the buffers have the same size.
+	// On root, buf is overwritten with the result, it is left unchanged on the other parties.
+	Reduce(buf []byte, root int, op ReduceOp) error
+
+	// AllReduce is Reduce with the result written in buf on all the parties.
+	AllReduce(buf []byte, op ReduceOp) error
+
+	// AllToAll sends bufs[i] to the party of rank i, all the buffers of all the parties have the same size.
+	// The i-th entry of the result is the buffer sent by the party of rank i to the caller.
+	AllToAll(bufs [][]byte) ([][]byte, error)
+}
+
+// ReduceOp combines buf into acc, in place. acc and buf have the same size.
+//
+// The operation must be associative and commutative, the order in which the buffers
+// of the parties are combined depends on the Communicator.
+type ReduceOp func(acc, buf []byte) error
+
+func checkRank(c Communicator, rank int) error {
+	if rank < 0 || rank >= c.Size() {
+		return ErrInvalidRank
+	}
+	return nil
+}
+
+// starBroadcast implements Broadcast with point to point messages from root
+func starBroadcast(c Communicator, buf []byte, root int) error {
+	if err := checkRank(c, root); err != nil {
+		return err
+	}
+	if c.Rank() != root {
+		res, err := c.Receive(len(buf), root)
+		if err != nil {
+			return err
+		}
+		copy(buf, res)
+		return nil
+	}
+	for i := 0; i < c.Size(); i++ {
+		if i == root {
+			continue
+		}
+		if err := c.Send(buf, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// starGather implements Gather with point to point messages to root
+func starGather(c Communicator, buf []byte, root int) ([][]byte, error) {
+	if err := checkRank(c, root); err != nil {
+		return nil, err
+	}
+	if c.Rank() != root {
+		return nil, c.Send(buf, root)
+	}
+	res := make([][]byte, c.Size())
+	for i := 0; i < c.Size(); i++ {
+		if i == root {
+			res[i] = make([]byte, len(buf))
+			copy(res[i], buf)
+			continue
+		}
+		b, err := c.Receive(len(buf), i)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = b
+	}
+	return res, nil
+}
+
+// starReduce implements Reduce with a Gather on root, the buffers being combined on root
+func starReduce(c Communicator, buf []byte, root int, op ReduceOp) error {
+	bufs, err := c.Gather(buf, root)
+	if err != nil || c.Rank() != root {
+		return err
+	}
+	for i := range bufs {
+		if i == root {
+			continue
+		}
+		if err := op(buf, bufs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// starAllReduce implements AllReduce with starReduce and a Broadcast from root
+func starAllReduce(c Communicator, buf []byte, root int, op ReduceOp) error {
+	if err := starReduce(c, buf, root, op); err != nil {
+		return err
+	}
+	return c.Broadcast(buf, root)
+}
+
+// checkAllToAll checks that there is one buffer per party, all of the same size
+func checkAllToAll(c Communicator, bufs [][]byte) error {
+	if len(bufs) != c.Size() {
+		return ErrInvalidBufferSize
+	}
+	for i := range bufs {
+		if len(bufs[i]) != len(bufs[0]) {
+			return ErrInvalidBufferSize
+		}
+	}
+	return nil
+}
+
+// pairwiseAllToAll implements AllToAll with point to point messages between all the parties.
+// At step s, a party sends to rank+s and receives from rank-s, so Send must not block.
+func pairwiseAllToAll(c Communicator, bufs [][]byte) ([][]byte, error) {
+	if err := checkAllToAll(c, bufs); err != nil {
+		return nil, err
+	}
+	size, rank := c.Size(), c.Rank()
+	res := make([][]byte, size)
+	res[rank] = make([]byte, len(bufs[rank]))
+	copy(res[rank], bufs[rank])
+	for s := 1; s < size; s++ {
+		if err := c.Send(bufs[(rank+s)%size], (rank+s)%size); err != nil {
+			return nil, err
+		}
+	}
+	for s := 1; s < size; s++ {
+		from := (rank - s + size) % size
+		b, err := c.Receive(len(bufs[0]), from)
+		if err != nil {
+			return nil, err
+		}
+		res[from] = b
+	}
+	return res, nil
+}
+
+// starAllToAll implements AllToAll through root: each party sends all its buffers to root,
+// which sends back to each party the buffers addressed to it.
+func starAllToAll(c Communicator, bufs [][]byte, root int) ([][]byte, error) {
+	if err := checkAllToAll(c, bufs); err != nil {
+		return nil, err
+	}
+	size, n := c.Size(), len(bufs[0])
+	if c.Rank() != root {
+		if err := c.Send(bytes.Join(bufs, nil), root); err != nil {
+			return nil, err
+		}
+		b, err := c.Receive(size*n, root)
+		if err != nil {
+			return nil, err
+		}
+		res := make([][]byte, size)
+		for i := range res {
+			res[i] = b[i*n : (i+1)*n]
+		}
+		return res, nil
+	}
+
+	// all[i][j] is the buffer sent by i to j
+	all := make([][][]byte, size)
+	for i := range all {
+		if i == root {
+			all[i] = bufs
+			continue
+		}
+		b, err := c.Receive(size*n, i)
+		if err != nil {
+			return nil, err
+		}
+		all[i] = make([][]byte, size)
+		for j := range all[i] {
+			all[i][j] = b[j*n : (j+1)*n]
+		}
+	}
+	res := make([][]byte, size)
+	for j := 0; j < size; j++ {
+		toJ := make([][]byte, size)
+		for i := range toJ {
+			toJ[i] = all[i][j]
+		}
+		if j == root {
+			for i := range res {
+				res[i] = append([]byte{}, toJ[i]...)
+			}
+			continue
+		}
+		if err := c.Send(bytes.Join(toJ, nil), j); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
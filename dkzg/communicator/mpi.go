@@ -0,0 +1,103 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package communicator
+
+import (
+	"github.com/sunblaze-ucb/simpleMPI/mpi"
+)
+
+// MPI is a Communicator backed by simpleMPI.
+//
+// simpleMPI connects the master (rank 0) to each of the other parties,
+// so only the messages from or to rank 0 are supported.
+type MPI struct{}
+
+// NewMPI sets up the simpleMPI world and returns a Communicator on it.
+//
+// The master reads the list of parties from ipFilePath and starts them
+// through ssh, see simpleMPI for more details.
+func NewMPI(ipFilePath, sshKeyFilePath, sshUserName string) *MPI {
+	mpi.WorldInit(ipFilePath, sshKeyFilePath, sshUserName)
+	return &MPI{}
+}
+
+// Rank implements Communicator
+func (c *MPI) Rank() int {
+	return int(mpi.SelfRank)
+}
+
+// Size implements Communicator
+func (c *MPI) Size() int {
+	return int(mpi.WorldSize)
+}
+
+// Send implements Communicator
+func (c *MPI) Send(buf []byte, to int) error {
+	if err := checkRank(c, to); err != nil {
+		return err
+	}
+	if c.Rank() != 0 && to != 0 {
+		return ErrUnsupportedRoute
+	}
+	return mpi.SendBytes(buf, uint64(to))
+}
+
+// Receive implements Communicator
+func (c *MPI) Receive(size int, from int) ([]byte, error) {
+	if err := checkRank(c, from); err != nil {
+		return nil, err
+	}
+	if c.Rank() != 0 && from != 0 {
+		return nil, ErrUnsupportedRoute
+	}
+	if size < 0 {
+		return nil, ErrInvalidBufferSize
+	}
+	return mpi.ReceiveBytes(uint64(size), uint64(from))
+}
+
+// Broadcast implements Communicator, root must be 0
+func (c *MPI) Broadcast(buf []byte, root int) error {
+	if root != 0 {
+		return ErrUnsupportedRoute
+	}
+	return starBroadcast(c, buf, root)
+}
+
+// Gather implements Communicator, root must be 0
+func (c *MPI) Gather(buf []byte, root int) ([][]byte, error) {
+	if root != 0 {
+		return nil, ErrUnsupportedRoute
+	}
+	return starGather(c, buf, root)
+}
+
+// Reduce implements Communicator, root must be 0
+func (c *MPI) Reduce(buf []byte, root int, op ReduceOp) error {
+	if root != 0 {
+		return ErrUnsupportedRoute
+	}
+	return starReduce(c, buf, root, op)
+}
+
+// AllReduce implements Communicator
+func (c *MPI) AllReduce(buf []byte, op ReduceOp) error {
+	return starAllReduce(c, buf, 0, op)
+}
+
+// AllToAll implements Communicator, the buffers being routed through rank 0
+func (c *MPI) AllToAll(bufs [][]byte) ([][]byte, error) {
+	return starAllToAll(c, bufs, 0)
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package communicator
+
+import "math/bits"
+
+// The collective operations below follow a binomial tree rooted at root, they complete in
+// ⌈log₂(Size())⌉ rounds. The parties are numbered relatively to root: r = (rank - root) mod Size().
+// In the round k, the parties r with r mod 2ᵏ⁺¹ = 2ᵏ exchange with r - 2ᵏ.
+
+// relativeRank returns the rank of c relatively to root
+func relativeRank(c Communicator, root int) int {
+	return (c.Rank() - root + c.Size()) % c.Size()
+}
+
+// absoluteRank returns the rank of the party of rank r relatively to root
+func absoluteRank(c Communicator, r, root int) int {
+	return (r + root) % c.Size()
+}
+
+// treeBroadcast implements Broadcast along a binomial tree
+func treeBroadcast(c Communicator, buf []byte, root int) error {
+	if err := checkRank(c, root); err != nil {
+		return err
+	}
+	size := c.Size()
+	r := relativeRank(c, root)
+
+	// receive from the parent
+	mask := 1
+	for ; mask < size; mask <<= 1 {
+		if r&mask != 0 {
+			res, err := c.Receive(len(buf), absoluteRank(c, r-mask, root))
+			if err != nil {
+				return err
+			}
+			copy(buf, res)
+			break
+		}
+	}
+
+	// send to the children
+	for mask >>= 1; mask > 0; mask >>= 1 {
+		if r+mask < size {
+			if err := c.Send(buf, absoluteRank(c, r+mask, root)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// treeGather implements Gather along a binomial tree.
+// Each party forwards to its parent the buffers of its subtree, that is of the parties
+// r, r+1, ..., r+2ᵏ-1.
+func treeGather(c Communicator, buf []byte, root int) ([][]byte, error) {
+	if err := checkRank(c, root); err != nil {
+		return nil, err
+	}
+	size := c.Size()
+	r := relativeRank(c, root)
+
+	block := make([]byte, len(buf), len(buf)*(size-r))
+	copy(block, buf)
+	for mask := 1; mask < size; mask <<= 1 {
+		if r&mask != 0 {
+			return nil, c.Send(block, absoluteRank(c, r-mask, root))
+		}
+		if child := r + mask; child < size {
+			nbBuffers := mask
+			if size-child < nbBuffers {
+				nbBuffers = size - child
+			}
+			res, err := c.Receive(nbBuffers*len(buf), absoluteRank(c, child, root))
+			if err != nil {
+				return nil, err
+			}
+			block = append(block, res...)
+		}
+	}
+
+	// root, block[i] is the buffer of the party of relative rank i
+	res := make([][]byte, size)
+	for i := 0; i < size; i++ {
+		res[absoluteRank(c, i, root)] = block[i*len(buf) : (i+1)*len(buf)]
+	}
+	return res, nil
+}
+
+// treeReduce implements Reduce along a binomial tree
+func treeReduce(c Communicator, buf []byte, root int, op ReduceOp) error {
+	if err := checkRank(c, root); err != nil {
+		return err
+	}
+	size := c.Size()
+	r := relativeRank(c, root)
+
+	acc := buf
+	if r != 0 {
+		acc = make([]byte, len(buf))
+		copy(acc, buf)
+	}
+	for mask := 1; mask < size; mask <<= 1 {
+		if r&mask != 0 {
+			return c.Send(acc, absoluteRank(c, r-mask, root))
+		}
+		if child := r + mask; child < size {
+			res, err := c.Receive(len(buf), absoluteRank(c, child, root))
+			if err != nil {
+				return err
+			}
+			if err := op(acc, res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recursiveDoublingAllReduce implements AllReduce. When Size() is a power of 2, in the round k
+// the party of rank i exchanges its partial result with the party of rank i ⊕ 2ᵏ. Otherwise
+// the buffers are reduced on 0 and broadcast along a binomial tree.
+func recursiveDoublingAllReduce(c Communicator, buf []byte, op ReduceOp) error {
+	size := c.Size()
+	if bits.OnesCount(uint(size)) != 1 {
+		if err := treeReduce(c, buf, 0, op); err != nil {
+			return err
+		}
+		return treeBroadcast(c, buf, 0)
+	}
+	for mask := 1; mask < size; mask <<= 1 {
+		peer := c.Rank() ^ mask
+		if err := c.Send(buf, peer); err != nil {
+			return err
+		}
+		res, err := c.Receive(len(buf), peer)
+		if err != nil {
+			return err
+		}
+		if err := op(buf, res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,6 @@
-// Package kzg provides constructor for curved-typed KZG SRS
+// Package dkzg provides constructors for curved-typed distributed KZG SRS and proofs
 //
-// For more details, see ecc/XXX/fr/kzg package
+// For more details, see ecc/XXX/fr/dkzg package
 package dkzg
 
 import (
@@ -8,7 +8,15 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc"
 
+	dkzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/dkzg"
+	dkzg_bls12378 "github.com/consensys/gnark-crypto/ecc/bls12-378/fr/dkzg"
+	dkzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/dkzg"
+	dkzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/dkzg"
+	dkzg_bls24317 "github.com/consensys/gnark-crypto/ecc/bls24-317/fr/dkzg"
 	dkzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/dkzg"
+	dkzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/dkzg"
+	dkzg_bw6756 "github.com/consensys/gnark-crypto/ecc/bw6-756/fr/dkzg"
+	dkzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/dkzg"
 )
 
 // SRS ...
@@ -17,12 +25,88 @@ type SRS interface {
 	io.WriterTo
 }
 
+// Proof ...
+type Proof interface {
+	io.ReaderFrom
+	io.WriterTo
+}
+
 // NewSRS returns an empty curved-typed SRS object
 // that implements io.ReaderFrom and io.WriterTo interfaces
 func NewSRS(curveID ecc.ID) SRS {
 	switch curveID {
 	case ecc.BN254:
 		return &dkzg_bn254.SRS{}
+	case ecc.BLS12_377:
+		return &dkzg_bls12377.SRS{}
+	case ecc.BLS12_378:
+		return &dkzg_bls12378.SRS{}
+	case ecc.BLS12_381:
+		return &dkzg_bls12381.SRS{}
+	case ecc.BLS24_315:
+		return &dkzg_bls24315.SRS{}
+	case ecc.BLS24_317:
+		return &dkzg_bls24317.SRS{}
+	case ecc.BW6_761:
+		return &dkzg_bw6761.SRS{}
+	case ecc.BW6_633:
+		return &dkzg_bw6633.SRS{}
+	case ecc.BW6_756:
+		return &dkzg_bw6756.SRS{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewOpeningProof returns an empty curved-typed opening proof
+// that implements io.ReaderFrom and io.WriterTo interfaces
+func NewOpeningProof(curveID ecc.ID) Proof {
+	switch curveID {
+	case ecc.BN254:
+		return &dkzg_bn254.OpeningProof{}
+	case ecc.BLS12_377:
+		return &dkzg_bls12377.OpeningProof{}
+	case ecc.BLS12_378:
+		return &dkzg_bls12378.OpeningProof{}
+	case ecc.BLS12_381:
+		return &dkzg_bls12381.OpeningProof{}
+	case ecc.BLS24_315:
+		return &dkzg_bls24315.OpeningProof{}
+	case ecc.BLS24_317:
+		return &dkzg_bls24317.OpeningProof{}
+	case ecc.BW6_761:
+		return &dkzg_bw6761.OpeningProof{}
+	case ecc.BW6_633:
+		return &dkzg_bw6633.OpeningProof{}
+	case ecc.BW6_756:
+		return &dkzg_bw6756.OpeningProof{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewBatchOpeningProof returns an empty curved-typed batch opening proof
+// that implements io.ReaderFrom and io.WriterTo interfaces
+func NewBatchOpeningProof(curveID ecc.ID) Proof {
+	switch curveID {
+	case ecc.BN254:
+		return &dkzg_bn254.BatchOpeningProof{}
+	case ecc.BLS12_377:
+		return &dkzg_bls12377.BatchOpeningProof{}
+	case ecc.BLS12_378:
+		return &dkzg_bls12378.BatchOpeningProof{}
+	case ecc.BLS12_381:
+		return &dkzg_bls12381.BatchOpeningProof{}
+	case ecc.BLS24_315:
+		return &dkzg_bls24315.BatchOpeningProof{}
+	case ecc.BLS24_317:
+		return &dkzg_bls24317.BatchOpeningProof{}
+	case ecc.BW6_761:
+		return &dkzg_bw6761.BatchOpeningProof{}
+	case ecc.BW6_633:
+		return &dkzg_bw6633.BatchOpeningProof{}
+	case ecc.BW6_756:
+		return &dkzg_bw6756.BatchOpeningProof{}
 	default:
 		panic("not implemented")
 	}
@@ -0,0 +1,106 @@
+package dkzg
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc"
+
+	dkzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/dkzg"
+	dkzg_bls12378 "github.com/consensys/gnark-crypto/ecc/bls12-378/fr/dkzg"
+	dkzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/dkzg"
+	dkzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/dkzg"
+	dkzg_bls24317 "github.com/consensys/gnark-crypto/ecc/bls24-317/fr/dkzg"
+	dkzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/dkzg"
+	dkzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/dkzg"
+	dkzg_bw6756 "github.com/consensys/gnark-crypto/ecc/bw6-756/fr/dkzg"
+	dkzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/dkzg"
+)
+
+const testSRSSize = 16
+
+var testTau = []*big.Int{big.NewInt(42), big.NewInt(27)}
+
+// testSRS returns a SRS of a world with a single party on curveID
+func testSRS(t *testing.T, curveID ecc.ID) SRS {
+	var srs SRS
+	var err error
+	c := communicator.NewChannels(1)[0]
+	switch curveID {
+	case ecc.BN254:
+		srs, err = dkzg_bn254.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BLS12_377:
+		srs, err = dkzg_bls12377.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BLS12_378:
+		srs, err = dkzg_bls12378.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BLS12_381:
+		srs, err = dkzg_bls12381.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BLS24_315:
+		srs, err = dkzg_bls24315.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BLS24_317:
+		srs, err = dkzg_bls24317.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BW6_761:
+		srs, err = dkzg_bw6761.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BW6_633:
+		srs, err = dkzg_bw6633.NewSRS(testSRSSize, testTau, nil, c)
+	case ecc.BW6_756:
+		srs, err = dkzg_bw6756.NewSRS(testSRSSize, testTau, nil, c)
+	default:
+		t.Fatalf("unsupported curve %s", curveID)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srs
+}
+
+func TestSerializationSRSCrossCurve(t *testing.T) {
+	curves := ecc.Implemented()
+	for _, from := range curves {
+		var buf bytes.Buffer
+		srs := testSRS(t, from)
+		if _, err := srs.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		encoded := buf.Bytes()
+
+		for _, to := range curves {
+			_srs := NewSRS(to)
+			_, err := _srs.ReadFrom(bytes.NewReader(encoded))
+			if from == to {
+				if err != nil {
+					t.Fatalf("%s: %v", from, err)
+				}
+				if !reflect.DeepEqual(srs, _srs) {
+					t.Fatalf("%s: SRS serialization failed", from)
+				}
+			} else if err == nil {
+				t.Fatalf("SRS on %s should not be decoded on %s", from, to)
+			}
+		}
+	}
+}
+
+func TestSerializationProofs(t *testing.T) {
+	for _, curveID := range ecc.Implemented() {
+		for _, newProof := range []func(ecc.ID) Proof{NewOpeningProof, NewBatchOpeningProof} {
+			proof := newProof(curveID)
+			var buf bytes.Buffer
+			written, err := proof.WriteTo(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_proof := newProof(curveID)
+			read, err := _proof.ReadFrom(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read != written || !reflect.DeepEqual(proof, _proof) {
+				t.Fatalf("%s: %T serialization failed", curveID, proof)
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	"github.com/consensys/gnark-crypto/internal/parallel"
+)
+
+/*
+BivariateDomain is the domain H_X × H_Y of the polynomials F(X, Y) = ∑ᵢfᵢ(Y)Lᵢ(X), H_X being the party
+domain, of size M, and H_Y a multiplicative subgroup of size N.
+
+The values of F are distributed among the parties in two layouts:
+  - rows: the party i holds a slice of N elements, the coefficients or the values along Y of fᵢ.
+    The rows of the padding points of H_X are zero, and held by no party.
+  - columns: the party p holds BlockSize() consecutive columns, starting at column p.BlockSize(),
+    each of them being M values along X, padding included. columns[j*M+r] is the value of the
+    column j of the block at the row r. The columns beyond N are zero.
+
+The transforms along Y are local to a row, the transforms along X are local to a column,
+Transpose and TransposeRows exchange the values between the parties to go from a layout to the other.
+*/
+type BivariateDomain struct {
+	X *PartyDomain
+	Y *fft.Domain
+}
+
+// NewBivariateDomain returns the domain x × H_Y, H_Y being of size sizeY (rounded to the next power of 2)
+func NewBivariateDomain(x *PartyDomain, sizeY uint64) *BivariateDomain {
+	return &BivariateDomain{
+		X: x,
+		Y: fft.NewDomain(sizeY),
+	}
+}
+
+// BlockSize returns the number of columns held by each party in the columns layout
+func (d *BivariateDomain) BlockSize() int {
+	n, w := int(d.Y.Cardinality), d.X.WorldSize
+	return (n + w - 1) / w
+}
+
+// FFTY evaluates in place the polynomial of coefficients row on shift.H_Y, the result being in natural order
+func (d *BivariateDomain) FFTY(row []fr.Element, shift fr.Element) {
+	shiftedFFT(d.Y, row, shift)
+}
+
+// FFTInverseY is the inverse of FFTY
+func (d *BivariateDomain) FFTInverseY(row []fr.Element, shift fr.Element) {
+	shiftedFFTInverse(d.Y, row, shift)
+}
+
+// FFTX evaluates in place each column, as the coefficients of a polynomial in X, on shift.<ω>,
+// the result being in natural order.
+func (d *BivariateDomain) FFTX(columns []fr.Element, shift fr.Element) {
+	m := int(d.X.Cardinality)
+	parallel.Execute(len(columns)/m, func(start, end int) {
+		for j := start; j < end; j++ {
+			shiftedFFT(d.X.Domain, columns[j*m:(j+1)*m], shift)
+		}
+	})
+}
+
+// FFTInverseX is the inverse of FFTX
+func (d *BivariateDomain) FFTInverseX(columns []fr.Element, shift fr.Element) {
+	m := int(d.X.Cardinality)
+	parallel.Execute(len(columns)/m, func(start, end int) {
+		for j := start; j < end; j++ {
+			shiftedFFTInverse(d.X.Domain, columns[j*m:(j+1)*m], shift)
+		}
+	})
+}
+
+// Transpose returns the block of columns of the party, row being the row of the party.
+// It's a collective call, each party sending a part of its row to each other party.
+func (d *BivariateDomain) Transpose(row []fr.Element, c communicator.Communicator) ([]fr.Element, error) {
+	if err := d.X.check(c.Size()); err != nil {
+		return nil, err
+	}
+	n, b, m := len(row), d.BlockSize(), int(d.X.Cardinality)
+	if n != int(d.Y.Cardinality) {
+		return nil, ErrInvalidPolynomialSize
+	}
+
+	// the party p gets the columns [p.b, (p+1).b) of the row
+	bufs := make([][]byte, c.Size())
+	block := make([]fr.Element, b)
+	for p := range bufs {
+		for j := range block {
+			block[j].SetZero()
+			if p*b+j < n {
+				block[j] = row[p*b+j]
+			}
+		}
+		bufs[p] = elementsToBytes(block)
+	}
+	received, err := c.AllToAll(bufs)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]fr.Element, b*m)
+	for i := range received {
+		if len(received[i]) != b*fr.Bytes {
+			return nil, ErrInvalidMessage
+		}
+		bytesToElements(block, received[i])
+		for j := range block {
+			columns[j*m+i] = block[j]
+		}
+	}
+	return columns, nil
+}
+
+// TransposeRows is the inverse of Transpose, it returns the row of the party. The rows of the
+// padding points of the party domain are dropped.
+func (d *BivariateDomain) TransposeRows(columns []fr.Element, c communicator.Communicator) ([]fr.Element, error) {
+	if err := d.X.check(c.Size()); err != nil {
+		return nil, err
+	}
+	n, b, m := int(d.Y.Cardinality), d.BlockSize(), int(d.X.Cardinality)
+	if len(columns) != b*m {
+		return nil, ErrInvalidPolynomialSize
+	}
+
+	// the party i gets the row i of the block
+	bufs := make([][]byte, c.Size())
+	block := make([]fr.Element, b)
+	for i := range bufs {
+		for j := range block {
+			block[j] = columns[j*m+i]
+		}
+		bufs[i] = elementsToBytes(block)
+	}
+	received, err := c.AllToAll(bufs)
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]fr.Element, n)
+	for p := range received {
+		if len(received[p]) != b*fr.Bytes {
+			return nil, ErrInvalidMessage
+		}
+		bytesToElements(block, received[p])
+		for j := range block {
+			if p*b+j < n {
+				row[p*b+j] = block[j]
+			}
+		}
+	}
+	return row, nil
+}
+
+/*
+CosetFFT evaluates F on shiftX.<ω> × shiftY.H_Y, row being the coefficients of fᵢ for the party i.
+The result is the block of columns of the party.
+
+The rows are evaluated along Y, transposed, and the columns, given by their values on the party domain,
+are interpolated and evaluated along X. The values are never gathered on a single party.
+*/
+func (d *BivariateDomain) CosetFFT(row []fr.Element, shiftX, shiftY fr.Element, c communicator.Communicator) ([]fr.Element, error) {
+	if len(row) > int(d.Y.Cardinality) {
+		return nil, ErrInvalidPolynomialSize
+	}
+	_row := make([]fr.Element, d.Y.Cardinality)
+	copy(_row, row)
+	d.FFTY(_row, shiftY)
+
+	columns, err := d.Transpose(_row, c)
+	if err != nil {
+		return nil, err
+	}
+	if xShift := d.X.Shift(); !shiftX.Equal(&xShift) {
+		d.FFTInverseX(columns, xShift)
+		d.FFTX(columns, shiftX)
+	}
+	return columns, nil
+}
+
+// CosetFFTInverse is the inverse of CosetFFT, it returns the coefficients of fᵢ for the party i.
+// The polynomial must be of degree < M in X, and vanish on the padding points of the party domain.
+func (d *BivariateDomain) CosetFFTInverse(columns []fr.Element, shiftX, shiftY fr.Element, c communicator.Communicator) ([]fr.Element, error) {
+	_columns := make([]fr.Element, len(columns))
+	copy(_columns, columns)
+	if xShift := d.X.Shift(); !shiftX.Equal(&xShift) {
+		d.FFTInverseX(_columns, shiftX)
+		d.FFTX(_columns, xShift)
+	}
+
+	row, err := d.TransposeRows(_columns, c)
+	if err != nil {
+		return nil, err
+	}
+	d.FFTInverseY(row, shiftY)
+	return row, nil
+}
+
+// shiftedFFT evaluates in place the polynomial of coefficients a on shift.<ω>, in natural order
+func shiftedFFT(domain *fft.Domain, a []fr.Element, shift fr.Element) {
+	scalePowers(a, shift)
+	domain.FFT(a, fft.DIF)
+	fft.BitReverse(a)
+}
+
+// shiftedFFTInverse is the inverse of shiftedFFT
+func shiftedFFTInverse(domain *fft.Domain, a []fr.Element, shift fr.Element) {
+	domain.FFTInverse(a, fft.DIF)
+	fft.BitReverse(a)
+	shift.Inverse(&shift)
+	scalePowers(a, shift)
+}
+
+// scalePowers sets a[k] to a[k]*shiftᵏ
+func scalePowers(a []fr.Element, shift fr.Element) {
+	if shift.IsOne() {
+		return
+	}
+	var acc fr.Element
+	acc.SetOne()
+	for k := range a {
+		a[k].Mul(&a[k], &acc)
+		acc.Mul(&acc, &shift)
+	}
+}
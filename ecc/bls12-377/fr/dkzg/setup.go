@@ -0,0 +1,382 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	"github.com/consensys/gnark-crypto/internal/parallel"
+)
+
+var (
+	ErrInvalidPowersOfTau = errors.New("dkzg: invalid powers of tau (number of rows must be a power of 2, and the rows of the same size)")
+	ErrInvalidShard       = errors.New("dkzg: SRS shard is not consistent with the powers of tau")
+)
+
+// PowersOfTau public bivariate powers of tau, as output by a ceremony.
+//
+// The shards of the parties can't be derived from two univariate KZG SRS, one for each dimension:
+// [τ₀ⁱτ₁ʲ]G₁ is not computable from [τ₀ⁱ]G₁ and [τ₁ʲ]G₁.
+//
+// implements io.ReaderFrom and io.WriterTo
+type PowersOfTau struct {
+	G1 [][]bls12377.G1Affine // G1[i][j] = g^(\tau[0]^i * \tau[1]^j), i < size of the party domain
+	G2 [3]bls12377.G2Affine  // G2[0] = g2, G2[1] = g2^tau[1], G2[2] = g2^tau[0]
+}
+
+// NewPowersOfTau returns bivariate powers of tau for nbParties parties and shards of the given size,
+// using tau as randomness source. The number of rows is the size of the party domain, the next
+// power of 2 of nbParties.
+//
+// In production, powers of tau generated through MPC should be used.
+func NewPowersOfTau(nbParties, size uint64, tau []*big.Int) (*PowersOfTau, error) {
+	if nbParties == 0 || size == 0 {
+		return nil, ErrInvalidPowersOfTau
+	}
+
+	nbRows := ecc.NextPowerOfTwo(nbParties)
+	_, _, gen1Aff, gen2Aff := bls12377.Generators()
+
+	var pt PowersOfTau
+	pt.G2[0] = gen2Aff
+	pt.G2[1].ScalarMultiplication(&gen2Aff, tau[1])
+	pt.G2[2].ScalarMultiplication(&gen2Aff, tau[0])
+
+	var tau0, tau1 fr.Element
+	tau0.SetBigInt(tau[0])
+	tau1.SetBigInt(tau[1])
+
+	// alphas[i*size+j] = τ₀ⁱτ₁ʲ
+	alphas := make([]fr.Element, nbRows*size)
+	alphas[0].SetOne()
+	for i := uint64(0); i < nbRows; i++ {
+		if i > 0 {
+			alphas[i*size].Mul(&alphas[(i-1)*size], &tau0)
+		}
+		for j := uint64(1); j < size; j++ {
+			alphas[i*size+j].Mul(&alphas[i*size+j-1], &tau1)
+		}
+	}
+	for i := 0; i < len(alphas); i++ {
+		alphas[i].FromMont()
+	}
+	g1s := bls12377.BatchScalarMultiplicationG1(&gen1Aff, alphas)
+
+	pt.G1 = make([][]bls12377.G1Affine, nbRows)
+	for i := uint64(0); i < nbRows; i++ {
+		pt.G1[i] = g1s[i*size : (i+1)*size]
+	}
+	return &pt, nil
+}
+
+// checkShape returns an error if the number of rows of pt is not a power of 2, or if its rows
+// are empty or not of the same size
+func (pt *PowersOfTau) checkShape() error {
+	nbRows := len(pt.G1)
+	if nbRows == 0 || bits.OnesCount(uint(nbRows)) != 1 || len(pt.G1[0]) == 0 {
+		return ErrInvalidPowersOfTau
+	}
+	for i := 1; i < nbRows; i++ {
+		if len(pt.G1[i]) != len(pt.G1[0]) {
+			return ErrInvalidPowersOfTau
+		}
+	}
+	return nil
+}
+
+// check returns an error if pt is malformed, or if its rows don't match domain
+func (pt *PowersOfTau) check(domain *PartyDomain) error {
+	if err := pt.checkShape(); err != nil {
+		return err
+	}
+	if err := domain.check(domain.WorldSize); err != nil {
+		return err
+	}
+	if domain.Cardinality != uint64(len(pt.G1)) {
+		return ErrInvalidPartyDomain
+	}
+	return nil
+}
+
+/*
+Verify checks that pt are powers of tau, for the \tau[1] of G2[1] and the \tau[0] of G2[2].
+
+G1[0][0] and G2[0] must be the generators of the curve. The rows are geometric sequences of ratio \tau[1],
+G1[i][j+1] = G1[i][j]^{\tau[1]}, and the columns of ratio \tau[0], G1[i+1][j] = G1[i][j]^{\tau[0]}.
+With random λ_{ij} and μ_{ij}, all the ratios are checked with a single pairing check
+e(\sum λ_{ij} G1[i][j+1] + \sum μ_{ij} G1[i+1][j], g2) = e(\sum λ_{ij} G1[i][j], g2^{\tau[1]}) e(\sum μ_{ij} G1[i][j], g2^{\tau[0]}).
+*/
+func (pt *PowersOfTau) Verify() error {
+	if err := pt.checkShape(); err != nil {
+		return err
+	}
+	_, _, gen1Aff, gen2Aff := bls12377.Generators()
+	if !pt.G1[0][0].Equal(&gen1Aff) || !pt.G2[0].Equal(&gen2Aff) {
+		return ErrInvalidPowersOfTau
+	}
+
+	nbRows, size := len(pt.G1), len(pt.G1[0])
+	if nbRows == 1 && size == 1 {
+		return nil
+	}
+
+	// scalars of G1[i][j], at index i*size+j, in the three multi-exponentiations
+	points := make([]bls12377.G1Affine, 0, nbRows*size)
+	for i := range pt.G1 {
+		points = append(points, pt.G1[i]...)
+	}
+	lambdas := make([]fr.Element, len(points))
+	mus := make([]fr.Element, len(points))
+	shifted := make([]fr.Element, len(points))
+	for i := 0; i < nbRows; i++ {
+		for j := 0; j < size; j++ {
+			k := i*size + j
+			if j+1 < size {
+				if _, err := lambdas[k].SetRandom(); err != nil {
+					return err
+				}
+				shifted[k+1].Add(&shifted[k+1], &lambdas[k])
+			}
+			if i+1 < nbRows {
+				if _, err := mus[k].SetRandom(); err != nil {
+					return err
+				}
+				shifted[k+size].Add(&shifted[k+size], &mus[k])
+			}
+		}
+	}
+
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	var a, b, c bls12377.G1Affine
+	if _, err := a.MultiExp(points, shifted, config); err != nil {
+		return err
+	}
+	if _, err := b.MultiExp(points, lambdas, config); err != nil {
+		return err
+	}
+	if _, err := c.MultiExp(points, mus, config); err != nil {
+		return err
+	}
+	b.Neg(&b)
+	c.Neg(&c)
+
+	check, err := bls12377.PairingCheck(
+		[]bls12377.G1Affine{a, b, c},
+		[]bls12377.G2Affine{pt.G2[0], pt.G2[1], pt.G2[2]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrInvalidPowersOfTau
+	}
+	return nil
+}
+
+/*
+Shards returns the SRS of the domain.WorldSize parties.
+
+The SRS of the party i is made of the g^{L_i(\tau[0]) * \tau[1]^j}. On the party domain g<ω> of size M,
+L_i(X) = 1/M * \sum_{k=0}^{M-1} ω^{-ik} g^{-k} X^k, so for each j, the vector (g^{L_i(\tau[0]) * \tau[1]^j})_i
+is the inverse DFT of (g^{g^{-k} * \tau[0]^k * \tau[1]^j})_k, computed with a FFT on G1.
+The shards of the padding points, i ≥ domain.WorldSize, are not returned.
+*/
+func (pt *PowersOfTau) Shards(domain *PartyDomain) ([]*SRS, error) {
+	lagrange, err := pt.Lagrange(domain)
+	if err != nil {
+		return nil, err
+	}
+	size := len(pt.G1[0])
+
+	shards := make([]*SRS, domain.WorldSize)
+	for i := range shards {
+		shards[i] = &SRS{
+			G1:        make([]bls12377.G1Affine, size),
+			G2:        pt.G2,
+			Lagrange:  lagrange,
+			Rank:      i,
+			WorldSize: domain.WorldSize,
+			Coset:     domain.Coset,
+		}
+	}
+
+	parallel.Execute(size, func(start, end int) {
+		column := make([]bls12377.G1Jac, domain.Cardinality)
+		for j := start; j < end; j++ {
+			pt.column(column, j, domain)
+			for i := range shards {
+				shards[i].G1[j].FromJacobian(&column[i])
+			}
+		}
+	})
+
+	return shards, nil
+}
+
+// Lagrange returns the [Lᵢ(τ₀)]G₁ for all the points of domain, that is SRS.Lagrange. They are also
+// the first points of the shards of the parties, needed by the root node to check their contributions,
+// see VerifiableCommit.
+func (pt *PowersOfTau) Lagrange(domain *PartyDomain) ([]bls12377.G1Affine, error) {
+	if err := pt.check(domain); err != nil {
+		return nil, err
+	}
+	column := make([]bls12377.G1Jac, domain.Cardinality)
+	pt.column(column, 0, domain)
+	res := make([]bls12377.G1Affine, len(column))
+	for i := range res {
+		res[i].FromJacobian(&column[i])
+	}
+	return res, nil
+}
+
+// column sets column to the (g^{L_i(\tau[0]) * \tau[1]^j})_i, see Shards
+func (pt *PowersOfTau) column(column []bls12377.G1Jac, j int, domain *PartyDomain) {
+	for k := range column {
+		column[k].FromAffine(&pt.G1[k][j])
+	}
+	if domain.Coset {
+		// g^{-k}
+		var gInvK fr.Element
+		var gInvKBigInt big.Int
+		gInvK.SetOne()
+		for k := 1; k < len(column); k++ {
+			gInvK.Mul(&gInvK, &domain.FrMultiplicativeGenInv)
+			gInvK.ToBigIntRegular(&gInvKBigInt)
+			column[k].ScalarMultiplication(&column[k], &gInvKBigInt)
+		}
+	}
+	domain.Domain.FFTInverseG1(column, fft.DIF)
+	fft.BitReverseG1(column)
+}
+
+// ShardPath returns the path of the SRS file of the party of rank rank in dir
+func ShardPath(dir string, rank int) string {
+	return filepath.Join(dir, fmt.Sprintf("dkzg_srs_%d", rank))
+}
+
+// WriteShards derives the SRS of all the parties of domain and writes them in dir, one file per party
+func (pt *PowersOfTau) WriteShards(dir string, domain *PartyDomain) error {
+	shards, err := pt.Shards(domain)
+	if err != nil {
+		return err
+	}
+	for i := range shards {
+		f, err := os.Create(ShardPath(dir, i))
+		if err != nil {
+			return err
+		}
+		if _, err := shards[i].WriteTo(f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadShard reads the SRS of the party of rank rank in dir
+func ReadShard(dir string, rank int) (*SRS, error) {
+	f, err := os.Open(ShardPath(dir, rank))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var srs SRS
+	if _, err := srs.ReadFrom(f); err != nil {
+		return nil, err
+	}
+	return &srs, nil
+}
+
+/*
+VerifyShard checks that srs is the SRS of the party of rank rank derived from pt.
+
+ 0. pt are powers of tau, see PowersOfTau.Verify.
+ 1. srs.Lagrange, the g^{L_i(\tau[0])} of the party domain of srs, are recomputed from the g^{\tau[0]^k},
+    and srs.G1[0] = srs.Lagrange[rank].
+ 2. srs.G1 is a geometric sequence of ratio \tau[1]. With random λ_j, A = \sum_j λ_j srs.G1[j]
+    and B = \sum_j λ_j srs.G1[j+1], we check e(B, g2) = e(A, g2^{\tau[1]}).
+*/
+func VerifyShard(srs *SRS, rank int, pt *PowersOfTau) error {
+	if srs.WorldSize <= 0 {
+		return ErrInvalidShard
+	}
+	if err := pt.Verify(); err != nil {
+		return err
+	}
+	lagrange, err := pt.Lagrange(srs.PartyDomain())
+	if err != nil {
+		return err
+	}
+	if rank < 0 || rank >= srs.WorldSize || srs.Rank != rank ||
+		len(srs.G1) != len(pt.G1[0]) || srs.G2 != pt.G2 || len(srs.Lagrange) != len(lagrange) {
+		return ErrInvalidShard
+	}
+	for i := range lagrange {
+		if !lagrange[i].Equal(&srs.Lagrange[i]) {
+			return ErrInvalidShard
+		}
+	}
+	if !lagrange[rank].Equal(&srs.G1[0]) {
+		return ErrInvalidShard
+	}
+
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+
+	size := len(srs.G1)
+	if size == 1 {
+		return nil
+	}
+
+	lambdas := make([]fr.Element, size-1)
+	for j := range lambdas {
+		if _, err := lambdas[j].SetRandom(); err != nil {
+			return err
+		}
+	}
+	var a, b bls12377.G1Affine
+	if _, err := a.MultiExp(srs.G1[:size-1], lambdas, config); err != nil {
+		return err
+	}
+	if _, err := b.MultiExp(srs.G1[1:], lambdas, config); err != nil {
+		return err
+	}
+	a.Neg(&a)
+
+	check, err := bls12377.PairingCheck(
+		[]bls12377.G1Affine{b, a},
+		[]bls12377.G2Affine{pt.G2[0], pt.G2[1]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrInvalidShard
+	}
+	return nil
+}
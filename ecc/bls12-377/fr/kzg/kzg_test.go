@@ -19,6 +19,7 @@ package kzg
 import (
 	"bytes"
 	"crypto/sha256"
+	"encoding/binary"
 	"math/big"
 	"reflect"
 	"testing"
@@ -26,6 +27,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
 )
 
 // testSRS re-used accross tests of the KZG scheme
@@ -79,29 +81,74 @@ func TestDividePolyByXminusA(t *testing.T) {
 
 func TestSerializationSRS(t *testing.T) {
 
-	// create a SRS
+	// create a SRS, a hiding one, and one with a Lagrange basis
 	srs, err := NewSRS(64, new(big.Int).SetInt64(42))
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	// serialize it...
-	var buf bytes.Buffer
-	_, err = srs.WriteTo(&buf)
+	hidingSRS, err := NewHidingSRS(64, new(big.Int).SetInt64(42), new(big.Int).SetInt64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lagrangeSRS, err := NewSRS(64, new(big.Int).SetInt64(42))
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err = lagrangeSRS.ToLagrangeBasis(fft.NewDomain(32)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, srs := range []*SRS{srs, hidingSRS, lagrangeSRS} {
+
+		// serialize it...
+		var buf bytes.Buffer
+		_, err = srs.WriteTo(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-	// reconstruct the SRS
-	var _srs SRS
-	_, err = _srs.ReadFrom(&buf)
+		// reconstruct the SRS
+		var _srs SRS
+		_, err = _srs.ReadFrom(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// compare
+		if !reflect.DeepEqual(srs, &_srs) {
+			t.Fatal("scheme serialization failed")
+		}
+	}
+
+	// layout of the first versions: G2[0], G2[1] and G1, without hiding generators nor Lagrange basis
+	var buf bytes.Buffer
+	enc := bls12377.NewEncoder(&buf)
+	for _, v := range []interface{}{&srs.G2[0], &srs.G2[1], srs.G1} {
+		if err := enc.Encode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	legacy := buf.Len()
+	_srs := SRS{H: hidingSRS.H, Lagrange: lagrangeSRS.Lagrange}
+	read, err := _srs.ReadFrom(&buf)
 	if err != nil {
 		t.Fatal(err)
 	}
+	if read != int64(legacy) || !reflect.DeepEqual(srs, &_srs) {
+		t.Fatal("decoding the first layout of the SRS failed")
+	}
 
-	// compare
-	if !reflect.DeepEqual(srs, &_srs) {
-		t.Fatal("scheme serialization failed")
+	// the optional fields are marked by the flags
+	buf.Reset()
+	if _, err = srs.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != legacy+1 || buf.Bytes()[legacy] != 0 {
+		t.Fatal("the SRS without optional fields should be encoded with empty flags")
+	}
+	buf.Bytes()[legacy] = 0x80
+	if _, err = _srs.ReadFrom(&buf); err != ErrInvalidSRSEncoding {
+		t.Fatalf("expected %v, got %v", ErrInvalidSRSEncoding, err)
 	}
 
 }
@@ -190,6 +237,146 @@ func TestVerifySinglePoint(t *testing.T) {
 	}
 }
 
+func TestLagrange(t *testing.T) {
+
+	const size = 64
+	domain := fft.NewDomain(size)
+	srs, err := NewSRS(size, new(big.Int).SetInt64(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = CommitLagrange(make([]fr.Element, size), srs); err != ErrNoLagrangeBasis {
+		t.Fatal("committing without Lagrange basis should have failed")
+	}
+	if err = srs.ToLagrangeBasis(domain); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same polynomial, in canonical and Lagrange form
+	f := randomPolynomial(size)
+	evals := make([]fr.Element, size)
+	copy(evals, f)
+	domain.FFT(evals, fft.DIF)
+	fft.BitReverse(evals)
+
+	digest, err := Commit(f, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digestLagrange, err := CommitLagrange(evals, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !digest.Equal(&digestLagrange) {
+		t.Fatal("Commit and CommitLagrange differ")
+	}
+
+	// open outside of the domain, and on a point of the domain
+	var point fr.Element
+	point.SetString("4321")
+	for _, point := range []fr.Element{point, domain.Generator} {
+		proof, err := OpenLagrange(evals, point, domain, srs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := Open(f, point, srs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(proof, expected) {
+			t.Fatal("Open and OpenLagrange differ")
+		}
+		if err = Verify(&digest, &proof, point, srs); err != nil {
+			t.Fatal(err)
+		}
+
+		// verify wrong proof
+		proof.ClaimedValue.Double(&proof.ClaimedValue)
+		if err = Verify(&digest, &proof, point, srs); err == nil {
+			t.Fatal("verifying wrong proof should have failed")
+		}
+	}
+}
+
+func TestHidingVerifySinglePoint(t *testing.T) {
+
+	srs, err := NewHidingSRS(64, new(big.Int).SetInt64(42), new(big.Int).SetInt64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create a polynomial
+	f := randomPolynomial(60)
+
+	// commit the polynomial twice, the commitments are blinded
+	digest, blinding, err := HidingCommit(f, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := HidingCommit(f, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest.Equal(&other) {
+		t.Fatal("hiding commitments of the same polynomial should differ")
+	}
+
+	// compute opening proof at a random point
+	var point fr.Element
+	point.SetString("4321")
+	proof, err := HidingOpen(f, blinding, point, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// verify the claimed valued
+	expected := eval(f, point)
+	if !proof.ClaimedValue.Equal(&expected) {
+		t.Fatal("inconsistant claimed value")
+	}
+
+	// verify correct proof
+	err = HidingVerify(&digest, &proof, point, srs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// serialize the proof
+	var buf bytes.Buffer
+	if _, err = proof.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var _proof HidingOpeningProof
+	if _, err = _proof.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(proof, _proof) {
+		t.Fatal("hiding opening proof serialization failed")
+	}
+
+	{
+		// verify wrong proof
+		proof.ClaimedValue.Double(&proof.ClaimedValue)
+		err = HidingVerify(&digest, &proof, point, srs)
+		if err == nil {
+			t.Fatal("verifying wrong proof should have failed")
+		}
+	}
+	{
+		// verify wrong proof against the other commitment
+		err = HidingVerify(&other, &_proof, point, srs)
+		if err == nil {
+			t.Fatal("verifying wrong proof should have failed")
+		}
+	}
+	{
+		// the SRS has no hiding generator
+		if _, _, err = HidingCommit(f, testSRS); err != ErrNoHidingGenerator {
+			t.Fatal("hiding commitment should need the hiding generators")
+		}
+	}
+}
+
 func TestBatchVerifySinglePoint(t *testing.T) {
 
 	size := 40
@@ -326,6 +513,151 @@ func TestBatchVerifyMultiPoints(t *testing.T) {
 
 const benchSize = 1 << 16
 
+func TestBatchOpenMultiPoints(t *testing.T) {
+
+	// ζ and ωζ, as in a Plonk prover, and a polynomial of degree < |S|
+	var zeta, omegaZeta fr.Element
+	zeta.SetString("4321")
+	omegaZeta.Mul(&zeta, &fft.NewDomain(64).Generator)
+	points := [][]fr.Element{
+		{zeta},
+		{zeta, omegaZeta},
+		{omegaZeta},
+		{omegaZeta, zeta},
+		{zeta, omegaZeta},
+	}
+	f := [][]fr.Element{
+		randomPolynomial(60),
+		randomPolynomial(50),
+		randomPolynomial(40),
+		randomPolynomial(30),
+		randomPolynomial(1),
+	}
+	digests := make([]Digest, len(f))
+	for k := range f {
+		var err error
+		if digests[k], err = Commit(f[k], testSRS); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proof, err := BatchOpenMultiPoints(f, digests, points, sha256.New(), testSRS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// claimed values
+	for k := range points {
+		for j := range points[k] {
+			expected := eval(f[k], points[k][j])
+			if !proof.ClaimedValues[k][j].Equal(&expected) {
+				t.Fatal("inconsistant claimed values")
+			}
+		}
+	}
+
+	// correct proof
+	if err := BatchVerifyMultiPointsOpening(digests, &proof, points, sha256.New(), testSRS); err != nil {
+		t.Fatal(err)
+	}
+
+	// serialization
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var _proof MultiPointsOpeningProof
+	if _, err := _proof.ReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(proof, _proof) {
+		t.Fatal("multi points opening proof serialization failed")
+	}
+
+	// the number of polynomials is bounded before allocating
+	buf.Reset()
+	if _, err := proof.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	binary.BigEndian.PutUint32(buf.Bytes()[2*bls12377.SizeOfG1AffineCompressed:], 0xffffffff)
+	if _, err := _proof.ReadFrom(&buf); err != ErrInvalidProofEncoding {
+		t.Fatalf("expected %v, got %v", ErrInvalidProofEncoding, err)
+	}
+
+	// wrong points
+	wrongPoints := [][]fr.Element{points[0], points[1], points[0], points[3], points[4]}
+	if err := BatchVerifyMultiPointsOpening(digests, &proof, wrongPoints, sha256.New(), testSRS); err == nil {
+		t.Fatal("verifying with wrong points should have failed")
+	}
+	{
+		// wrong claimed value
+		saved := proof.ClaimedValues[1][1]
+		proof.ClaimedValues[1][1].Double(&saved)
+		if err := BatchVerifyMultiPointsOpening(digests, &proof, points, sha256.New(), testSRS); err == nil {
+			t.Fatal("verifying wrong claimed values should have failed")
+		}
+		proof.ClaimedValues[1][1] = saved
+	}
+	{
+		// wrong quotient
+		saved := proof.W
+		proof.W.Add(&saved, &saved)
+		if err := BatchVerifyMultiPointsOpening(digests, &proof, points, sha256.New(), testSRS); err == nil {
+			t.Fatal("verifying wrong quotient should have failed")
+		}
+		proof.W = saved
+	}
+
+	// invalid sets of points
+	if _, err := BatchOpenMultiPoints(f[:1], digests[:1], [][]fr.Element{{}}, sha256.New(), testSRS); err != ErrInvalidPointSet {
+		t.Fatal("an empty set of points should be rejected")
+	}
+	if _, err := BatchOpenMultiPoints(f[:1], digests[:1], [][]fr.Element{{zeta, zeta}}, sha256.New(), testSRS); err != ErrInvalidPointSet {
+		t.Fatal("a set of points with duplicates should be rejected")
+	}
+}
+
+func TestOpenAll(t *testing.T) {
+
+	domain := fft.NewDomain(16)
+	for _, size := range []int{16, 11, 2} {
+		f := randomPolynomial(size)
+		digest, err := Commit(f, testSRS)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		proofs, err := OpenAll(f, domain, testSRS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(proofs) != int(domain.Cardinality) {
+			t.Fatal("a proof per point of the domain is expected")
+		}
+
+		// same proofs as Open
+		var point fr.Element
+		point.SetOne()
+		for i := range proofs {
+			expected, err := Open(f, point, testSRS)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(proofs[i], expected) {
+				t.Fatalf("size %d: proof at ω^%d differs from Open", size, i)
+			}
+			if err := Verify(&digest, &proofs[i], point, testSRS); err != nil {
+				t.Fatal(err)
+			}
+			point.Mul(&point, &domain.Generator)
+		}
+	}
+
+	if _, err := OpenAll(randomPolynomial(17), domain, testSRS); err != ErrInvalidPolynomialSize {
+		t.Fatal("a polynomial larger than the domain should be rejected")
+	}
+}
+
 func BenchmarkKZGCommit(b *testing.B) {
 	benchSRS, err := NewSRS(ecc.NextPowerOfTwo(benchSize), new(big.Int).SetInt64(42))
 	if err != nil {
@@ -0,0 +1,160 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package kzg
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+)
+
+var (
+	ErrNoLagrangeBasis   = errors.New("the SRS has no Lagrange basis for this domain")
+	ErrInvalidDomainSize = errors.New("invalid domain size (larger than SRS)")
+)
+
+// ToLagrangeBasis sets srs.Lagrange to the [Lᵢ(α)]G₁, Lᵢ being the Lagrange polynomials of domain,
+// computed from the first domain.Cardinality points of srs.G1:
+//
+//	[Lᵢ(α)]G₁ = 1/n ∑ⱼω⁻ⁱʲ[αʲ]G₁
+//
+// that is the inverse DFT of the points of srs.G1.
+func (srs *SRS) ToLagrangeBasis(domain *fft.Domain) error {
+	n := int(domain.Cardinality)
+	if n > len(srs.G1) {
+		return ErrInvalidDomainSize
+	}
+	points := make([]bls12377.G1Jac, n)
+	for i := range points {
+		points[i].FromAffine(&srs.G1[i])
+	}
+	domain.FFTInverseG1(points, fft.DIF)
+	fft.BitReverseG1(points)
+	srs.Lagrange = bls12377.BatchJacobianToAffineG1(points)
+	return nil
+}
+
+// CommitLagrange commits to a polynomial given by its values on the points ωⁱ of the domain
+// of the Lagrange basis of the SRS, in natural order, in Montgomery form. The missing values are zero.
+//
+// The digest is the same as the one of Commit on the canonical form of the polynomial.
+func CommitLagrange(evals []fr.Element, srs *SRS, nbTasks ...int) (Digest, error) {
+	if len(srs.Lagrange) == 0 {
+		return Digest{}, ErrNoLagrangeBasis
+	}
+	if len(evals) == 0 || len(evals) > len(srs.Lagrange) {
+		return Digest{}, ErrInvalidPolynomialSize
+	}
+
+	var res bls12377.G1Affine
+
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	if len(nbTasks) > 0 {
+		config.NbTasks = nbTasks[0]
+	}
+	if _, err := res.MultiExp(srs.Lagrange[:len(evals)], evals, config); err != nil {
+		return Digest{}, err
+	}
+
+	return res, nil
+}
+
+/*
+OpenLagrange computes an opening proof at point of a polynomial given by its values on domain, as in
+CommitLagrange. The proof is verified by Verify.
+
+The value at point is computed with the barycentric formula
+
+	f(z) = (zⁿ - 1)/n ∑ᵢfᵢωⁱ/(z - ωⁱ)
+
+and the quotient q = (f - f(z))/(X - z) in evaluation form, qᵢ = (fᵢ - f(z))/(ωⁱ - z). If z = ωᵏ is
+in the domain, qₖ = f'(ωᵏ) = -∑_{i≠k}qᵢωⁱ⁻ᵏ.
+*/
+func OpenLagrange(evals []fr.Element, point fr.Element, domain *fft.Domain, srs *SRS) (OpeningProof, error) {
+	n := int(domain.Cardinality)
+	if len(srs.Lagrange) != n {
+		return OpeningProof{}, ErrNoLagrangeBasis
+	}
+	if len(evals) == 0 || len(evals) > n {
+		return OpeningProof{}, ErrInvalidPolynomialSize
+	}
+	f := make([]fr.Element, n)
+	copy(f, evals)
+
+	// ωⁱ - z, and the index of z in the domain, if any
+	k := -1
+	diffs := make([]fr.Element, n)
+	omegas := make([]fr.Element, n)
+	omegas[0].SetOne()
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			omegas[i].Mul(&omegas[i-1], &domain.Generator)
+		}
+		diffs[i].Sub(&omegas[i], &point)
+		if diffs[i].IsZero() {
+			k = i
+			diffs[i].SetOne()
+		}
+	}
+	diffs = fr.BatchInvert(diffs)
+
+	res := OpeningProof{}
+	if k >= 0 {
+		res.ClaimedValue.Set(&f[k])
+	} else {
+		// f(z) = (1 - zⁿ)/n ∑ᵢfᵢωⁱ/(ωⁱ - z)
+		var t, acc fr.Element
+		for i := 0; i < n; i++ {
+			t.Mul(&f[i], &omegas[i]).Mul(&t, &diffs[i])
+			acc.Add(&acc, &t)
+		}
+		var zn fr.Element
+		zn.Exp(point, big.NewInt(int64(n)))
+		t.SetOne().Sub(&t, &zn).Mul(&t, &domain.CardinalityInv)
+		res.ClaimedValue.Mul(&acc, &t)
+	}
+
+	// the quotient, in evaluation form
+	q := f
+	for i := 0; i < n; i++ {
+		q[i].Sub(&f[i], &res.ClaimedValue).Mul(&q[i], &diffs[i])
+	}
+	if k >= 0 {
+		// ωⁱ⁻ᵏ = ωⁱ.ω⁻ᵏ = ωⁱ.ωⁿ⁻ᵏ
+		var t, acc fr.Element
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			t.Mul(&q[i], &omegas[(i+n-k)%n])
+			acc.Add(&acc, &t)
+		}
+		q[k].Neg(&acc)
+	}
+
+	var err error
+	res.H, err = CommitLagrange(q, srs)
+	if err != nil {
+		return OpeningProof{}, err
+	}
+
+	return res, nil
+}
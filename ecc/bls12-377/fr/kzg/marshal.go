@@ -17,11 +17,37 @@
 package kzg
 
 import (
-	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"encoding/binary"
+	"errors"
 	"io"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 )
 
-// WriteTo writes binary encoding of the SRS
+var (
+	ErrInvalidSRSEncoding   = errors.New("invalid SRS encoding")
+	ErrInvalidProofEncoding = errors.New("invalid proof encoding")
+)
+
+// maxDecodedLength bounds the untrusted lengths read by the decoders, before allocating
+const maxDecodedLength = 1 << 20
+
+// flags of the SRS encoding, marking the optional fields present in the encoding
+const (
+	srsFlagHiding   uint8 = 1 // the SRS has hiding generators, see NewHidingSRS
+	srsFlagLagrange uint8 = 2 // the SRS has a Lagrange basis, see ToLagrangeBasis
+)
+
+/*
+WriteTo writes binary encoding of the SRS:
+ 1. G2[0], G2[1] and G1, as in the first versions of the encoding
+ 2. a byte of flags, srsFlagHiding and srsFlagLagrange marking the optional fields
+ 3. H[0] and H[1] if the SRS is hiding
+ 4. the Lagrange points if the SRS has a Lagrange basis
+
+ReadFrom decodes the SRS encoded by the first versions, ending after G1, as a non hiding SRS.
+*/
 func (srs *SRS) WriteTo(w io.Writer) (int64, error) {
 	// encode the SRS
 	enc := bls12377.NewEncoder(w)
@@ -38,7 +64,28 @@ func (srs *SRS) WriteTo(w io.Writer) (int64, error) {
 		}
 	}
 
-	return enc.BytesWritten(), nil
+	// optional fields
+	var flags uint8
+	toEncode = toEncode[:0]
+	if !srs.H[0].IsInfinity() {
+		flags |= srsFlagHiding
+		toEncode = append(toEncode, &srs.H[0], &srs.H[1])
+	}
+	if srs.Lagrange != nil {
+		flags |= srsFlagLagrange
+		toEncode = append(toEncode, srs.Lagrange)
+	}
+	if _, err := w.Write([]byte{flags}); err != nil {
+		return enc.BytesWritten(), err
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten() + 1, err
+		}
+	}
+
+	return enc.BytesWritten() + 1, nil
 }
 
 // ReadFrom decodes SRS data from reader.
@@ -58,7 +105,34 @@ func (srs *SRS) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
-	return dec.BytesRead(), nil
+	// optional fields, absent from the encoding of the first versions
+	srs.H = [2]bls12377.G1Affine{}
+	srs.Lagrange = nil
+	var flags [1]byte
+	if _, err := io.ReadFull(r, flags[:]); err == io.EOF {
+		return dec.BytesRead(), nil
+	} else if err != nil {
+		return dec.BytesRead(), err
+	}
+	if flags[0]&^(srsFlagHiding|srsFlagLagrange) != 0 {
+		return dec.BytesRead() + 1, ErrInvalidSRSEncoding
+	}
+
+	toDecode = toDecode[:0]
+	if flags[0]&srsFlagHiding != 0 {
+		toDecode = append(toDecode, &srs.H[0], &srs.H[1])
+	}
+	if flags[0]&srsFlagLagrange != 0 {
+		toDecode = append(toDecode, &srs.Lagrange)
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead() + 1, err
+		}
+	}
+
+	return dec.BytesRead() + 1, nil
 }
 
 // WriteTo writes binary encoding of a OpeningProof
@@ -132,3 +206,104 @@ func (proof *BatchOpeningProof) ReadFrom(r io.Reader) (int64, error) {
 
 	return dec.BytesRead(), nil
 }
+
+// WriteTo writes binary encoding of a HidingOpeningProof
+func (proof *HidingOpeningProof) WriteTo(w io.Writer) (int64, error) {
+	enc := bls12377.NewEncoder(w)
+
+	toEncode := []interface{}{
+		&proof.H,
+		&proof.ClaimedValue,
+		&proof.ClaimedBlinding,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes HidingOpeningProof data from reader.
+func (proof *HidingOpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12377.NewDecoder(r)
+
+	toDecode := []interface{}{
+		&proof.H,
+		&proof.ClaimedValue,
+		&proof.ClaimedBlinding,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// WriteTo writes binary encoding of a MultiPointsOpeningProof: W, WPrime, then the number of
+// polynomials as a big endian uint32, followed by the claimed values of each polynomial
+func (proof *MultiPointsOpeningProof) WriteTo(w io.Writer) (int64, error) {
+	enc := bls12377.NewEncoder(w)
+
+	toEncode := []interface{}{
+		&proof.W,
+		&proof.WPrime,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(proof.ClaimedValues))); err != nil {
+		return enc.BytesWritten(), err
+	}
+	for k := range proof.ClaimedValues {
+		if err := enc.Encode(proof.ClaimedValues[k]); err != nil {
+			return enc.BytesWritten() + 4, err
+		}
+	}
+
+	return enc.BytesWritten() + 4, nil
+}
+
+// ReadFrom decodes MultiPointsOpeningProof data from reader.
+func (proof *MultiPointsOpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12377.NewDecoder(r)
+
+	toDecode := []interface{}{
+		&proof.W,
+		&proof.WPrime,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	var nbPolynomials uint32
+	if err := binary.Read(r, binary.BigEndian, &nbPolynomials); err != nil {
+		return dec.BytesRead(), err
+	}
+	if nbPolynomials > maxDecodedLength {
+		return dec.BytesRead() + 4, ErrInvalidProofEncoding
+	}
+	proof.ClaimedValues = nil
+	if nbPolynomials != 0 {
+		proof.ClaimedValues = make([][]fr.Element, nbPolynomials)
+	}
+	for k := range proof.ClaimedValues {
+		if err := dec.Decode(&proof.ClaimedValues[k]); err != nil {
+			return dec.BytesRead() + 4, err
+		}
+	}
+
+	return dec.BytesRead() + 4, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package permutation
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/dkzg"
+)
+
+// runParties runs f on each party of an in-process world of nbParties parties
+func runParties(t *testing.T, nbParties int, f func(c communicator.Communicator) error) {
+	t.Helper()
+	comms := communicator.NewChannels(nbParties)
+	errs := make([]error, nbParties)
+	var wg sync.WaitGroup
+	wg.Add(nbParties)
+	for i := 0; i < nbParties; i++ {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(comms[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("party %d: %v", i, err)
+		}
+	}
+}
+
+func TestDistributedProof(t *testing.T) {
+
+	const nbParties, size = 3, 8
+
+	// the vectors are distributed among the parties, the permutation mixes the slices
+	a := make([]fr.Element, nbParties*size)
+	b := make([]fr.Element, nbParties*size)
+	for i := range a {
+		a[i].SetUint64(uint64(4*i + 1))
+	}
+	for i := range b {
+		b[i].Set(&a[(5*i)%len(a)])
+	}
+
+	prove := func(a, b []fr.Element) (DistributedProof, *dkzg.SRS) {
+		var proof DistributedProof
+		var srs *dkzg.SRS
+		runParties(t, nbParties, func(c communicator.Communicator) error {
+			_srs, err := dkzg.NewSRS(2*size, []*big.Int{big.NewInt(13), big.NewInt(7)}, nil, c)
+			if err != nil {
+				return err
+			}
+			r := c.Rank()
+			_proof, err := ProveDistributed(_srs, a[r*size:(r+1)*size], b[r*size:(r+1)*size], c)
+			if err != nil {
+				return err
+			}
+			if r == 0 {
+				proof, srs = _proof, _srs
+			}
+			return nil
+		})
+		return proof, srs
+	}
+
+	// correct proof
+	{
+		proof, srs := prove(a, b)
+		err := VerifyDistributed(srs, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the products of the parties are bound to the proof
+		proof.products[0], proof.products[1] = proof.products[1], proof.products[0]
+		err = VerifyDistributed(srs, proof)
+		if err == nil {
+			t.Fatal("verifying a proof with permuted products should have failed")
+		}
+	}
+
+	// wrong proof
+	{
+		a[size+2].SetRandom()
+		proof, srs := prove(a, b)
+		err := VerifyDistributed(srs, proof)
+		if err == nil {
+			t.Fatal("verifying a proof of vectors that are not permuted should have failed")
+		}
+	}
+
+}
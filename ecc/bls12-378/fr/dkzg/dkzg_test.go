@@ -0,0 +1,1118 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr"
+)
+
+// testSRS re-used accross tests of the KZG scheme, testSRS[i] is the SRS of the party i
+var testSRS []*SRS
+
+const srsSize = 230
+
+// nbParties number of parties of the in-process world used in the tests, not a power of 2
+// so that the party domain is padded
+const nbParties = 5
+
+func init() {
+	comms := communicator.NewChannels(nbParties)
+	testSRS = make([]*SRS, nbParties)
+	for i := 0; i < nbParties; i++ {
+		var err error
+		testSRS[i], err = NewSRS(ecc.NextPowerOfTwo(srsSize), []*big.Int{big.NewInt(42), big.NewInt(27)}, nil, comms[i])
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// runParties runs f on each party of an in-process world of nbParties parties
+func runParties(t testing.TB, f func(c communicator.Communicator) error) {
+	t.Helper()
+	runWorld(t, nbParties, f)
+}
+
+// runWorld runs f on each party of an in-process world of size parties
+func runWorld(t testing.TB, size int, f func(c communicator.Communicator) error) {
+	t.Helper()
+	comms := communicator.NewChannels(size)
+	errs := make([]error, size)
+	var wg sync.WaitGroup
+	wg.Add(size)
+	for i := 0; i < size; i++ {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(comms[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("party %d: %v", i, err)
+		}
+	}
+}
+
+func TestDividePolyByXminusA(t *testing.T) {
+
+	const pSize = 230
+
+	// build random polynomial
+	pol := make([]fr.Element, pSize)
+	pol[0].SetRandom()
+	for i := 1; i < pSize; i++ {
+		pol[i] = pol[i-1]
+	}
+
+	// evaluate the polynomial at a random point
+	var point fr.Element
+	point.SetRandom()
+	evaluation := eval(pol, point)
+
+	// probabilistic test (using Schwartz Zippel lemma, evaluation at one point is enough)
+	var randPoint, xminusa fr.Element
+	randPoint.SetRandom()
+	polRandpoint := eval(pol, randPoint)
+	polRandpoint.Sub(&polRandpoint, &evaluation) // f(rand)-f(point)
+
+	// compute f-f(a)/x-a
+	h := dividePolyByXminusA(pol, evaluation, point)
+	pol = nil // h reuses this memory
+
+	if len(h) != 229 {
+		t.Fatal("inconsistant size of quotient")
+	}
+
+	hRandPoint := eval(h, randPoint)
+	xminusa.Sub(&randPoint, &point) // rand-point
+
+	// f(rand)-f(point)	==? h(rand)*(rand-point)
+	hRandPoint.Mul(&hRandPoint, &xminusa)
+
+	if !hRandPoint.Equal(&polRandpoint) {
+		t.Fatal("Error f-f(a)/x-a")
+	}
+}
+
+func TestSerializationSRS(t *testing.T) {
+
+	// create a SRS
+	srs, err := NewSRS(64, []*big.Int{big.NewInt(42), big.NewInt(27)}, NewPartyDomain(1, true), communicator.NewChannels(1)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// serialize it...
+	var buf bytes.Buffer
+	_, err = srs.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reconstruct the SRS
+	var _srs SRS
+	_, err = _srs.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// compare
+	if !reflect.DeepEqual(srs, &_srs) {
+		t.Fatal("scheme serialization failed")
+	}
+
+	// same without subgroup checks
+	buf.Reset()
+	if _, err = testSRS[1].WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := append([]byte{}, buf.Bytes()...)
+	var unsafeSRS SRS
+	if _, err = unsafeSRS.UnsafeReadFrom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(testSRS[1], &unsafeSRS) {
+		t.Fatal("unsafe scheme serialization failed")
+	}
+
+	// corrupted encodings
+	corrupt := func(offset int) []byte {
+		b := append([]byte{}, encoded...)
+		b[offset] ^= 0x80
+		return b
+	}
+
+	// headerless layout of the first versions, with the previous party domain
+	var legacy []byte
+	legacy = append(legacy, G2AffineToBytes(testSRS[1].G2[0])...)
+	legacy = append(legacy, G2AffineToBytes(testSRS[1].G2[1])...)
+	legacy = append(legacy, G1AffineArrayToBytes(testSRS[1].G1)...)
+	for _, tc := range []struct {
+		name    string
+		encoded []byte
+		err     error
+	}{
+		{"magic", corrupt(0), ErrInvalidSRSEncoding},
+		{"version", corrupt(4), ErrUnsupportedSRSVersion},
+		{"curve", corrupt(5), ErrSRSCurveMismatch},
+		{"rank", corrupt(7), ErrInvalidSRSEncoding},
+		{"size", corrupt(15), ErrInvalidSRSEncoding},
+		{"flags", corrupt(19), ErrInvalidSRSEncoding},
+		{"legacy", legacy, ErrInvalidSRSEncoding},
+	} {
+		var _srs SRS
+		if _, err := _srs.ReadFrom(bytes.NewReader(tc.encoded)); err != tc.err {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.err, err)
+		}
+	}
+	var truncated SRS
+	if _, err := truncated.ReadFrom(bytes.NewReader(encoded[:len(encoded)-1])); err == nil {
+		t.Fatal("truncated SRS should not be decoded")
+	}
+}
+
+func TestCommit(t *testing.T) {
+	runParties(t, func(c communicator.Communicator) error {
+
+		// create a polynomial
+		f := polynomial(60, uint64(c.Rank()))
+
+		// commit using the method from KZG
+		kzgCommit, err := Commit(f, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+
+		// all the nodes get the commitment with AllCommit
+		allCommit, err := AllCommit(f, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		commitBytes := allCommit.Bytes()
+		allCommitBytes, err := c.Gather(commitBytes[:], 0)
+		if err != nil {
+			return err
+		}
+		if c.Rank() != 0 {
+			return nil
+		}
+		for i := range allCommitBytes {
+			var commit bls12378.G1Affine
+			if _, err := commit.SetBytes(allCommitBytes[i]); err != nil {
+				return err
+			}
+			if !commit.Equal(&kzgCommit) {
+				t.Errorf("party %d got a different commitment with AllCommit", i)
+			}
+		}
+
+		// check commitment using manual commit
+		var x fr.Element
+		x.SetString("27")
+
+		ps := make([][]fr.Element, nbParties)
+		es := make([]fr.Element, nbParties)
+		bs := make([]bls12378.G1Affine, nbParties)
+		for i := 0; i < nbParties; i++ {
+			ps[i] = polynomial(60, uint64(i))
+			es[i] = eval(ps[i], x)
+			bs[i] = testSRS[i].G1[0]
+		}
+
+		config := ecc.MultiExpConfig{ScalarsMont: true}
+		var manualCommit bls12378.G1Affine
+		if _, err = manualCommit.MultiExp(bs, es, config); err != nil {
+			return err
+		}
+
+		// compare both results
+		if !kzgCommit.Equal(&manualCommit) {
+			t.Error("error KZG commitment")
+		}
+		return nil
+	})
+}
+
+func TestVerifySinglePoint(t *testing.T) {
+	runParties(t, func(c communicator.Communicator) error {
+
+		// create a polynomial
+		f := polynomial(60, uint64(c.Rank()))
+
+		// commit the polynomial
+		digest, err := Commit(f, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+
+		// compute opening proof at a random point
+		var point fr.Element
+		point.SetString("4321")
+		proof, _, err := Open(f, point, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+
+		if c.Rank() != 0 {
+			return nil
+		}
+
+		ps := make([][]fr.Element, nbParties)
+		es := make([]fr.Element, nbParties)
+		bs := make([]bls12378.G1Affine, nbParties)
+		for i := 0; i < nbParties; i++ {
+			ps[i] = polynomial(60, uint64(i))
+			es[i] = eval(ps[i], point)
+			bs[i] = testSRS[i].G1[0]
+		}
+
+		var expectedGroup bls12378.G1Affine
+		if _, err := expectedGroup.MultiExp(bs, es, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+			return err
+		}
+
+		// verify the claimed valued
+		if !proof.ClaimedDigest.Equal(&expectedGroup) {
+			t.Error("inconsistant claimed value")
+		}
+
+		// verify correct proof
+		err = Verify(&digest, &proof, point, testSRS[c.Rank()])
+		if err != nil {
+			return err
+		}
+		{
+			// verify wrong proof
+			var nexpectedGroup bls12378.G1Affine
+			nexpectedGroup.Add(&expectedGroup, &expectedGroup)
+			proof.ClaimedDigest = nexpectedGroup
+
+			err = Verify(&digest, &proof, point, testSRS[c.Rank()])
+			if err == nil {
+				t.Error("verifying wrong proof should have failed")
+			}
+		}
+		{
+			// verify wrong proof with quotient set to zero
+			// see https://cryptosubtlety.medium.com/00-8d4adcf4d255
+			proof.H.X.SetZero()
+			proof.H.Y.SetZero()
+			err = Verify(&digest, &proof, point, testSRS[c.Rank()])
+			if err == nil {
+				t.Error("verifying wrong proof should have failed")
+			}
+		}
+		return nil
+	})
+}
+
+func TestPartyDomainLagrangeAt(t *testing.T) {
+	for _, coset := range []bool{false, true} {
+		domain := NewPartyDomain(nbParties, coset)
+		points := domain.Points()
+		if len(points) != 8 {
+			t.Fatal("the party domain should be padded to the next power of 2")
+		}
+
+		var x fr.Element
+		x.SetRandom()
+		for _, x := range []fr.Element{x, points[2], points[nbParties]} {
+			l := domain.LagrangeAt(x)
+
+			// ∑ᵢLᵢ(x)xᵢʲ = xʲ for j < M
+			var xj fr.Element
+			xj.SetOne()
+			pj := make([]fr.Element, len(points))
+			for i := range pj {
+				pj[i].SetOne()
+			}
+			for j := 0; j < len(points); j++ {
+				var sum, t0 fr.Element
+				for i := range points {
+					t0.Mul(&l[i], &pj[i])
+					sum.Add(&sum, &t0)
+					pj[i].Mul(&pj[i], &points[i])
+				}
+				if !sum.Equal(&xj) {
+					t.Fatalf("coset=%v: wrong Lagrange polynomials", coset)
+				}
+				xj.Mul(&xj, &x)
+			}
+		}
+	}
+}
+
+// TestPartyDomainBasis pins the Lagrange basis of the party domain: the points are the powers
+// of the generator of fft.NewDomain, the SRS and the commitments depend on it.
+func TestPartyDomainBasis(t *testing.T) {
+	const omega = "14883435066912132898602823177192252573563475277852872717534549867115940151296"
+
+	var expected fr.Element
+	if _, err := expected.SetString(omega); err != nil {
+		t.Fatal(err)
+	}
+
+	// 3 parties are padded to the domain of size 4
+	for _, worldSize := range []int{3, 4} {
+		points := NewPartyDomain(worldSize, false).Points()
+		if len(points) != 4 {
+			t.Fatal("the party domain should be padded to the next power of 2")
+		}
+		var x fr.Element
+		x.SetOne()
+		for i := range points {
+			if !points[i].Equal(&x) {
+				t.Fatalf("world size %d: the %d-th point of the party domain should be ωⁱ", worldSize, i)
+			}
+			x.Mul(&x, &expected)
+		}
+	}
+
+	// Lₖ(τ) = (τ⁴-1)ωᵏ / (4(τ-ωᵏ)), as in the SRS
+	var tau, one fr.Element
+	tau.SetUint64(42)
+	one.SetOne()
+	l := NewPartyDomain(4, false).LagrangeAt(tau)
+	var omegaK fr.Element
+	omegaK.SetOne()
+	for k := range l {
+		var num, den fr.Element
+		num.Exp(tau, big.NewInt(4)).Sub(&num, &one).Mul(&num, &omegaK)
+		den.Sub(&tau, &omegaK).Mul(&den, new(fr.Element).SetUint64(4))
+		num.Div(&num, &den)
+		if !l[k].Equal(&num) {
+			t.Fatalf("wrong Lagrange polynomial L%d", k)
+		}
+		omegaK.Mul(&omegaK, &expected)
+	}
+}
+
+func TestVerifyClaimedValues(t *testing.T) {
+	runParties(t, func(c communicator.Communicator) error {
+		f := polynomial(60, uint64(c.Rank()))
+		var point fr.Element
+		point.SetString("4321")
+		proof, _, err := Open(f, point, testSRS[c.Rank()], c)
+		if err != nil || c.Rank() != 0 {
+			return err
+		}
+		if err := VerifyClaimedValues(&proof.ClaimedDigest, proof.ClaimedValues, testSRS[0]); err != nil {
+			return err
+		}
+		proof.ClaimedValues[1].Double(&proof.ClaimedValues[1])
+		if err := VerifyClaimedValues(&proof.ClaimedDigest, proof.ClaimedValues, testSRS[0]); err != ErrVerifyClaimedValues {
+			t.Error("verifying wrong claimed values should have failed")
+		}
+		if err := VerifyClaimedValues(&proof.ClaimedDigest, proof.ClaimedValues[1:], testSRS[0]); err != ErrInvalidNbDigests {
+			t.Error("a value per party is expected")
+		}
+		return nil
+	})
+}
+
+func TestDivideLagrangeByXminusA(t *testing.T) {
+
+	const size = 16
+	for _, coset := range []bool{false, true} {
+		domain := NewPartyDomain(size, coset)
+		points := domain.Points()
+
+		// random polynomial, in canonical and Lagrange form
+		fCanonical := make([]fr.Element, size)
+		for i := 0; i < size; i++ {
+			fCanonical[i].SetRandom()
+		}
+		f := make([]fr.Element, size)
+		for i := 0; i < size; i++ {
+			f[i] = eval(fCanonical, points[i])
+		}
+
+		var randPoint fr.Element
+		randPoint.SetRandom()
+
+		for _, a := range []fr.Element{randPoint, points[3]} {
+			fa, h := divideLagrangeByXminusA(f, a, domain)
+
+			expected := eval(fCanonical, a)
+			if !fa.Equal(&expected) {
+				t.Fatal("wrong evaluation in Lagrange form")
+			}
+
+			_f := make([]fr.Element, size)
+			copy(_f, fCanonical)
+			hCanonical := dividePolyByXminusA(_f, expected, a)
+			for i := 0; i < size; i++ {
+				hi := eval(hCanonical, points[i])
+				if !hi.Equal(&h[i]) {
+					t.Fatalf("coset=%v: wrong quotient in Lagrange form", coset)
+				}
+			}
+		}
+	}
+}
+
+func TestVerifyBivariate(t *testing.T) {
+	var x, y fr.Element
+	x.SetString("1234")
+	y.SetString("4321")
+
+	// SRS on a coset of the party domain
+	comms := communicator.NewChannels(nbParties)
+	cosetSRS := make([]*SRS, nbParties)
+	for i := range cosetSRS {
+		var err error
+		cosetSRS[i], err = NewSRS(64, []*big.Int{big.NewInt(42), big.NewInt(27)}, NewPartyDomain(nbParties, true), comms[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, srs := range [][]*SRS{testSRS, cosetSRS} {
+		domain := srs[0].PartyDomain()
+		points := domain.Points()
+
+		// x outside and inside the party domain, including a padding point
+		for _, x := range []fr.Element{x, points[1], points[nbParties]} {
+			runParties(t, func(c communicator.Communicator) error {
+
+				// create a polynomial
+				f := polynomial(60, uint64(c.Rank()))
+
+				// commit the polynomial
+				digest, err := Commit(f, srs[c.Rank()], c)
+				if err != nil {
+					return err
+				}
+
+				// compute opening proof at (x, y)
+				proof, err := OpenBivariate(f, x, y, srs[c.Rank()], c)
+				if err != nil {
+					return err
+				}
+
+				if c.Rank() != 0 {
+					return nil
+				}
+
+				// F(x, y) = ∑ᵢfᵢ(y)Lᵢ(x)
+				var expected fr.Element
+				l := domain.LagrangeAt(x)
+				for i := 0; i < nbParties; i++ {
+					fy := eval(polynomial(60, uint64(i)), y)
+					fy.Mul(&fy, &l[i])
+					expected.Add(&expected, &fy)
+				}
+				if !proof.ClaimedValue.Equal(&expected) {
+					t.Error("inconsistant claimed value")
+				}
+
+				// verify correct proof
+				if err := VerifyBivariate(&digest, &proof, x, y, srs[c.Rank()]); err != nil {
+					return err
+				}
+
+				{
+					// verify wrong claimed value (F(x, y) = 0 on the padding points)
+					wrongProof := proof
+					one := fr.One()
+					wrongProof.ClaimedValue.Add(&wrongProof.ClaimedValue, &one)
+					if err := VerifyBivariate(&digest, &wrongProof, x, y, srs[c.Rank()]); err == nil {
+						t.Error("verifying wrong claimed value should have failed")
+					}
+				}
+				{
+					// verify wrong proof with quotient set to zero
+					wrongProof := proof
+					wrongProof.HX.X.SetZero()
+					wrongProof.HX.Y.SetZero()
+					if err := VerifyBivariate(&digest, &wrongProof, x, y, srs[c.Rank()]); err == nil {
+						t.Error("verifying wrong quotient digest should have failed")
+					}
+				}
+				return nil
+			})
+		}
+	}
+}
+
+func TestBatchOpenSinglePoint(t *testing.T) {
+	runParties(t, func(c communicator.Communicator) error {
+
+		// create a polynomial
+		num := 10
+		fs := make([][]fr.Element, num)
+		digests := make([]bls12378.G1Affine, num)
+
+		var err error
+		for i := 0; i < num; i++ {
+			fs[i] = polynomial(60, uint64(c.Rank()), i)
+			digests[i], err = Commit(fs[i], testSRS[c.Rank()], c)
+			if err != nil {
+				return err
+			}
+		}
+
+		// compute opening proof at a random point
+		var point fr.Element
+		point.SetString("4321")
+		hfunc := sha256.New()
+		proof, evals, err := BatchOpenSinglePoint(fs, digests, point, hfunc, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+
+		if c.Rank() != 0 {
+			return nil
+		}
+
+		ps := make([][][]fr.Element, num)
+		es := make([][]fr.Element, num)
+		bs := make([]bls12378.G1Affine, nbParties)
+		for i := 0; i < num; i++ {
+			ps[i] = make([][]fr.Element, nbParties)
+			es[i] = make([]fr.Element, nbParties)
+			for j := 0; j < nbParties; j++ {
+				ps[i][j] = polynomial(60, uint64(j), i)
+				es[i][j] = eval(ps[i][j], point)
+				if !es[i][j].Equal(&evals[i][j]) {
+					t.Error("inconsistant evals")
+				}
+			}
+		}
+
+		for i := 0; i < nbParties; i++ {
+			bs[i] = testSRS[i].G1[0]
+		}
+
+		expectedGroups := make([]bls12378.G1Affine, num)
+		for i := 0; i < num; i++ {
+			if _, err := expectedGroups[i].MultiExp(bs, es[i], ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+				return err
+			}
+			if !proof.ClaimedDigests[i].Equal(&expectedGroups[i]) {
+				t.Error("inconsistant claimed digests for evaluation")
+			}
+		}
+
+		// verify correct proof
+		if err := BatchVerifySinglePoint(digests, &proof, point, hfunc, testSRS[c.Rank()]); err != nil {
+			return err
+		}
+
+		// the folded values of the parties are consistent with the folded digest
+		foldedProof, _, err := FoldProof(digests, &proof, point, hfunc)
+		if err != nil {
+			return err
+		}
+		var foldedDigest bls12378.G1Affine
+		if _, err := foldedDigest.MultiExp(bs, foldedProof.ClaimedValues, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+			return err
+		}
+		if !foldedDigest.Equal(&foldedProof.ClaimedDigest) {
+			t.Error("inconsistant folded claimed values")
+		}
+
+		{
+			// verify wrong proof
+			var nexpectedGroup bls12378.G1Affine
+			nexpectedGroup.Add(&proof.ClaimedDigests[0], &proof.ClaimedDigests[0])
+			proof.ClaimedDigests[0] = nexpectedGroup
+
+			if err := BatchVerifySinglePoint(digests, &proof, point, hfunc, testSRS[c.Rank()]); err == nil {
+				t.Error("verifying wrong claimed digests should have failed")
+			}
+		}
+		{
+			// verify wrong proof with quotient set to zero
+			// see https://cryptosubtlety.medium.com/00-8d4adcf4d255
+			proof.H.X.SetZero()
+			proof.H.Y.SetZero()
+			if err := BatchVerifySinglePoint(digests, &proof, point, hfunc, testSRS[c.Rank()]); err == nil {
+				t.Error("verifying wrong quotient digest should have failed")
+			}
+		}
+		return nil
+	})
+}
+
+func TestBatchOpeningMessage(t *testing.T) {
+	num := 5
+	msg := batchOpeningMessage{
+		ClaimedValues:  make([]fr.Element, num),
+		ClaimedDigests: make([]bls12378.G1Affine, num),
+	}
+	for i := 0; i < num; i++ {
+		msg.ClaimedValues[i].SetRandom()
+		msg.ClaimedDigests[i] = testSRS[0].G1[i]
+	}
+	frame := msg.marshal()
+	if len(frame) != 9+num*(fr.Bytes+bls12378.SizeOfG1AffineUncompressed) {
+		t.Fatal("unexpected frame size")
+	}
+
+	var _msg batchOpeningMessage
+	if err := _msg.unmarshal(frame); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(msg, _msg) {
+		t.Fatal("batch opening message round trip failed")
+	}
+
+	// empty message
+	if err := _msg.unmarshal((&batchOpeningMessage{}).marshal()); err != nil || len(_msg.ClaimedValues) != 0 {
+		t.Fatal("empty batch opening message round trip failed")
+	}
+
+	// invalid frames
+	wrongVersion := append([]byte{}, frame...)
+	wrongVersion[0]++
+	if err := _msg.unmarshal(wrongVersion); err != ErrUnsupportedMessageVersion {
+		t.Fatal("frame with a wrong version should be rejected")
+	}
+	if err := _msg.unmarshal(frame[:len(frame)-1]); err != ErrInvalidMessage {
+		t.Fatal("truncated frame should be rejected")
+	}
+	wrongCount := append([]byte{}, frame...)
+	wrongCount[8]++
+	if err := _msg.unmarshal(wrongCount); err != ErrInvalidMessage {
+		t.Fatal("frame with a wrong number of polynomials should be rejected")
+	}
+}
+
+// serializable is implemented by the proofs
+type serializable interface {
+	io.ReaderFrom
+	io.WriterTo
+	WriteRawTo(w io.Writer) (int64, error)
+}
+
+// testRoundTrip encodes src, compressed and raw, and decodes it in dst
+func testRoundTrip(t *testing.T, src, dst serializable) {
+	var buf bytes.Buffer
+	for _, raw := range []bool{false, true} {
+		buf.Reset()
+		var written int64
+		var err error
+		if raw {
+			written, err = src.WriteRawTo(&buf)
+		} else {
+			written, err = src.WriteTo(&buf)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != int64(buf.Len()) {
+			t.Fatalf("raw=%v: %d bytes written, %d reported", raw, buf.Len(), written)
+		}
+		read, err := dst.ReadFrom(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read != written {
+			t.Fatalf("raw=%v: %d bytes written, %d read", raw, written, read)
+		}
+		if !reflect.DeepEqual(src, dst) {
+			t.Fatalf("raw=%v: serialization failed", raw)
+		}
+	}
+}
+
+func TestSerializationProofs(t *testing.T) {
+	runParties(t, func(c communicator.Communicator) error {
+
+		num := 3
+		fs := make([][]fr.Element, num)
+		digests := make([]Digest, num)
+		var err error
+		for i := 0; i < num; i++ {
+			fs[i] = polynomial(60, uint64(c.Rank()), i)
+			if digests[i], err = Commit(fs[i], testSRS[c.Rank()], c); err != nil {
+				return err
+			}
+		}
+
+		// random point, chosen by the root
+		var x, y fr.Element
+		x.SetRandom()
+		y.SetRandom()
+		xBytes, yBytes := x.Bytes(), y.Bytes()
+		if err := c.Broadcast(xBytes[:], 0); err != nil {
+			return err
+		}
+		if err := c.Broadcast(yBytes[:], 0); err != nil {
+			return err
+		}
+		x.SetBytes(xBytes[:])
+		y.SetBytes(yBytes[:])
+
+		proof, _, err := Open(fs[0], y, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		batchProof, _, err := BatchOpenSinglePoint(fs, digests, y, sha256.New(), testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		bivariateProof, err := OpenBivariate(fs[1], x, y, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		if c.Rank() != 0 {
+			return nil
+		}
+		if len(proof.ClaimedValues) != nbParties || len(batchProof.ClaimedValues) != num {
+			t.Error("claimed values of the parties should be in the proofs")
+		}
+
+		testRoundTrip(t, &proof, &OpeningProof{})
+		testRoundTrip(t, &batchProof, &BatchOpeningProof{})
+		testRoundTrip(t, &bivariateProof, &BivariateOpeningProof{})
+
+		// digests
+		var buf bytes.Buffer
+		if err := bls12378.NewEncoder(&buf).Encode(digests); err != nil {
+			return err
+		}
+		var _digests []Digest
+		if err := bls12378.NewDecoder(&buf).Decode(&_digests); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(digests, _digests) {
+			t.Error("digests serialization failed")
+		}
+
+		// proofs without the values of the parties
+		testRoundTrip(t, &OpeningProof{H: proof.H, ClaimedDigest: proof.ClaimedDigest}, &OpeningProof{})
+		testRoundTrip(t, &BatchOpeningProof{H: batchProof.H, ClaimedDigests: batchProof.ClaimedDigests}, &BatchOpeningProof{})
+		return nil
+	})
+}
+
+func TestSerializationProofsLengths(t *testing.T) {
+	encode := func(proof io.WriterTo) []byte {
+		var buf bytes.Buffer
+		if _, err := proof.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	// number of polynomials of a multi points opening proof, after W and WPrime
+	oversized := encode(&MultiPointsOpeningProof{})
+	binary.BigEndian.PutUint32(oversized[2*bls12378.SizeOfG1AffineCompressed:], maxDecodedLength+1)
+
+	for _, tc := range []struct {
+		name    string
+		encoded []byte
+		proof   io.ReaderFrom
+	}{
+		{
+			"batch values",
+			encode(&BatchOpeningProof{ClaimedDigests: make([]Digest, 2), ClaimedValues: make([][]fr.Element, 3)}),
+			&BatchOpeningProof{},
+		},
+		{
+			"multi points values",
+			encode(&MultiPointsOpeningProof{ClaimedDigests: make([][]Digest, 2), ClaimedValues: make([][][]fr.Element, 1)}),
+			&MultiPointsOpeningProof{},
+		},
+		{
+			"multi points points",
+			encode(&MultiPointsOpeningProof{
+				ClaimedDigests: [][]Digest{make([]Digest, 1), make([]Digest, 2)},
+				ClaimedValues:  [][][]fr.Element{make([][]fr.Element, 1), make([][]fr.Element, 1)},
+			}),
+			&MultiPointsOpeningProof{},
+		},
+		{"multi points oversized", oversized, &MultiPointsOpeningProof{}},
+	} {
+		if _, err := tc.proof.ReadFrom(bytes.NewReader(tc.encoded)); err != ErrInvalidProofEncoding {
+			t.Fatalf("%s: expected %v, got %v", tc.name, ErrInvalidProofEncoding, err)
+		}
+	}
+}
+
+const benchSize = 1 << 16
+
+// benchComm is a world with a single party, used in the benchmarks
+var benchComm = communicator.NewChannels(1)[0]
+
+func BenchmarkKZGCommit(b *testing.B) {
+	benchSRS, err := NewSRS(ecc.NextPowerOfTwo(benchSize), []*big.Int{big.NewInt(42), big.NewInt(27)}, nil, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+	// random polynomial
+	p := polynomial(benchSize/2, 0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Commit(p, benchSRS, benchComm)
+	}
+}
+
+func BenchmarkDivideByXMinusA(b *testing.B) {
+	const pSize = 1 << 22
+
+	// build random polynomial
+	pol := make([]fr.Element, pSize)
+	pol[0].SetRandom()
+	for i := 1; i < pSize; i++ {
+		pol[i] = pol[i-1]
+	}
+	var a, fa fr.Element
+	a.SetRandom()
+	fa.SetRandom()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dividePolyByXminusA(pol, fa, a)
+		pol = pol[:pSize]
+		pol[pSize-1] = pol[0]
+	}
+}
+
+func BenchmarkKZGOpen(b *testing.B) {
+	benchSRS, err := NewSRS(ecc.NextPowerOfTwo(benchSize), []*big.Int{big.NewInt(42), big.NewInt(27)}, nil, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// random polynomial
+	p := polynomial(benchSize/2, 0)
+	var r fr.Element
+	r.SetRandom()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = Open(p, r, benchSRS, benchComm)
+	}
+}
+
+func BenchmarkKZGVerify(b *testing.B) {
+	benchSRS, err := NewSRS(ecc.NextPowerOfTwo(benchSize), []*big.Int{big.NewInt(42), big.NewInt(27)}, nil, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// random polynomial
+	p := polynomial(benchSize/2, 0)
+	var r fr.Element
+	r.SetRandom()
+
+	// commit
+	comm, err := Commit(p, benchSRS, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// open
+	openingProof, _, err := Open(p, r, benchSRS, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := Verify(&comm, &openingProof, r, benchSRS)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkKZGBatchOpen10(b *testing.B) {
+	benchSRS, err := NewSRS(ecc.NextPowerOfTwo(benchSize), []*big.Int{big.NewInt(42), big.NewInt(27)}, nil, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// 10 random polynomials
+	var ps [10][]fr.Element
+	for i := 0; i < 10; i++ {
+		ps[i] = polynomial(benchSize/2, 0)
+	}
+
+	// commitments
+	var commitments [10]Digest
+	for i := 0; i < 10; i++ {
+		commitments[i], _ = Commit(ps[i], benchSRS, benchComm)
+	}
+
+	// pick a hash function
+	hf := sha256.New()
+
+	var r fr.Element
+	r.SetRandom()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err := BatchOpenSinglePoint(ps[:], commitments[:], r, hf, benchSRS, benchComm)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkKZGBatchVerify10(b *testing.B) {
+	benchSRS, err := NewSRS(ecc.NextPowerOfTwo(benchSize), []*big.Int{big.NewInt(42), big.NewInt(27)}, nil, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// 10 random polynomials
+	var ps [10][]fr.Element
+	for i := 0; i < 10; i++ {
+		ps[i] = polynomial(benchSize/2, 0)
+	}
+
+	// commitments
+	var commitments [10]Digest
+	for i := 0; i < 10; i++ {
+		commitments[i], _ = Commit(ps[i], benchSRS, benchComm)
+	}
+
+	// pick a hash function
+	hf := sha256.New()
+
+	var r fr.Element
+	r.SetRandom()
+
+	proof, _, err := BatchOpenSinglePoint(ps[:], commitments[:], r, hf, benchSRS, benchComm)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := BatchVerifySinglePoint(commitments[:], &proof, r, hf, benchSRS)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func polynomial(size int, num uint64, idx ...int) []fr.Element {
+	f := make([]fr.Element, size)
+	for i := 0; i < size; i++ {
+		tmp := fr.NewElement(num)
+		tmp2 := fr.NewElement(uint64(i))
+		f[i].Add(&tmp2, &tmp)
+		if len(idx) > 0 {
+			tmp3 := fr.NewElement(uint64(idx[0]*3 + 1))
+			f[i].Add(&f[i], &tmp3)
+		}
+	}
+	return f
+}
+
+func BenchmarkDKZGBatchOpenSinglePoint(b *testing.B) {
+	for _, num := range []int{1, 16, 128} {
+		b.Run(fmt.Sprintf("polynomials=%d", num), func(b *testing.B) {
+			polynomials := make([][][]fr.Element, nbParties)
+			for i := range polynomials {
+				polynomials[i] = make([][]fr.Element, num)
+				for k := range polynomials[i] {
+					polynomials[i][k] = polynomial(64, uint64(i), k)
+				}
+			}
+			digests := make([]Digest, num)
+			var point fr.Element
+			point.SetRandom()
+
+			b.ResetTimer()
+			runParties(b, func(c communicator.Communicator) error {
+				for i := 0; i < b.N; i++ {
+					if _, _, err := BatchOpenSinglePoint(polynomials[c.Rank()], digests, point, sha256.New(), testSRS[c.Rank()], c); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+	}
+}
+
+// BenchmarkGatherClaims compares the single framed message per party sent in BatchOpenSinglePoint
+// with two gathers (value and digest) per polynomial
+func BenchmarkGatherClaims(b *testing.B) {
+	const num = 128
+	msg := batchOpeningMessage{
+		ClaimedValues:  make([]fr.Element, num),
+		ClaimedDigests: make([]bls12378.G1Affine, num),
+	}
+	for k := 0; k < num; k++ {
+		msg.ClaimedValues[k].SetRandom()
+		msg.ClaimedDigests[k] = testSRS[0].G1[k]
+	}
+
+	b.Run("framed", func(b *testing.B) {
+		runParties(b, func(c communicator.Communicator) error {
+			for i := 0; i < b.N; i++ {
+				frames, err := c.Gather(msg.marshal(), 0)
+				if err != nil {
+					return err
+				}
+				for j := range frames {
+					var _msg batchOpeningMessage
+					if err := _msg.unmarshal(frames[j]); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	})
+
+	b.Run("per polynomial", func(b *testing.B) {
+		runParties(b, func(c communicator.Communicator) error {
+			for i := 0; i < b.N; i++ {
+				for k := 0; k < num; k++ {
+					value := msg.ClaimedValues[k].Bytes()
+					values, err := c.Gather(value[:], 0)
+					if err != nil {
+						return err
+					}
+					digests, err := c.Gather(G1AffineToBytes(msg.ClaimedDigests[k]), 0)
+					if err != nil {
+						return err
+					}
+					for j := range values {
+						var v fr.Element
+						v.SetBytes(values[j])
+						_ = BytesToG1Affine(digests[j])
+					}
+				}
+			}
+			return nil
+		})
+	})
+}
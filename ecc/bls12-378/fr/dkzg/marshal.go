@@ -0,0 +1,737 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr"
+)
+
+var (
+	ErrInvalidSRSEncoding    = errors.New("dkzg: invalid SRS encoding")
+	ErrUnsupportedSRSVersion = errors.New("dkzg: unsupported SRS encoding version")
+	ErrSRSCurveMismatch      = errors.New("dkzg: SRS encoded for another curve")
+	ErrInvalidProofEncoding  = errors.New("dkzg: invalid proof encoding")
+
+	ErrUnsupportedMessageVersion = errors.New("dkzg: unsupported message version")
+)
+
+func uint64ArrayToBytes(a []uint64) []byte {
+	b := make([]byte, 8*len(a))
+	for i := 0; i < len(a); i++ {
+		binary.LittleEndian.PutUint64(b[i*8:], a[i])
+	}
+	return b
+}
+func BytesToUint64Array(b []byte) []uint64 {
+	a := make([]uint64, len(b)/8)
+	for i := 0; i < len(a); i++ {
+		a[i] = binary.LittleEndian.Uint64(b[i*8:])
+	}
+	return a
+}
+
+// g1Limbs returns the limbs of the coordinates of p, in the order they are serialized
+func g1Limbs(p *bls12378.G1Affine) [][]uint64 {
+	return [][]uint64{p.X[:], p.Y[:]}
+}
+
+// g2Limbs returns the limbs of the coordinates of p, in the order they are serialized
+func g2Limbs(p *bls12378.G2Affine) [][]uint64 {
+	return [][]uint64{p.X.A0[:], p.X.A1[:], p.Y.A0[:], p.Y.A1[:]}
+}
+
+func limbsToBytes(limbs [][]uint64) []byte {
+	b := make([]byte, 0)
+	for _, l := range limbs {
+		b = append(b, uint64ArrayToBytes(l)...)
+	}
+	return b
+}
+
+func bytesToLimbs(b []byte, limbs [][]uint64) {
+	for _, l := range limbs {
+		copy(l, BytesToUint64Array(b[:8*len(l)]))
+		b = b[8*len(l):]
+	}
+}
+
+func G1AffineToBytes(p bls12378.G1Affine) []byte {
+	return limbsToBytes(g1Limbs(&p))
+}
+
+func G1AffineArrayToBytes(a []bls12378.G1Affine) []byte {
+	b := make([]byte, 0)
+	for _, v := range a {
+		b = append(b, G1AffineToBytes(v)...)
+	}
+	return b
+}
+
+func BytesToG1Affine(b []byte) bls12378.G1Affine {
+	var p bls12378.G1Affine
+	bytesToLimbs(b, g1Limbs(&p))
+	return p
+}
+
+func BytesToG1AffineArray(b []byte) []bls12378.G1Affine {
+	a := make([]bls12378.G1Affine, len(b)/bls12378.SizeOfG1AffineUncompressed)
+	for i := 0; i < len(a); i++ {
+		a[i] = BytesToG1Affine(b[i*bls12378.SizeOfG1AffineUncompressed : (i+1)*bls12378.SizeOfG1AffineUncompressed])
+	}
+	return a
+}
+
+func BytesToG2Affine(b []byte) bls12378.G2Affine {
+	var p bls12378.G2Affine
+	bytesToLimbs(b, g2Limbs(&p))
+	return p
+}
+
+func BytesToG2AffineArray(b []byte) []bls12378.G2Affine {
+	a := make([]bls12378.G2Affine, len(b)/bls12378.SizeOfG2AffineUncompressed)
+	for i := 0; i < len(a); i++ {
+		a[i] = BytesToG2Affine(b[i*bls12378.SizeOfG2AffineUncompressed : (i+1)*bls12378.SizeOfG2AffineUncompressed])
+	}
+	return a
+}
+
+func G2AffineToBytes(p bls12378.G2Affine) []byte {
+	return limbsToBytes(g2Limbs(&p))
+}
+
+func G2AffineArrayToBytes(a []bls12378.G2Affine) []byte {
+	b := make([]byte, 0)
+	for _, v := range a {
+		b = append(b, G2AffineToBytes(v)...)
+	}
+	return b
+}
+
+// elementsToBytes returns the concatenation of the v[i].Bytes()
+func elementsToBytes(v []fr.Element) []byte {
+	b := make([]byte, 0, len(v)*fr.Bytes)
+	for i := range v {
+		e := v[i].Bytes()
+		b = append(b, e[:]...)
+	}
+	return b
+}
+
+// bytesToElements decodes b, built by elementsToBytes, in v
+func bytesToElements(v []fr.Element, b []byte) {
+	for i := range v {
+		v[i].SetBytes(b[i*fr.Bytes : (i+1)*fr.Bytes])
+	}
+}
+
+// batchOpeningMessageVersion version of the layout of batchOpeningMessage
+const batchOpeningMessageVersion uint8 = 1
+
+// batchOpeningMessage is sent by each node to the root node in BatchOpenSinglePoint
+type batchOpeningMessage struct {
+	ClaimedValues  []fr.Element        // f_k(y) for each polynomial f_k of the node
+	ClaimedDigests []bls12378.G1Affine // digests of the ClaimedValues
+}
+
+/*
+marshal returns the frame of the message, all the integers are big endian:
+
+	version   uint8  batchOpeningMessageVersion
+	length    uint32 number of bytes following this field
+	n         uint32 number of polynomials
+	values    n fr.Element, as in fr.Element.Bytes
+	digests   n G1 points, as in G1AffineToBytes
+*/
+func (msg *batchOpeningMessage) marshal() []byte {
+	n := len(msg.ClaimedValues)
+	length := 4 + n*(fr.Bytes+bls12378.SizeOfG1AffineUncompressed)
+	b := make([]byte, 9, 5+length)
+	b[0] = batchOpeningMessageVersion
+	binary.BigEndian.PutUint32(b[1:5], uint32(length))
+	binary.BigEndian.PutUint32(b[5:9], uint32(n))
+	for i := range msg.ClaimedValues {
+		v := msg.ClaimedValues[i].Bytes()
+		b = append(b, v[:]...)
+	}
+	for i := range msg.ClaimedDigests {
+		b = append(b, G1AffineToBytes(msg.ClaimedDigests[i])...)
+	}
+	return b
+}
+
+// unmarshal decodes a frame built by marshal
+func (msg *batchOpeningMessage) unmarshal(b []byte) error {
+	if len(b) < 9 {
+		return ErrInvalidMessage
+	}
+	if b[0] != batchOpeningMessageVersion {
+		return ErrUnsupportedMessageVersion
+	}
+	length := binary.BigEndian.Uint32(b[1:5])
+	n := binary.BigEndian.Uint32(b[5:9])
+	if uint64(length) != uint64(len(b)-5) ||
+		uint64(length) != 4+uint64(n)*(fr.Bytes+bls12378.SizeOfG1AffineUncompressed) {
+		return ErrInvalidMessage
+	}
+	b = b[9:]
+
+	msg.ClaimedValues = make([]fr.Element, n)
+	for i := range msg.ClaimedValues {
+		msg.ClaimedValues[i].SetBytes(b[:fr.Bytes])
+		b = b[fr.Bytes:]
+	}
+	msg.ClaimedDigests = BytesToG1AffineArray(b)
+	return nil
+}
+
+// srsMagic identifies an encoded dkzg SRS ("dkzg" in ASCII)
+const srsMagic uint32 = 0x646b7a67
+
+// srsVersion version of the SRS encoding, the version 1 had no party domain information
+const srsVersion uint8 = 2
+
+// srsFlagCoset is set in srsHeader.Flags if the party domain is a coset
+const srsFlagCoset uint8 = 1
+
+// srsFlagHiding is set in srsHeader.Flags if the SRS has hiding generators, see NewHidingSRS
+const srsFlagHiding uint8 = 2
+
+// srsHeader precedes the points in the SRS encoding, all the fields are big endian
+type srsHeader struct {
+	Magic     uint32 // srsMagic
+	Version   uint8  // srsVersion
+	CurveID   uint16 // ecc.ID of the curve
+	Rank      uint32 // rank of the party owning the SRS
+	WorldSize uint32 // number of parties
+	Size      uint32 // number of points in G1
+	Flags     uint8  // srsFlagCoset | srsFlagHiding
+}
+
+/*
+WriteTo writes binary encoding of the SRS
+
+The encoding is made of
+ 1. the header (magic number, version, curve ID, rank, world size, size, flags), see srsHeader
+ 2. G2[0], G2[1], G2[2], as compressed points
+ 3. the number of Lagrange points as a big endian uint32, followed by the points, compressed
+ 4. the number of points in G1 as a big endian uint32, followed by the G1 points, compressed
+ 5. if srsFlagHiding is set, H[0], H[1], and the HLagrange points encoded as the Lagrange points
+
+Points are compressed as in the Encoder of the curve package.
+*/
+func (srs *SRS) WriteTo(w io.Writer) (int64, error) {
+	header := srsHeader{
+		Magic:     srsMagic,
+		Version:   srsVersion,
+		CurveID:   uint16(ecc.BLS12_378),
+		Rank:      uint32(srs.Rank),
+		WorldSize: uint32(srs.WorldSize),
+		Size:      uint32(len(srs.G1)),
+	}
+	if srs.Coset {
+		header.Flags |= srsFlagCoset
+	}
+	if srs.HLagrange != nil {
+		header.Flags |= srsFlagHiding
+	}
+	if err := binary.Write(w, binary.BigEndian, &header); err != nil {
+		return 0, err
+	}
+	n := int64(binary.Size(&header))
+
+	enc := bls12378.NewEncoder(w)
+	toEncode := []interface{}{
+		&srs.G2[0],
+		&srs.G2[1],
+		&srs.G2[2],
+		srs.Lagrange,
+		srs.G1,
+	}
+	if srs.HLagrange != nil {
+		toEncode = append(toEncode, &srs.H[0], &srs.H[1], srs.HLagrange)
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+
+	return n + enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes SRS data from reader.
+//
+// The points are checked to be in the correct subgroup.
+func (srs *SRS) ReadFrom(r io.Reader) (int64, error) {
+	return srs.readFrom(r)
+}
+
+// UnsafeReadFrom decodes SRS data from reader, without checking that the points are in
+// the correct subgroup. It should only be used on trusted data.
+func (srs *SRS) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return srs.readFrom(r, bls12378.NoSubgroupChecks())
+}
+
+func (srs *SRS) readFrom(r io.Reader, decOptions ...func(*bls12378.Decoder)) (int64, error) {
+	var header srsHeader
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return 0, err
+	}
+	n := int64(binary.Size(&header))
+	if header.Magic != srsMagic {
+		return n, ErrInvalidSRSEncoding
+	}
+	if header.Version != srsVersion {
+		return n, ErrUnsupportedSRSVersion
+	}
+	if header.CurveID != uint16(ecc.BLS12_378) {
+		return n, ErrSRSCurveMismatch
+	}
+	if header.Rank >= header.WorldSize || header.Flags&^(srsFlagCoset|srsFlagHiding) != 0 {
+		return n, ErrInvalidSRSEncoding
+	}
+
+	dec := bls12378.NewDecoder(r, decOptions...)
+	toDecode := []interface{}{
+		&srs.G2[0],
+		&srs.G2[1],
+		&srs.G2[2],
+		&srs.Lagrange,
+		&srs.G1,
+	}
+	hiding := header.Flags&srsFlagHiding != 0
+	if hiding {
+		toDecode = append(toDecode, &srs.H[0], &srs.H[1], &srs.HLagrange)
+	} else {
+		srs.H, srs.HLagrange = [2]bls12378.G1Affine{}, nil
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return n + dec.BytesRead(), err
+		}
+	}
+	if len(srs.G1) != int(header.Size) || uint64(len(srs.Lagrange)) != ecc.NextPowerOfTwo(uint64(header.WorldSize)) {
+		return n + dec.BytesRead(), ErrInvalidSRSEncoding
+	}
+	if hiding && len(srs.HLagrange) != len(srs.Lagrange) {
+		return n + dec.BytesRead(), ErrInvalidSRSEncoding
+	}
+	srs.Rank = int(header.Rank)
+	srs.WorldSize = int(header.WorldSize)
+	srs.Coset = header.Flags&srsFlagCoset != 0
+
+	return n + dec.BytesRead(), nil
+}
+
+// maxDecodedLength bounds the untrusted lengths read by the decoders, before allocating
+const maxDecodedLength = 1 << 20
+
+// WriteTo writes binary encoding of the PowersOfTau
+func (pt *PowersOfTau) WriteTo(w io.Writer) (int64, error) {
+	enc := bls12378.NewEncoder(w)
+
+	for i := range pt.G2 {
+		if err := enc.Encode(&pt.G2[i]); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	// number of rows, each row is then encoded with its length
+	if err := binary.Write(w, binary.BigEndian, uint32(len(pt.G1))); err != nil {
+		return enc.BytesWritten(), err
+	}
+	for i := range pt.G1 {
+		if err := enc.Encode(pt.G1[i]); err != nil {
+			return enc.BytesWritten() + 4, err
+		}
+	}
+
+	return enc.BytesWritten() + 4, nil
+}
+
+// ReadFrom decodes PowersOfTau data from reader.
+func (pt *PowersOfTau) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12378.NewDecoder(r)
+
+	for i := range pt.G2 {
+		if err := dec.Decode(&pt.G2[i]); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	var nbRows uint32
+	if err := binary.Read(r, binary.BigEndian, &nbRows); err != nil {
+		return dec.BytesRead(), err
+	}
+	if nbRows > maxDecodedLength {
+		return dec.BytesRead() + 4, ErrInvalidPowersOfTau
+	}
+	pt.G1 = make([][]bls12378.G1Affine, nbRows)
+	for i := range pt.G1 {
+		if err := dec.Decode(&pt.G1[i]); err != nil {
+			return dec.BytesRead() + 4, err
+		}
+	}
+
+	return dec.BytesRead() + 4, nil
+}
+
+// WriteTo writes binary encoding of an OpeningProof, with compressed points
+func (proof *OpeningProof) WriteTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w)
+}
+
+// WriteRawTo writes binary encoding of an OpeningProof, with uncompressed points
+func (proof *OpeningProof) WriteRawTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w, bls12378.RawEncoding())
+}
+
+func (proof *OpeningProof) writeTo(w io.Writer, encOptions ...func(*bls12378.Encoder)) (int64, error) {
+	enc := bls12378.NewEncoder(w, encOptions...)
+
+	toEncode := []interface{}{
+		&proof.H,
+		&proof.ClaimedDigest,
+		proof.ClaimedValues,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes OpeningProof data from reader, compressed or not.
+func (proof *OpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12378.NewDecoder(r)
+
+	toDecode := []interface{}{
+		&proof.H,
+		&proof.ClaimedDigest,
+		&proof.ClaimedValues,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// WriteTo writes binary encoding of a HidingOpeningProof, with compressed points
+func (proof *HidingOpeningProof) WriteTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w)
+}
+
+// WriteRawTo writes binary encoding of a HidingOpeningProof, with uncompressed points
+func (proof *HidingOpeningProof) WriteRawTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w, bls12378.RawEncoding())
+}
+
+func (proof *HidingOpeningProof) writeTo(w io.Writer, encOptions ...func(*bls12378.Encoder)) (int64, error) {
+	enc := bls12378.NewEncoder(w, encOptions...)
+
+	toEncode := []interface{}{
+		&proof.H,
+		&proof.ClaimedDigest,
+		proof.ClaimedValues,
+		proof.ClaimedBlindings,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes HidingOpeningProof data from reader, compressed or not.
+func (proof *HidingOpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12378.NewDecoder(r)
+
+	toDecode := []interface{}{
+		&proof.H,
+		&proof.ClaimedDigest,
+		&proof.ClaimedValues,
+		&proof.ClaimedBlindings,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// WriteTo writes binary encoding of a BatchOpeningProof, with compressed points
+func (proof *BatchOpeningProof) WriteTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w)
+}
+
+// WriteRawTo writes binary encoding of a BatchOpeningProof, with uncompressed points
+func (proof *BatchOpeningProof) WriteRawTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w, bls12378.RawEncoding())
+}
+
+func (proof *BatchOpeningProof) writeTo(w io.Writer, encOptions ...func(*bls12378.Encoder)) (int64, error) {
+	enc := bls12378.NewEncoder(w, encOptions...)
+
+	toEncode := []interface{}{
+		&proof.H,
+		proof.ClaimedDigests,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	// number of polynomials, the values of each polynomial are then encoded with their length
+	if err := binary.Write(w, binary.BigEndian, uint32(len(proof.ClaimedValues))); err != nil {
+		return enc.BytesWritten(), err
+	}
+	for i := range proof.ClaimedValues {
+		if err := enc.Encode(proof.ClaimedValues[i]); err != nil {
+			return enc.BytesWritten() + 4, err
+		}
+	}
+
+	return enc.BytesWritten() + 4, nil
+}
+
+// ReadFrom decodes BatchOpeningProof data from reader, compressed or not.
+func (proof *BatchOpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12378.NewDecoder(r)
+
+	toDecode := []interface{}{
+		&proof.H,
+		&proof.ClaimedDigests,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	var nbPolynomials uint32
+	if err := binary.Read(r, binary.BigEndian, &nbPolynomials); err != nil {
+		return dec.BytesRead(), err
+	}
+	// the claimed values are either omitted or given for each claimed digest
+	if nbPolynomials != 0 && int64(nbPolynomials) != int64(len(proof.ClaimedDigests)) {
+		return dec.BytesRead() + 4, ErrInvalidProofEncoding
+	}
+	proof.ClaimedValues = nil
+	if nbPolynomials != 0 {
+		proof.ClaimedValues = make([][]fr.Element, nbPolynomials)
+	}
+	for i := range proof.ClaimedValues {
+		if err := dec.Decode(&proof.ClaimedValues[i]); err != nil {
+			return dec.BytesRead() + 4, err
+		}
+	}
+
+	return dec.BytesRead() + 4, nil
+}
+
+// WriteTo writes binary encoding of a BivariateOpeningProof, with compressed points
+func (proof *BivariateOpeningProof) WriteTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w)
+}
+
+// WriteRawTo writes binary encoding of a BivariateOpeningProof, with uncompressed points
+func (proof *BivariateOpeningProof) WriteRawTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w, bls12378.RawEncoding())
+}
+
+func (proof *BivariateOpeningProof) writeTo(w io.Writer, encOptions ...func(*bls12378.Encoder)) (int64, error) {
+	enc := bls12378.NewEncoder(w, encOptions...)
+
+	toEncode := []interface{}{
+		&proof.HY,
+		&proof.ClaimedDigest,
+		&proof.HX,
+		&proof.ClaimedValue,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom decodes BivariateOpeningProof data from reader, compressed or not.
+func (proof *BivariateOpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12378.NewDecoder(r)
+
+	toDecode := []interface{}{
+		&proof.HY,
+		&proof.ClaimedDigest,
+		&proof.HX,
+		&proof.ClaimedValue,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// WriteTo writes binary encoding of a MultiPointsOpeningProof, with compressed points
+func (proof *MultiPointsOpeningProof) WriteTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w)
+}
+
+// WriteRawTo writes binary encoding of a MultiPointsOpeningProof, with uncompressed points
+func (proof *MultiPointsOpeningProof) WriteRawTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w, bls12378.RawEncoding())
+}
+
+// writeTo encodes W, WPrime, then the number of polynomials as a big endian uint32, followed by the
+// claimed digests of each polynomial. The claimed values are then encoded the same way,
+// the number of points of each polynomial preceding the values at each point.
+func (proof *MultiPointsOpeningProof) writeTo(w io.Writer, encOptions ...func(*bls12378.Encoder)) (int64, error) {
+	enc := bls12378.NewEncoder(w, encOptions...)
+	var n int64
+
+	toEncode := []interface{}{
+		&proof.W,
+		&proof.WPrime,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(proof.ClaimedDigests))); err != nil {
+		return enc.BytesWritten() + n, err
+	}
+	n += 4
+	for k := range proof.ClaimedDigests {
+		if err := enc.Encode(proof.ClaimedDigests[k]); err != nil {
+			return enc.BytesWritten() + n, err
+		}
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint32(len(proof.ClaimedValues))); err != nil {
+		return enc.BytesWritten() + n, err
+	}
+	n += 4
+	for k := range proof.ClaimedValues {
+		if err := binary.Write(w, binary.BigEndian, uint32(len(proof.ClaimedValues[k]))); err != nil {
+			return enc.BytesWritten() + n, err
+		}
+		n += 4
+		for j := range proof.ClaimedValues[k] {
+			if err := enc.Encode(proof.ClaimedValues[k][j]); err != nil {
+				return enc.BytesWritten() + n, err
+			}
+		}
+	}
+
+	return enc.BytesWritten() + n, nil
+}
+
+// ReadFrom decodes MultiPointsOpeningProof data from reader, compressed or not.
+func (proof *MultiPointsOpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := bls12378.NewDecoder(r)
+	var n int64
+
+	toDecode := []interface{}{
+		&proof.W,
+		&proof.WPrime,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return dec.BytesRead() + n, err
+	}
+	n += 4
+	if length > maxDecodedLength {
+		return dec.BytesRead() + n, ErrInvalidProofEncoding
+	}
+	proof.ClaimedDigests = nil
+	if length != 0 {
+		proof.ClaimedDigests = make([][]bls12378.G1Affine, length)
+	}
+	for k := range proof.ClaimedDigests {
+		if err := dec.Decode(&proof.ClaimedDigests[k]); err != nil {
+			return dec.BytesRead() + n, err
+		}
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return dec.BytesRead() + n, err
+	}
+	n += 4
+	// the claimed values are either omitted or given for each claimed digest
+	if length != 0 && int64(length) != int64(len(proof.ClaimedDigests)) {
+		return dec.BytesRead() + n, ErrInvalidProofEncoding
+	}
+	proof.ClaimedValues = nil
+	if length != 0 {
+		proof.ClaimedValues = make([][][]fr.Element, length)
+	}
+	for k := range proof.ClaimedValues {
+		if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+			return dec.BytesRead() + n, err
+		}
+		n += 4
+		if int64(length) != int64(len(proof.ClaimedDigests[k])) {
+			return dec.BytesRead() + n, ErrInvalidProofEncoding
+		}
+		if length != 0 {
+			proof.ClaimedValues[k] = make([][]fr.Element, length)
+		}
+		for j := range proof.ClaimedValues[k] {
+			if err := dec.Decode(&proof.ClaimedValues[k][j]); err != nil {
+				return dec.BytesRead() + n, err
+			}
+		}
+	}
+
+	return dec.BytesRead() + n, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package kzg
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-378"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr/fft"
+	"github.com/consensys/gnark-crypto/internal/parallel"
+)
+
+/*
+OpenAll computes the opening proofs of the polynomial p, in canonical form, at all the points ωⁱ of
+domain, in natural order, with the method of Feist and Khovratovich (FK20, https://eprint.iacr.org/2023/033).
+
+With d = len(p) - 1, the quotient of p by (X - z) is ∑_{i<d}(∑_{j>i}pⱼzʲ⁻ⁱ⁻¹)Xⁱ, so that its commitment is
+
+	∑_{k<d}zᵏHₖ, Hₖ = ∑_{i<d-k}pᵢ₊ₖ₊₁[αⁱ]G₁
+
+The Hₖ are a Toeplitz matrix-vector product, computed as a convolution with FFTs of size 2n, and the
+proofs at the ωⁱ are the DFT of the Hₖ, that is O(n log n) group operations instead of n multi exponentiations.
+*/
+func OpenAll(p []fr.Element, domain *fft.Domain, srs *SRS) ([]OpeningProof, error) {
+	n := int(domain.Cardinality)
+	if len(p) == 0 || len(p) > n || len(p) > len(srs.G1) {
+		return nil, ErrInvalidPolynomialSize
+	}
+
+	// the claimed values
+	values := make([]fr.Element, n)
+	copy(values, p)
+	domain.FFT(values, fft.DIF)
+	fft.BitReverse(values)
+
+	// the commitments of the quotients
+	h := toeplitzCommitments(p, srs)
+	quotients := g1Infinity(n)
+	copy(quotients, h)
+	domain.FFTG1(quotients, fft.DIF)
+	fft.BitReverseG1(quotients)
+	proofs := bls12378.BatchJacobianToAffineG1(quotients)
+
+	res := make([]OpeningProof, n)
+	for i := range res {
+		res[i].H = proofs[i]
+		res[i].ClaimedValue = values[i]
+	}
+	return res, nil
+}
+
+/*
+toeplitzCommitments returns the Hₖ = ∑_{i<d-k}pᵢ₊ₖ₊₁[αⁱ]G₁, k < d = len(p) - 1, see OpenAll.
+
+Hₖ is the coefficient d - 1 + k of the product of a = (p₁, .., p_d) and s = ([α^{d-1}]G₁, .., G₁),
+computed in the evaluation form on a domain of size 2^⌈log(2d)⌉.
+*/
+func toeplitzCommitments(p []fr.Element, srs *SRS) []bls12378.G1Jac {
+	d := len(p) - 1
+	if d == 0 {
+		return nil
+	}
+	bigDomain := fft.NewDomain(uint64(2 * d))
+	m := int(bigDomain.Cardinality)
+
+	a := make([]fr.Element, m)
+	copy(a, p[1:])
+	bigDomain.FFT(a, fft.DIF)
+
+	// both in bit reversed order
+	s := g1Infinity(m)
+	for i := 0; i < d; i++ {
+		s[i].FromAffine(&srs.G1[d-1-i])
+	}
+	bigDomain.FFTG1(s, fft.DIF)
+
+	parallel.Execute(m, func(start, end int) {
+		var b big.Int
+		for i := start; i < end; i++ {
+			a[i].ToBigIntRegular(&b)
+			s[i].ScalarMultiplication(&s[i], &b)
+		}
+	})
+	bigDomain.FFTInverseG1(s, fft.DIT)
+
+	return s[d-1 : 2*d-1]
+}
+
+// g1Infinity returns n points at infinity
+func g1Infinity(n int) []bls12378.G1Jac {
+	res := make([]bls12378.G1Jac, n)
+	for i := range res {
+		res[i].X.SetOne()
+		res[i].Y.SetOne()
+	}
+	return res
+}
@@ -43,6 +43,11 @@ type Digest = bls12378.G1Affine
 type SRS struct {
 	G1 []bls12378.G1Affine  // [G₁ [α]G₁ , [α²]G₁, ... ]
 	G2 [2]bls12378.G2Affine // [G₂, [α]G₂ ]
+	H  [2]bls12378.G1Affine // [[h]G₁, [αh]G₁ ] hiding generators, zero if the SRS is not hiding, see NewHidingSRS
+
+	// Lagrange[i] = [Lᵢ(α)]G₁ for the points ωⁱ of the fft.Domain of size len(Lagrange),
+	// nil if the SRS has no Lagrange basis, see ToLagrangeBasis
+	Lagrange []bls12378.G1Affine
 }
 
 // eval returns p(point) where p is interpreted as a polynomial
@@ -0,0 +1,351 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package kzg
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr"
+	"github.com/consensys/gnark-crypto/fiat-shamir"
+)
+
+var (
+	ErrInvalidPointSet               = errors.New("the sets of opening points must be non empty, without duplicates")
+	ErrVerifyBatchOpeningMultiPoints = errors.New("can't verify batch opening proof at multiple points")
+)
+
+// MultiPointsOpeningProof opening proof of many polynomials, each of them at a set of points,
+// see BatchOpenMultiPoints.
+//
+// implements io.ReaderFrom and io.WriterTo
+type MultiPointsOpeningProof struct {
+	// W commitment of h = ∑ₖγᵏ(fₖ - rₖ) / Z_{Sₖ}
+	W bls12378.G1Affine
+
+	// WPrime commitment of L / (X - z), see BatchOpenMultiPoints
+	WPrime bls12378.G1Affine
+
+	// ClaimedValues purported values, ClaimedValues[k][j] is the value of the k-th polynomial at points[k][j]
+	ClaimedValues [][]fr.Element
+}
+
+/*
+BatchOpenMultiPoints opens the polynomials fₖ, fₖ being opened at the points of the set Sₖ = points[k].
+It is the scheme of Boneh, Drake, Fisch and Gabizon (Shplonk, https://eprint.iacr.org/2020/081),
+the proof being made of two G₁ points whatever the number of polynomials and points:
+
+ 1. γ is derived from the points, the digests and the claimed values.
+    rₖ denotes the polynomial of degree < |Sₖ| interpolating fₖ on Sₖ.
+ 2. W commits to h = ∑ₖγᵏ(fₖ - rₖ) / Z_{Sₖ}, where Z_{Sₖ} = ∏_{s ∈ Sₖ}(X - s).
+ 3. z is derived from W. With T = ∪ₖSₖ,
+    L = ∑ₖγᵏZ_{T\Sₖ}(z)(fₖ - rₖ(z)) - Z_T(z)h vanishes at z, and W' commits to L / (X - z).
+
+* polynomials the polynomials, in canonical form
+* digests the digests of the fₖ, needed to derive the challenges
+* points the sets of points at which the polynomials are opened
+*/
+func BatchOpenMultiPoints(polynomials [][]fr.Element, digests []Digest, points [][]fr.Element, hf hash.Hash, srs *SRS) (MultiPointsOpeningProof, error) {
+	nbDigests := len(digests)
+	if nbDigests == 0 || nbDigests != len(polynomials) || nbDigests != len(points) {
+		return MultiPointsOpeningProof{}, ErrInvalidNbDigests
+	}
+	largestPoly := 0
+	for k := range polynomials {
+		if len(polynomials[k]) == 0 || len(polynomials[k]) > len(srs.G1) {
+			return MultiPointsOpeningProof{}, ErrInvalidPolynomialSize
+		}
+		if len(polynomials[k]) > largestPoly {
+			largestPoly = len(polynomials[k])
+		}
+	}
+	t, err := pointsUnion(points)
+	if err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// 1. claimed values, and γ
+	var proof MultiPointsOpeningProof
+	proof.ClaimedValues = make([][]fr.Element, len(points))
+	for k := range points {
+		proof.ClaimedValues[k] = make([]fr.Element, len(points[k]))
+		for j := range points[k] {
+			proof.ClaimedValues[k][j] = eval(polynomials[k], points[k][j])
+		}
+	}
+	fs := fiatshamir.NewTranscript(hf, "gamma", "z")
+	gamma, err := deriveMultiPointsGamma(&fs, digests, points, proof.ClaimedValues)
+	if err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// 2. h = ∑ₖγᵏ fₖ / Z_{Sₖ}, quotients only
+	h := make([]fr.Element, largestPoly)
+	var gammak, tmp fr.Element
+	gammak.SetOne()
+	for k := range polynomials {
+		q := make([]fr.Element, len(polynomials[k]))
+		copy(q, polynomials[k])
+		for _, s := range points[k] {
+			if len(q) <= 1 {
+				// deg(fₖ) < |Sₖ|, the quotient is 0
+				q = nil
+				break
+			}
+			q = dividePolyByXminusA(q, eval(q, s), s)
+		}
+		for j := range q {
+			tmp.Mul(&q[j], &gammak)
+			h[j].Add(&h[j], &tmp)
+		}
+		gammak.Mul(&gammak, &gamma)
+	}
+	if proof.W, err = Commit(h, srs); err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// 3. z, and L = ∑ₖγᵏZ_{T\Sₖ}(z)(fₖ - rₖ(z)) - Z_T(z)h
+	z, err := deriveMultiPointsZ(&fs, &proof.W)
+	if err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+	zT, zTMinusS := vanishingAt(z, t, points)
+	l := make([]fr.Element, largestPoly+1)
+	gammak.SetOne()
+	for k := range polynomials {
+		var factor, rz fr.Element
+		factor.Mul(&gammak, &zTMinusS[k])
+		for j := range polynomials[k] {
+			tmp.Mul(&polynomials[k][j], &factor)
+			l[j].Add(&l[j], &tmp)
+		}
+		rz = interpolateAt(z, points[k], proof.ClaimedValues[k])
+		tmp.Mul(&rz, &factor)
+		l[0].Sub(&l[0], &tmp)
+		gammak.Mul(&gammak, &gamma)
+	}
+	for j := range h {
+		tmp.Mul(&h[j], &zT)
+		l[j].Sub(&l[j], &tmp)
+	}
+
+	// L(z) = 0
+	var zero fr.Element
+	if proof.WPrime, err = Commit(dividePolyByXminusA(l, zero, z), srs); err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	return proof, nil
+}
+
+/*
+BatchVerifyMultiPointsOpening verifies a proof computed by BatchOpenMultiPoints, with one pairing check.
+
+F = ∑ₖγᵏZ_{T\Sₖ}(z)(digests[k] - [rₖ(z)]G₁) - Z_T(z)W is a commitment to L, and we check
+e(F + zW', G₂) = e(W', [α]G₂).
+*/
+func BatchVerifyMultiPointsOpening(digests []Digest, proof *MultiPointsOpeningProof, points [][]fr.Element, hf hash.Hash, srs *SRS) error {
+	nbDigests := len(digests)
+	if nbDigests == 0 || nbDigests != len(points) || nbDigests != len(proof.ClaimedValues) {
+		return ErrInvalidNbDigests
+	}
+	for k := range points {
+		if len(points[k]) != len(proof.ClaimedValues[k]) {
+			return ErrInvalidNbDigests
+		}
+	}
+	t, err := pointsUnion(points)
+	if err != nil {
+		return err
+	}
+
+	fs := fiatshamir.NewTranscript(hf, "gamma", "z")
+	gamma, err := deriveMultiPointsGamma(&fs, digests, points, proof.ClaimedValues)
+	if err != nil {
+		return err
+	}
+	z, err := deriveMultiPointsZ(&fs, &proof.W)
+	if err != nil {
+		return err
+	}
+	zT, zTMinusS := vanishingAt(z, t, points)
+
+	// F + zW' as a single multi exponentiation
+	bases := make([]bls12378.G1Affine, 0, nbDigests+3)
+	scalars := make([]fr.Element, 0, cap(bases))
+	var gammak, factor, rz, tmp, sumR fr.Element
+	gammak.SetOne()
+	for k := 0; k < nbDigests; k++ {
+		factor.Mul(&gammak, &zTMinusS[k])
+		bases = append(bases, digests[k])
+		scalars = append(scalars, factor)
+
+		rz = interpolateAt(z, points[k], proof.ClaimedValues[k])
+		tmp.Mul(&factor, &rz)
+		sumR.Add(&sumR, &tmp)
+		gammak.Mul(&gammak, &gamma)
+	}
+	sumR.Neg(&sumR)
+	zT.Neg(&zT)
+	bases = append(bases, srs.G1[0], proof.W, proof.WPrime)
+	scalars = append(scalars, sumR, zT, z)
+
+	var f bls12378.G1Affine
+	if _, err := f.MultiExp(bases, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+
+	// e(F + zW', G₂).e(-W', [α]G₂) ==? 1
+	var negWPrime bls12378.G1Affine
+	negWPrime.Neg(&proof.WPrime)
+	check, err := bls12378.PairingCheck(
+		[]bls12378.G1Affine{f, negWPrime},
+		[]bls12378.G2Affine{srs.G2[0], srs.G2[1]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrVerifyBatchOpeningMultiPoints
+	}
+	return nil
+}
+
+// deriveMultiPointsGamma derives γ, binded to the points, the digests and the claimed values
+func deriveMultiPointsGamma(fs *fiatshamir.Transcript, digests []Digest, points [][]fr.Element, claimedValues [][]fr.Element) (fr.Element, error) {
+	for k := range points {
+		for j := range points[k] {
+			if err := fs.Bind("gamma", points[k][j].Marshal()); err != nil {
+				return fr.Element{}, err
+			}
+		}
+	}
+	for k := range digests {
+		if err := fs.Bind("gamma", digests[k].Marshal()); err != nil {
+			return fr.Element{}, err
+		}
+	}
+	for k := range claimedValues {
+		for j := range claimedValues[k] {
+			if err := fs.Bind("gamma", claimedValues[k][j].Marshal()); err != nil {
+				return fr.Element{}, err
+			}
+		}
+	}
+	gammaByte, err := fs.ComputeChallenge("gamma")
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var gamma fr.Element
+	gamma.SetBytes(gammaByte)
+	return gamma, nil
+}
+
+// deriveMultiPointsZ derives z, binded to γ and W
+func deriveMultiPointsZ(fs *fiatshamir.Transcript, w *bls12378.G1Affine) (fr.Element, error) {
+	if err := fs.Bind("z", w.Marshal()); err != nil {
+		return fr.Element{}, err
+	}
+	zByte, err := fs.ComputeChallenge("z")
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var z fr.Element
+	z.SetBytes(zByte)
+	return z, nil
+}
+
+// pointsUnion returns T = ∪ₖSₖ, and checks that the sets are non empty, without duplicates
+func pointsUnion(points [][]fr.Element) ([]fr.Element, error) {
+	var t []fr.Element
+	for k := range points {
+		if len(points[k]) == 0 {
+			return nil, ErrInvalidPointSet
+		}
+		for j := range points[k] {
+			for l := 0; l < j; l++ {
+				if points[k][l].Equal(&points[k][j]) {
+					return nil, ErrInvalidPointSet
+				}
+			}
+			if !contains(t, &points[k][j]) {
+				t = append(t, points[k][j])
+			}
+		}
+	}
+	return t, nil
+}
+
+func contains(set []fr.Element, e *fr.Element) bool {
+	for i := range set {
+		if set[i].Equal(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// vanishingAt returns Z_T(z) and the Z_{T\Sₖ}(z)
+func vanishingAt(z fr.Element, t []fr.Element, points [][]fr.Element) (fr.Element, []fr.Element) {
+	var zT, tmp fr.Element
+	zT.SetOne()
+	for i := range t {
+		tmp.Sub(&z, &t[i])
+		zT.Mul(&zT, &tmp)
+	}
+	zTMinusS := make([]fr.Element, len(points))
+	for k := range points {
+		zTMinusS[k].SetOne()
+		for i := range t {
+			if contains(points[k], &t[i]) {
+				continue
+			}
+			tmp.Sub(&z, &t[i])
+			zTMinusS[k].Mul(&zTMinusS[k], &tmp)
+		}
+	}
+	return zT, zTMinusS
+}
+
+// interpolateAt returns r(z), r being the polynomial of degree < len(s) such that r(s[j]) = values[j]
+func interpolateAt(z fr.Element, s, values []fr.Element) fr.Element {
+	num := make([]fr.Element, len(s))
+	den := make([]fr.Element, len(s))
+	var tmp fr.Element
+	for j := range s {
+		num[j].SetOne()
+		den[j].SetOne()
+		for l := range s {
+			if l == j {
+				continue
+			}
+			tmp.Sub(&z, &s[l])
+			num[j].Mul(&num[j], &tmp)
+			tmp.Sub(&s[j], &s[l])
+			den[j].Mul(&den[j], &tmp)
+		}
+	}
+	den = fr.BatchInvert(den)
+	var res fr.Element
+	for j := range s {
+		tmp.Mul(&num[j], &den[j]).Mul(&tmp, &values[j])
+		res.Add(&res, &tmp)
+	}
+	return res
+}
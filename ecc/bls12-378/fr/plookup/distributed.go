@@ -0,0 +1,654 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package plookup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"math/bits"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr/dkzg"
+	"github.com/consensys/gnark-crypto/ecc/bls12-378/fr/fft"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+)
+
+var (
+	ErrEmptyVector = errors.New("f and t should not be empty")
+	ErrNbProducts  = errors.New("there should be one product per party")
+)
+
+/*
+ProofDistributedLookupVector plookup proof of a vector f distributed among the parties, the party i
+holding the slice fᵢ, against a table t shared by all the parties.
+
+The global vectors f, t, h₁, h₂ and z are the concatenations of the slices of the parties, each of size n,
+committed with dkzg. The party i holds the rows [i.n, (i+1).n) of the global plookup relation:
+
+  - the sorting of f by t is split across the parties: each party places its queries in t, and the
+    multiplicities of the table entries are summed over the parties, so that any party can build its
+    slices of h₁ and h₂, and the first values of the slices of the next party.
+  - the accumulation is split across the parties: zᵢ starts at 1, its last step uses the first values of
+    the slices of the next party, and gives the product Pᵢ of the party. The global accumulation ends
+    at 1 iff ∏ᵢPᵢ = 1.
+
+The transition constraint is checked on each slice with a quotient qᵢ = qloᵢ + Xⁿqhiᵢ, the boundary
+constraints with the openings of the bivariate polynomials at Y = 1 and Y = gⁿ⁻¹, whose claimed values
+are the values of the slices of the parties.
+*/
+type ProofDistributedLookupVector struct {
+
+	// size of the slices held by each party
+	size uint64
+
+	// generator of the fft domain, used for shifting the evaluation point
+	g fr.Element
+
+	// Commitments to h1, h2, t, z, f, and to the low and high parts of the quotient
+	h1, h2, t, z, f, qLo, qHi dkzg.Digest
+
+	// products[i] is the product of the accumulation of the party i
+	products []fr.Element
+
+	// Batch opening proof of h1, h2, t, z, f, qLo, qHi
+	BatchedProof dkzg.BatchOpeningProof
+
+	// Batch opening proof of h1, h2, t, z shifted by g
+	BatchedProofShifted dkzg.BatchOpeningProof
+
+	// Batch opening proof of h1, h2, t, z at 1, the first values of the slices
+	BatchedProofFirst dkzg.BatchOpeningProof
+
+	// Batch opening proof of h1, h2, t, z, f at gⁿ⁻¹, the last values of the slices
+	BatchedProofLast dkzg.BatchOpeningProof
+}
+
+// countQueries returns the multiplicities of the queries of all the parties in the sorted table lt.
+// The queries lf of the party are placed in lt with a binary search, the multiplicities are summed
+// over the parties with a single AllReduce. The queries that are not in the table are dropped,
+// the proof will not verify.
+func countQueries(lf []fr.Element, lt Table, c communicator.Communicator) ([]uint64, error) {
+
+	buf := make([]byte, 8*len(lt))
+	for i := range lf {
+		j := sort.Search(len(lt), func(j int) bool { return lt[j].Cmp(&lf[i]) >= 0 })
+		if j < len(lt) && lt[j].Equal(&lf[i]) {
+			binary.BigEndian.PutUint64(buf[8*j:], binary.BigEndian.Uint64(buf[8*j:])+1)
+		}
+	}
+
+	err := c.AllReduce(buf, func(acc, buf []byte) error {
+		for j := 0; j < len(acc); j += 8 {
+			binary.BigEndian.PutUint64(acc[j:], binary.BigEndian.Uint64(acc[j:])+binary.BigEndian.Uint64(buf[j:]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]uint64, len(lt))
+	for j := range counts {
+		counts[j] = binary.BigEndian.Uint64(buf[8*j:])
+	}
+	return counts, nil
+}
+
+// sortedSlice returns the slice [start, start+size) of f sorted by t, lt being the sorted table,
+// where lt[j] appears 1+counts[j] times. The positions beyond the end are filled with the last
+// value of lt.
+func sortedSlice(lt Table, counts []uint64, start, size int) []fr.Element {
+
+	res := make([]fr.Element, size)
+	k, end := 0, 0
+	for j := 0; j < len(lt) && k < size; j++ {
+		end += 1 + int(counts[j])
+		for ; k < size && start+k < end; k++ {
+			res[k] = lt[j]
+		}
+	}
+	for ; k < size; k++ {
+		res[k] = lt[len(lt)-1]
+	}
+	return res
+}
+
+// evaluateDistributedAccumulation computes zᵢ, in Lagrange basis, and the product of the accumulation of
+// the party. next contains the first values of the slices of t, h₁, h₂ of the next party, used by the
+// last step. The last party has no next party, its last row is the last row of the global relation,
+// so its accumulation stops one step before.
+func evaluateDistributedAccumulation(lf, lt, lh1, lh2 []fr.Element, next [3]fr.Element, last bool, beta, gamma fr.Element) ([]fr.Element, fr.Element) {
+
+	n := len(lt)
+	nbSteps := n
+	if last {
+		nbSteps--
+	}
+	lt = append(lt[:n:n], next[0])
+	lh1 = append(lh1[:n:n], next[1])
+	lh2 = append(lh2[:n:n], next[2])
+
+	d := make([]fr.Element, nbSteps)
+	var u, c fr.Element
+	c.SetOne().
+		Add(&c, &beta).
+		Mul(&c, &gamma)
+	for i := 0; i < nbSteps; i++ {
+
+		d[i].Mul(&beta, &lh1[i+1]).
+			Add(&d[i], &lh1[i]).
+			Add(&d[i], &c)
+
+		u.Mul(&beta, &lh2[i+1]).
+			Add(&u, &lh2[i]).
+			Add(&u, &c)
+
+		d[i].Mul(&d[i], &u)
+	}
+	d = fr.BatchInvert(d)
+
+	z := make([]fr.Element, n+1)
+	z[0].SetOne()
+	var a, b, e fr.Element
+	e.SetOne().Add(&e, &beta)
+	for i := 0; i < nbSteps; i++ {
+
+		a.Add(&gamma, &lf[i])
+
+		b.Mul(&beta, &lt[i+1]).
+			Add(&b, &lt[i]).
+			Add(&b, &c)
+
+		a.Mul(&a, &b).
+			Mul(&a, &e)
+
+		z[i+1].Mul(&z[i], &a).
+			Mul(&z[i+1], &d[i])
+	}
+
+	return z[:n], z[nbSteps]
+}
+
+// interpolate returns the canonical form of the polynomial whose values on domain are l, in natural order
+func interpolate(l []fr.Element, domain *fft.Domain) []fr.Element {
+	res := make([]fr.Element, domain.Cardinality)
+	copy(res, l)
+	domain.FFTInverse(res, fft.DIF)
+	fft.BitReverse(res)
+	return res
+}
+
+// bindProducts binds the products of the parties to challenge
+func bindProducts(fs *fiatshamir.Transcript, challenge string, products []fr.Element) error {
+	for i := range products {
+		b := products[i].Bytes()
+		if err := fs.Bind(challenge, b[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProveDistributedLookupVector returns proof that the values in the slices f of all the parties
+// are in t. f should be of the same size for all the parties, and t the same for all the parties.
+//
+// It's a collective call. The root node returns the proof, the other nodes return an empty proof.
+//
+// /!\IMPORTANT/!\
+//
+// As for ProveLookupVector, if the table t is already commited somewhere, the commitment needs
+// to be done on the table sorted, resized to the number of parties times the size of the slices
+// by repeating its last element, and split among the parties.
+func ProveDistributedLookupVector(srs *dkzg.SRS, f, t Table, c communicator.Communicator) (ProofDistributedLookupVector, error) {
+
+	// res
+	var proof ProofDistributedLookupVector
+	var err error
+
+	if len(f) == 0 || len(t) == 0 {
+		return proof, ErrEmptyVector
+	}
+
+	// hash function used for Fiat Shamir
+	hFunc := sha256.New()
+
+	// transcript to derive the challenge, all the parties derive the same challenges
+	fs := fiatshamir.NewTranscript(hFunc, "beta", "gamma", "nu")
+
+	// create domains, the table is split among the parties
+	worldSize, rank := c.Size(), c.Rank()
+	last := rank == worldSize-1
+	sizeSlice := len(f) + 1
+	if s := (len(t) + worldSize - 1) / worldSize; s > sizeSlice {
+		sizeSlice = s
+	}
+	domainSmall := fft.NewDomain(uint64(sizeSlice))
+	n := int(domainSmall.Cardinality)
+	size := worldSize * n
+
+	// set the size
+	proof.size = domainSmall.Cardinality
+
+	// set the generator
+	proof.g.Set(&domainSmall.Generator)
+
+	// resize f and t, sort t
+	// note: the last element of the global f, on the last party, does not matter
+	lf := make([]fr.Element, n)
+	lt := make(Table, size)
+	copy(lt, t)
+	copy(lf, f)
+	for i := len(f); i < n; i++ {
+		lf[i] = f[len(f)-1]
+	}
+	for i := len(t); i < size; i++ {
+		lt[i] = t[len(t)-1]
+	}
+	sort.Sort(lt)
+	ct := interpolate(lt[rank*n:(rank+1)*n], domainSmall)
+	cf := interpolate(lf, domainSmall)
+	proof.t, err = dkzg.AllCommit(ct, srs, c)
+	if err != nil {
+		return proof, err
+	}
+	proof.f, err = dkzg.AllCommit(cf, srs, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// write f sorted by t
+	queries := lf
+	if last {
+		queries = lf[:n-1]
+	}
+	counts, err := countQueries(queries, lt, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// compute h1, h2, commit to them
+	lh1 := sortedSlice(lt, counts, rank*n, n)
+	lh2 := sortedSlice(lt, counts, size-1+rank*n, n)
+	ch1 := interpolate(lh1, domainSmall)
+	ch2 := interpolate(lh2, domainSmall)
+	proof.h1, err = dkzg.AllCommit(ch1, srs, c)
+	if err != nil {
+		return proof, err
+	}
+	proof.h2, err = dkzg.AllCommit(ch2, srs, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// derive beta, gamma
+	beta, err := deriveRandomness(&fs, "beta", &proof.t, &proof.f, &proof.h1, &proof.h2)
+	if err != nil {
+		return proof, err
+	}
+	gamma, err := deriveRandomness(&fs, "gamma")
+	if err != nil {
+		return proof, err
+	}
+
+	// Compute to Z, the first values of the slices of the next party are computed locally
+	var next [3]fr.Element
+	if !last {
+		next[0] = lt[(rank+1)*n]
+		next[1] = sortedSlice(lt, counts, (rank+1)*n, 1)[0]
+		next[2] = sortedSlice(lt, counts, size-1+(rank+1)*n, 1)[0]
+	}
+	lz, product := evaluateDistributedAccumulation(lf, lt[rank*n:(rank+1)*n], lh1, lh2, next, last, beta, gamma)
+	cz := interpolate(lz, domainSmall)
+	proof.z, err = dkzg.AllCommit(cz, srs, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// exchange the products of the parties
+	productBytes := product.Bytes()
+	bufs := make([][]byte, worldSize)
+	for i := range bufs {
+		bufs[i] = productBytes[:]
+	}
+	received, err := c.AllToAll(bufs)
+	if err != nil {
+		return proof, err
+	}
+	proof.products = make([]fr.Element, len(received))
+	for i := range received {
+		proof.products[i].SetBytes(received[i])
+	}
+
+	// prepare data for computing the quotient
+	// compute the numerator
+	domainBig := fft.NewDomain(uint64(2 * n))
+
+	_lz := make([]fr.Element, 2*n)
+	_lh1 := make([]fr.Element, 2*n)
+	_lh2 := make([]fr.Element, 2*n)
+	_lt := make([]fr.Element, 2*n)
+	_lf := make([]fr.Element, 2*n)
+	copy(_lz, cz)
+	copy(_lh1, ch1)
+	copy(_lh2, ch2)
+	copy(_lt, ct)
+	copy(_lf, cf)
+	domainBig.FFT(_lz, fft.DIF, true)
+	domainBig.FFT(_lh1, fft.DIF, true)
+	domainBig.FFT(_lh2, fft.DIF, true)
+	domainBig.FFT(_lt, fft.DIF, true)
+	domainBig.FFT(_lf, fft.DIF, true)
+
+	// compute h, the transition constraint, and divide it by xⁿ-1
+	lh := evaluateNumBitReversed(_lz, _lh1, _lh2, _lt, _lf, beta, gamma, domainBig)
+	xnMinusOne := evaluateXnMinusOneDomainBig(domainBig)
+	xnMinusOne[0].Inverse(&xnMinusOne[0])
+	xnMinusOne[1].Inverse(&xnMinusOne[1])
+	nn := uint64(64 - bits.TrailingZeros64(domainBig.Cardinality))
+	for i := 0; i < 2*n; i++ {
+		_i := int(bits.Reverse64(uint64(i)) >> nn)
+		lh[_i].Mul(&lh[_i], &xnMinusOne[i%2])
+	}
+	domainBig.FFTInverse(lh, fft.DIT, true)
+	cqLo, cqHi := lh[:n], lh[n:]
+	proof.qLo, err = dkzg.AllCommit(cqLo, srs, c)
+	if err != nil {
+		return proof, err
+	}
+	proof.qHi, err = dkzg.AllCommit(cqHi, srs, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// build the opening proofs
+	if err = bindProducts(&fs, "nu", proof.products); err != nil {
+		return proof, err
+	}
+	nu, err := deriveRandomness(&fs, "nu", &proof.z, &proof.qLo, &proof.qHi)
+	if err != nil {
+		return proof, err
+	}
+	proof.BatchedProof, _, err = dkzg.BatchOpenSinglePoint(
+		[][]fr.Element{
+			ch1,
+			ch2,
+			ct,
+			cz,
+			cf,
+			cqLo,
+			cqHi,
+		},
+		[]dkzg.Digest{
+			proof.h1,
+			proof.h2,
+			proof.t,
+			proof.z,
+			proof.f,
+			proof.qLo,
+			proof.qHi,
+		},
+		nu,
+		hFunc,
+		srs,
+		c,
+	)
+	if err != nil {
+		return proof, err
+	}
+
+	var one fr.Element
+	one.SetOne()
+	points := []fr.Element{nu, one}
+	points[0].Mul(&nu, &domainSmall.Generator)
+	for i, p := range []*dkzg.BatchOpeningProof{&proof.BatchedProofShifted, &proof.BatchedProofFirst} {
+		*p, _, err = dkzg.BatchOpenSinglePoint(
+			[][]fr.Element{
+				ch1,
+				ch2,
+				ct,
+				cz,
+			},
+			[]dkzg.Digest{
+				proof.h1,
+				proof.h2,
+				proof.t,
+				proof.z,
+			},
+			points[i],
+			hFunc,
+			srs,
+			c,
+		)
+		if err != nil {
+			return proof, err
+		}
+	}
+
+	proof.BatchedProofLast, _, err = dkzg.BatchOpenSinglePoint(
+		[][]fr.Element{
+			ch1,
+			ch2,
+			ct,
+			cz,
+			cf,
+		},
+		[]dkzg.Digest{
+			proof.h1,
+			proof.h2,
+			proof.t,
+			proof.z,
+			proof.f,
+		},
+		domainSmall.GeneratorInv,
+		hFunc,
+		srs,
+		c,
+	)
+	if err != nil {
+		return proof, err
+	}
+
+	if rank != 0 {
+		return ProofDistributedLookupVector{}, nil
+	}
+
+	return proof, nil
+}
+
+// verifyClaimedValues checks that the batch opening proof has nbDigests claimed values per party,
+// consistent with its claimed digests.
+func verifyClaimedValues(proof *dkzg.BatchOpeningProof, nbDigests int, srs *dkzg.SRS) error {
+	if len(proof.ClaimedValues) != nbDigests || len(proof.ClaimedDigests) != nbDigests {
+		return dkzg.ErrInvalidNbDigests
+	}
+	for k := range proof.ClaimedValues {
+		if err := dkzg.VerifyClaimedValues(&proof.ClaimedDigests[k], proof.ClaimedValues[k], srs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyDistributedLookupVector verifies that a ProofDistributedLookupVector proof is correct
+func VerifyDistributedLookupVector(srs *dkzg.SRS, proof ProofDistributedLookupVector) error {
+
+	// hash function that is used for Fiat Shamir
+	hFunc := sha256.New()
+
+	// transcript to derive the challenge
+	fs := fiatshamir.NewTranscript(hFunc, "beta", "gamma", "nu")
+
+	// derive the various challenges
+	beta, err := deriveRandomness(&fs, "beta", &proof.t, &proof.f, &proof.h1, &proof.h2)
+	if err != nil {
+		return err
+	}
+
+	gamma, err := deriveRandomness(&fs, "gamma")
+	if err != nil {
+		return err
+	}
+
+	if err = bindProducts(&fs, "nu", proof.products); err != nil {
+		return err
+	}
+	nu, err := deriveRandomness(&fs, "nu", &proof.z, &proof.qLo, &proof.qHi)
+	if err != nil {
+		return err
+	}
+
+	// check opening proofs
+	var shiftedNu, one fr.Element
+	one.SetOne()
+	shiftedNu.Mul(&nu, &proof.g)
+	openings := []struct {
+		proof   *dkzg.BatchOpeningProof
+		digests []dkzg.Digest
+		point   fr.Element
+	}{
+		{&proof.BatchedProof, []dkzg.Digest{proof.h1, proof.h2, proof.t, proof.z, proof.f, proof.qLo, proof.qHi}, nu},
+		{&proof.BatchedProofShifted, []dkzg.Digest{proof.h1, proof.h2, proof.t, proof.z}, shiftedNu},
+		{&proof.BatchedProofFirst, []dkzg.Digest{proof.h1, proof.h2, proof.t, proof.z}, one},
+		{&proof.BatchedProofLast, []dkzg.Digest{proof.h1, proof.h2, proof.t, proof.z, proof.f}, fr.Element{}},
+	}
+	openings[3].point.Inverse(&proof.g)
+	for _, o := range openings {
+		if err := verifyClaimedValues(o.proof, len(o.digests), srs); err != nil {
+			return err
+		}
+		if err := dkzg.BatchVerifySinglePoint(o.digests, o.proof, o.point, hFunc, srs); err != nil {
+			return err
+		}
+	}
+
+	// check the generator is correct
+	var checkOrder fr.Element
+	checkOrder.Exp(proof.g, big.NewInt(int64(proof.size/2)))
+	if checkOrder.Equal(&one) {
+		return ErrGenerator
+	}
+	checkOrder.Square(&checkOrder)
+	if !checkOrder.Equal(&one) {
+		return ErrGenerator
+	}
+
+	// the accumulation of all the parties ends by one
+	worldSize := srs.WorldSize
+	if len(proof.products) != worldSize {
+		return ErrNbProducts
+	}
+	var product fr.Element
+	product.SetOne()
+	for i := range proof.products {
+		product.Mul(&product, &proof.products[i])
+	}
+	if !product.Equal(&one) {
+		return ErrPlookupVerification
+	}
+
+	// h₁ and h₂ overlap: the last value of the global h₁ is the first value of the global h₂
+	first, last := proof.BatchedProofFirst.ClaimedValues, proof.BatchedProofLast.ClaimedValues
+	if !last[0][worldSize-1].Equal(&first[1][0]) {
+		return ErrPlookupVerification
+	}
+
+	// check polynomial relations using Schwartz Zippel, for each party
+	values, shifted := proof.BatchedProof.ClaimedValues, proof.BatchedProofShifted.ClaimedValues
+	var lhs, rhs, nun, g, zeta, a, v, w fr.Element
+	g.Inverse(&proof.g) // gⁿ⁻¹
+	nun.Exp(nu, big.NewInt(int64(proof.size)))
+	zeta.Sub(&nun, &one)
+
+	v.Add(&one, &beta)
+	w.Mul(&v, &gamma)
+
+	for i := 0; i < worldSize; i++ {
+
+		// zᵢ starts by one
+		if !first[3][i].Equal(&one) {
+			return ErrPlookupVerification
+		}
+
+		// h(ν) where
+		// h = (x-gⁿ⁻¹)*z*(1+β)*(γ+f)*(γ(1+β) + t+ β*t(gX)) -
+		//		(x-gⁿ⁻¹)*z(gX)*(γ(1+β) + h₁ + β*h₁(gX))*(γ(1+β) + h₂ + β*h₂(gX) )
+		lhs.Mul(&values[3][i], &v)
+		a.Add(&gamma, &values[4][i])
+		lhs.Mul(&lhs, &a)
+		a.Mul(&beta, &shifted[2][i]).
+			Add(&a, &values[2][i]).
+			Add(&a, &w)
+		lhs.Mul(&lhs, &a)
+
+		rhs.Set(&shifted[3][i])
+		a.Mul(&beta, &shifted[0][i]).
+			Add(&a, &values[0][i]).
+			Add(&a, &w)
+		rhs.Mul(&rhs, &a)
+		a.Mul(&beta, &shifted[1][i]).
+			Add(&a, &values[1][i]).
+			Add(&a, &w)
+		rhs.Mul(&rhs, &a)
+
+		lhs.Sub(&lhs, &rhs)
+		a.Sub(&nu, &g) // (ν-gⁿ⁻¹)
+		lhs.Mul(&lhs, &a)
+
+		// (xⁿ-1) * (qlo(x) + xⁿ * qhi(x)) evaluated at ν
+		rhs.Mul(&values[6][i], &nun).
+			Add(&rhs, &values[5][i]).
+			Mul(&rhs, &zeta)
+		if !lhs.Equal(&rhs) {
+			return ErrPlookupVerification
+		}
+
+		// the last step of the accumulation, with the first values of the next party,
+		// gives the product of the party. The last row of the last party is not constrained.
+		if i == worldSize-1 {
+			if !last[3][i].Equal(&proof.products[i]) {
+				return ErrPlookupVerification
+			}
+			continue
+		}
+		lhs.Mul(&last[3][i], &v)
+		a.Add(&gamma, &last[4][i])
+		lhs.Mul(&lhs, &a)
+		a.Mul(&beta, &first[2][i+1]).
+			Add(&a, &last[2][i]).
+			Add(&a, &w)
+		lhs.Mul(&lhs, &a)
+
+		rhs.Set(&proof.products[i])
+		a.Mul(&beta, &first[0][i+1]).
+			Add(&a, &last[0][i]).
+			Add(&a, &w)
+		rhs.Mul(&rhs, &a)
+		a.Mul(&beta, &first[1][i+1]).
+			Add(&a, &last[1][i]).
+			Add(&a, &w)
+		rhs.Mul(&rhs, &a)
+		if !lhs.Equal(&rhs) {
+			return ErrPlookupVerification
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,371 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eip4844 implements the KZG commitments to the blobs of EIP-4844, as specified in the
+// polynomial commitments of the Deneb consensus specifications.
+//
+// The byte encodings are the ones of the specifications: field elements are 32 bytes big endian
+// and must be canonical, commitments and proofs are compressed G₁ points, as in bls12381.G1Affine.Bytes.
+// A blob holds the values of a polynomial on the roots of unity of order FieldElementsPerBlob,
+// in bit reversed order.
+package eip4844
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+)
+
+const (
+	FieldElementsPerBlob = 4096
+	BytesPerFieldElement = fr.Bytes
+	BytesPerBlob         = FieldElementsPerBlob * BytesPerFieldElement
+	BytesPerCommitment   = bls12381.SizeOfG1AffineCompressed
+	BytesPerProof        = bls12381.SizeOfG1AffineCompressed
+)
+
+// domain separators of the Fiat-Shamir challenges
+const (
+	fiatShamirProtocolDomain      = "FSBLOBVERIFY_V1_"
+	randomChallengeKZGBatchDomain = "RCKZGBATCH___V1_"
+)
+
+var (
+	ErrNonCanonicalFieldElement = errors.New("eip4844: non canonical field element")
+	ErrInvalidPoint             = errors.New("eip4844: invalid compressed G1 point")
+	ErrInvalidBatchSize         = errors.New("eip4844: the number of blobs, commitments and proofs differ")
+)
+
+// Blob FieldElementsPerBlob field elements, in bit reversed order
+type Blob [BytesPerBlob]byte
+
+// Commitment KZG commitment of a blob, a compressed G₁ point
+type Commitment [BytesPerCommitment]byte
+
+// Proof KZG opening proof, a compressed G₁ point
+type Proof [BytesPerProof]byte
+
+// Scalar big endian encoding of a field element
+type Scalar [BytesPerFieldElement]byte
+
+// BlobToKZGCommitment returns the commitment of the polynomial of the blob
+func (ctx *Context) BlobToKZGCommitment(blob *Blob) (Commitment, error) {
+	polynomial, err := blobToPolynomial(blob)
+	if err != nil {
+		return Commitment{}, err
+	}
+	digest, err := kzg.CommitLagrange(polynomial, &ctx.srs)
+	if err != nil {
+		return Commitment{}, err
+	}
+	return digest.Bytes(), nil
+}
+
+// ComputeKZGProof returns the opening proof of the polynomial of the blob at z, and its value y
+func (ctx *Context) ComputeKZGProof(blob *Blob, z Scalar) (Proof, Scalar, error) {
+	polynomial, err := blobToPolynomial(blob)
+	if err != nil {
+		return Proof{}, Scalar{}, err
+	}
+	_z, err := scalarToField(z)
+	if err != nil {
+		return Proof{}, Scalar{}, err
+	}
+	proof, err := ctx.computeKZGProof(polynomial, _z)
+	if err != nil {
+		return Proof{}, Scalar{}, err
+	}
+	return proof.H.Bytes(), proof.ClaimedValue.Bytes(), nil
+}
+
+// ComputeBlobKZGProof returns the opening proof of the polynomial of the blob at the Fiat-Shamir
+// challenge derived from the blob and its commitment, see VerifyBlobKZGProof.
+func (ctx *Context) ComputeBlobKZGProof(blob *Blob, commitment Commitment) (Proof, error) {
+	polynomial, err := blobToPolynomial(blob)
+	if err != nil {
+		return Proof{}, err
+	}
+	if _, err := bytesToPoint(commitment); err != nil {
+		return Proof{}, err
+	}
+	proof, err := ctx.computeKZGProof(polynomial, computeChallenge(blob, commitment))
+	if err != nil {
+		return Proof{}, err
+	}
+	return proof.H.Bytes(), nil
+}
+
+// VerifyKZGProof verifies that the polynomial committed in commitment takes the value y at z
+func (ctx *Context) VerifyKZGProof(commitment Commitment, z, y Scalar, proof Proof) error {
+	digest, err := bytesToPoint(commitment)
+	if err != nil {
+		return err
+	}
+	h, err := bytesToPoint(proof)
+	if err != nil {
+		return err
+	}
+	_z, err := scalarToField(z)
+	if err != nil {
+		return err
+	}
+	_y, err := scalarToField(y)
+	if err != nil {
+		return err
+	}
+	return kzg.Verify(&digest, &kzg.OpeningProof{H: h, ClaimedValue: _y}, _z, &ctx.srs)
+}
+
+// VerifyBlobKZGProof verifies a proof computed by ComputeBlobKZGProof
+func (ctx *Context) VerifyBlobKZGProof(blob *Blob, commitment Commitment, proof Proof) error {
+	polynomial, err := blobToPolynomial(blob)
+	if err != nil {
+		return err
+	}
+	digest, err := bytesToPoint(commitment)
+	if err != nil {
+		return err
+	}
+	h, err := bytesToPoint(proof)
+	if err != nil {
+		return err
+	}
+	z := computeChallenge(blob, commitment)
+	y := ctx.evaluate(polynomial, z)
+	return kzg.Verify(&digest, &kzg.OpeningProof{H: h, ClaimedValue: y}, z, &ctx.srs)
+}
+
+/*
+VerifyBlobKZGProofBatch verifies the proofs computed by ComputeBlobKZGProof, with a single pairing check.
+
+With r derived from all the commitments, challenges, values and proofs, and Cᵢ, zᵢ, yᵢ and πᵢ the
+commitments, challenges, values and proofs, we check
+
+	e(∑ᵢrⁱπᵢ, [τ]G₂) = e(∑ᵢrⁱ(Cᵢ - [yᵢ]G₁ + zᵢπᵢ), G₂)
+
+An empty batch is valid.
+*/
+func (ctx *Context) VerifyBlobKZGProofBatch(blobs []Blob, commitments []Commitment, proofs []Proof) error {
+	n := len(blobs)
+	if len(commitments) != n || len(proofs) != n {
+		return ErrInvalidBatchSize
+	}
+	if n == 0 {
+		return nil
+	}
+
+	digests := make([]bls12381.G1Affine, n)
+	hs := make([]bls12381.G1Affine, n)
+	zs := make([]fr.Element, n)
+	ys := make([]fr.Element, n)
+	for i := range blobs {
+		polynomial, err := blobToPolynomial(&blobs[i])
+		if err != nil {
+			return err
+		}
+		if digests[i], err = bytesToPoint(commitments[i]); err != nil {
+			return err
+		}
+		if hs[i], err = bytesToPoint(proofs[i]); err != nil {
+			return err
+		}
+		zs[i] = computeChallenge(&blobs[i], commitments[i])
+		ys[i] = ctx.evaluate(polynomial, zs[i])
+	}
+
+	// r = hash(domain | degree | n | (Cᵢ | zᵢ | yᵢ | πᵢ)ᵢ)
+	h := sha256.New()
+	h.Write([]byte(randomChallengeKZGBatchDomain))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], FieldElementsPerBlob)
+	h.Write(buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(n))
+	h.Write(buf[:])
+	for i := 0; i < n; i++ {
+		z, y := zs[i].Bytes(), ys[i].Bytes()
+		h.Write(commitments[i][:])
+		h.Write(z[:])
+		h.Write(y[:])
+		h.Write(proofs[i][:])
+	}
+	var r fr.Element
+	r.SetBytes(h.Sum(nil))
+
+	// ∑ᵢrⁱπᵢ, and ∑ᵢrⁱ(Cᵢ - [yᵢ]G₁ + zᵢπᵢ) as a single multi exponentiation
+	rPowers := make([]fr.Element, n)
+	rPowers[0].SetOne()
+	for i := 1; i < n; i++ {
+		rPowers[i].Mul(&rPowers[i-1], &r)
+	}
+	bases := make([]bls12381.G1Affine, 0, 2*n+1)
+	scalars := make([]fr.Element, 0, cap(bases))
+	var sumY, tmp fr.Element
+	for i := 0; i < n; i++ {
+		bases = append(bases, digests[i], hs[i])
+		scalars = append(scalars, rPowers[i], *tmp.Mul(&rPowers[i], &zs[i]))
+		tmp.Mul(&rPowers[i], &ys[i])
+		sumY.Add(&sumY, &tmp)
+	}
+	sumY.Neg(&sumY)
+	bases = append(bases, ctx.srs.G1[0])
+	scalars = append(scalars, sumY)
+
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	var left, proofLincomb bls12381.G1Affine
+	if _, err := left.MultiExp(bases, scalars, config); err != nil {
+		return err
+	}
+	if _, err := proofLincomb.MultiExp(hs, rPowers, config); err != nil {
+		return err
+	}
+
+	// e(∑ᵢrⁱ(Cᵢ - [yᵢ]G₁ + zᵢπᵢ), G₂).e(-∑ᵢrⁱπᵢ, [τ]G₂) ==? 1
+	proofLincomb.Neg(&proofLincomb)
+	check, err := bls12381.PairingCheck(
+		[]bls12381.G1Affine{left, proofLincomb},
+		[]bls12381.G2Affine{ctx.srs.G2[0], ctx.srs.G2[1]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return kzg.ErrVerifyOpeningProof
+	}
+	return nil
+}
+
+// computeKZGProof computes the opening proof at z of the polynomial in evaluation form, on the bit reversed roots
+// of unity. The quotient is computed in evaluation form:
+//
+//	qᵢ = (pᵢ - y) / (ωᵢ - z), and if z = ωₖ, qₖ = ∑_{i≠k}(pᵢ - y)ωᵢ / (z(z - ωᵢ))
+func (ctx *Context) computeKZGProof(polynomial []fr.Element, z fr.Element) (kzg.OpeningProof, error) {
+	y := ctx.evaluate(polynomial, z)
+
+	// ωᵢ - z, and the index of z in the domain, if any
+	k := -1
+	diffs := make([]fr.Element, len(polynomial))
+	for i := range diffs {
+		diffs[i].Sub(&ctx.rootsOfUnity[i], &z)
+		if diffs[i].IsZero() {
+			k = i
+			diffs[i].SetOne()
+		}
+	}
+	diffs = fr.BatchInvert(diffs)
+
+	quotient := make([]fr.Element, len(polynomial))
+	for i := range quotient {
+		quotient[i].Sub(&polynomial[i], &y).Mul(&quotient[i], &diffs[i])
+	}
+	if k >= 0 {
+		// (pᵢ - y)ωᵢ / (z(z - ωᵢ)) = -qᵢωᵢ/z
+		var acc, t, zInv fr.Element
+		for i := range quotient {
+			if i == k {
+				continue
+			}
+			t.Mul(&quotient[i], &ctx.rootsOfUnity[i])
+			acc.Add(&acc, &t)
+		}
+		zInv.Inverse(&z)
+		quotient[k].Mul(&acc, &zInv).Neg(&quotient[k])
+	}
+
+	h, err := kzg.CommitLagrange(quotient, &ctx.srs)
+	if err != nil {
+		return kzg.OpeningProof{}, err
+	}
+	return kzg.OpeningProof{H: h, ClaimedValue: y}, nil
+}
+
+// evaluate returns the value at z of the polynomial in evaluation form, with the barycentric formula
+//
+//	p(z) = (zⁿ - 1)/n ∑ᵢpᵢωᵢ/(z - ωᵢ)
+func (ctx *Context) evaluate(polynomial []fr.Element, z fr.Element) fr.Element {
+	diffs := make([]fr.Element, len(polynomial))
+	for i := range diffs {
+		if ctx.rootsOfUnity[i].Equal(&z) {
+			return polynomial[i]
+		}
+		diffs[i].Sub(&z, &ctx.rootsOfUnity[i])
+	}
+	diffs = fr.BatchInvert(diffs)
+
+	var res, t fr.Element
+	for i := range polynomial {
+		t.Mul(&polynomial[i], &ctx.rootsOfUnity[i]).Mul(&t, &diffs[i])
+		res.Add(&res, &t)
+	}
+	var nInv fr.Element
+	nInv.SetUint64(uint64(len(polynomial))).Inverse(&nInv)
+	t.Exp(z, big.NewInt(int64(len(polynomial))))
+	t.Sub(&t, new(fr.Element).SetOne()).Mul(&t, &nInv)
+	return *res.Mul(&res, &t)
+}
+
+// computeChallenge returns the Fiat-Shamir challenge hash(domain | degree | blob | commitment)
+func computeChallenge(blob *Blob, commitment Commitment) fr.Element {
+	h := sha256.New()
+	h.Write([]byte(fiatShamirProtocolDomain))
+	var degree [16]byte
+	binary.BigEndian.PutUint64(degree[8:], FieldElementsPerBlob)
+	h.Write(degree[:])
+	h.Write(blob[:])
+	h.Write(commitment[:])
+
+	var res fr.Element
+	res.SetBytes(h.Sum(nil))
+	return res
+}
+
+// blobToPolynomial returns the field elements of the blob, which must be canonical
+func blobToPolynomial(blob *Blob) ([]fr.Element, error) {
+	res := make([]fr.Element, FieldElementsPerBlob)
+	for i := range res {
+		var s Scalar
+		copy(s[:], blob[i*BytesPerFieldElement:])
+		var err error
+		if res[i], err = scalarToField(s); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// scalarToField returns the field element encoded in s, which must be canonical
+func scalarToField(s Scalar) (fr.Element, error) {
+	var b big.Int
+	b.SetBytes(s[:])
+	if b.Cmp(fr.Modulus()) >= 0 {
+		return fr.Element{}, ErrNonCanonicalFieldElement
+	}
+	var res fr.Element
+	res.SetBigInt(&b)
+	return res, nil
+}
+
+// bytesToPoint returns the compressed G₁ point b, checked to be in the correct subgroup
+func bytesToPoint(b [bls12381.SizeOfG1AffineCompressed]byte) (bls12381.G1Affine, error) {
+	var res bls12381.G1Affine
+	if _, err := res.SetBytes(b[:]); err != nil {
+		return bls12381.G1Affine{}, ErrInvalidPoint
+	}
+	return res, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eip4844
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+)
+
+// testTau secret of the insecure trusted setup used in the tests
+const testTau = 42
+
+var (
+	testSetupOnce sync.Once
+	testSetup     []byte   // insecure trusted setup, in the text format
+	testSRS       *kzg.SRS // the same setup in monomial form
+)
+
+// writeTestSetup returns the path of a trusted setup generated from testTau, in the text format
+func writeTestSetup(t *testing.T) string {
+	t.Helper()
+	testSetupOnce.Do(func() {
+		var err error
+		testSRS, err = kzg.NewSRS(FieldElementsPerBlob, big.NewInt(testTau))
+		if err != nil {
+			panic(err)
+		}
+		lagrange, err := kzg.NewSRS(FieldElementsPerBlob, big.NewInt(testTau))
+		if err != nil {
+			panic(err)
+		}
+		if err := lagrange.ToLagrangeBasis(fft.NewDomain(FieldElementsPerBlob)); err != nil {
+			panic(err)
+		}
+
+		const nbG2 = 65
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "%d\n%d\n", FieldElementsPerBlob, nbG2)
+		for i := range lagrange.Lagrange {
+			b := lagrange.Lagrange[i].Bytes()
+			fmt.Fprintln(&buf, hex.EncodeToString(b[:]))
+		}
+		powers := make([]fr.Element, nbG2)
+		var tau fr.Element
+		tau.SetUint64(testTau)
+		powers[0].SetOne()
+		for i := 1; i < nbG2; i++ {
+			powers[i].Mul(&powers[i-1], &tau)
+		}
+		for i := range powers {
+			powers[i].FromMont()
+		}
+		_, _, _, g2 := bls12381.Generators()
+		g2s := bls12381.BatchScalarMultiplicationG2(&g2, powers)
+		for i := range g2s {
+			b := g2s[i].Bytes()
+			fmt.Fprintln(&buf, hex.EncodeToString(b[:]))
+		}
+		testSetup = buf.Bytes()
+	})
+
+	path := filepath.Join(t.TempDir(), "trusted_setup.txt")
+	if err := os.WriteFile(path, testSetup, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testContext(t *testing.T) *Context {
+	t.Helper()
+	ctx, err := LoadTrustedSetup(writeTestSetup(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+// randomBlob returns a blob of random field elements, and the polynomial in canonical form
+func randomBlob(t *testing.T, ctx *Context) (*Blob, []fr.Element) {
+	t.Helper()
+	var blob Blob
+	values := make([]fr.Element, FieldElementsPerBlob)
+	for i := range values {
+		values[i].SetRandom()
+		b := values[i].Bytes()
+		copy(blob[i*BytesPerFieldElement:], b[:])
+	}
+
+	// the blob is in bit reversed order
+	coefficients := make([]fr.Element, FieldElementsPerBlob)
+	copy(coefficients, values)
+	fft.BitReverse(coefficients)
+	fft.NewDomain(FieldElementsPerBlob).FFTInverse(coefficients, fft.DIF)
+	fft.BitReverse(coefficients)
+	return &blob, coefficients
+}
+
+func TestRootsOfUnity(t *testing.T) {
+	ctx := newContext()
+	domain := fft.NewDomain(FieldElementsPerBlob)
+	if !ctx.rootsOfUnity[FieldElementsPerBlob/2].Equal(&domain.Generator) {
+		t.Fatal("the roots of unity should be the ones of the fft domain")
+	}
+}
+
+func TestReadTrustedSetup(t *testing.T) {
+	writeTestSetup(t)
+	if _, err := ReadTrustedSetup(bytes.NewReader(testSetup)); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, setup := range map[string]string{
+		"truncated": string(testSetup[:len(testSetup)/2]),
+		"size":      strings.Replace(string(testSetup), "4096", "2048", 1),
+		"point":     strings.Replace(string(testSetup), "\n", "\nzz", 3),
+	} {
+		if _, err := ReadTrustedSetup(strings.NewReader(setup)); err != ErrInvalidTrustedSetup {
+			t.Fatalf("%s: expected %v, got %v", name, ErrInvalidTrustedSetup, err)
+		}
+	}
+}
+
+func TestBlobToKZGCommitment(t *testing.T) {
+	ctx := testContext(t)
+
+	// the commitment of the zero blob is the point at infinity
+	commitment, err := ctx.BlobToKZGCommitment(&Blob{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Commitment{0xc0}
+	if commitment != expected {
+		t.Fatal("the commitment of the zero blob should be the point at infinity")
+	}
+
+	// same as the commitment of the polynomial in canonical form
+	blob, coefficients := randomBlob(t, ctx)
+	commitment, err = ctx.BlobToKZGCommitment(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := kzg.Commit(coefficients, testSRS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commitment != digest.Bytes() {
+		t.Fatal("inconsistent commitment")
+	}
+
+	// non canonical field element
+	copy(blob[BytesPerFieldElement:], fr.Modulus().Bytes())
+	if _, err := ctx.BlobToKZGCommitment(blob); err != ErrNonCanonicalFieldElement {
+		t.Fatal("non canonical field elements should be rejected")
+	}
+}
+
+func TestKZGProof(t *testing.T) {
+	ctx := testContext(t)
+	blob, coefficients := randomBlob(t, ctx)
+	commitment, err := ctx.BlobToKZGCommitment(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// outside and inside the domain
+	var outside fr.Element
+	outside.SetString("4321")
+	for _, z := range []fr.Element{outside, ctx.rootsOfUnity[3]} {
+		proof, y, err := ctx.ComputeKZGProof(blob, z.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// same as kzg.Open on the polynomial in canonical form
+		expected, err := kzg.Open(coefficients, z, testSRS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if proof != expected.H.Bytes() || y != expected.ClaimedValue.Bytes() {
+			t.Fatal("inconsistent proof")
+		}
+
+		if err := ctx.VerifyKZGProof(commitment, z.Bytes(), y, proof); err != nil {
+			t.Fatal(err)
+		}
+		var wrongY fr.Element
+		wrongY.SetOne().Add(&wrongY, &expected.ClaimedValue)
+		if err := ctx.VerifyKZGProof(commitment, z.Bytes(), wrongY.Bytes(), proof); err == nil {
+			t.Fatal("verifying a wrong value should have failed")
+		}
+	}
+
+	// invalid proof encoding
+	if err := ctx.VerifyKZGProof(commitment, Scalar{}, Scalar{}, Proof{0x42}); err != ErrInvalidPoint {
+		t.Fatal("invalid points should be rejected")
+	}
+}
+
+func TestBlobKZGProof(t *testing.T) {
+	ctx := testContext(t)
+
+	const nbBlobs = 3
+	blobs := make([]Blob, nbBlobs)
+	commitments := make([]Commitment, nbBlobs)
+	proofs := make([]Proof, nbBlobs)
+	for i := range blobs {
+		blob, _ := randomBlob(t, ctx)
+		blobs[i] = *blob
+		var err error
+		if commitments[i], err = ctx.BlobToKZGCommitment(blob); err != nil {
+			t.Fatal(err)
+		}
+		if proofs[i], err = ctx.ComputeBlobKZGProof(blob, commitments[i]); err != nil {
+			t.Fatal(err)
+		}
+		if err := ctx.VerifyBlobKZGProof(blob, commitments[i], proofs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ctx.VerifyBlobKZGProofBatch(blobs, commitments, proofs); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.VerifyBlobKZGProofBatch(nil, nil, nil); err != nil {
+		t.Fatal("an empty batch is valid")
+	}
+	if err := ctx.VerifyBlobKZGProofBatch(blobs, commitments[1:], proofs); err != ErrInvalidBatchSize {
+		t.Fatal("batches of different sizes should be rejected")
+	}
+
+	// wrong proofs
+	proofs[0], proofs[1] = proofs[1], proofs[0]
+	if err := ctx.VerifyBlobKZGProof(&blobs[0], commitments[0], proofs[0]); err == nil {
+		t.Fatal("verifying a wrong proof should have failed")
+	}
+	if err := ctx.VerifyBlobKZGProofBatch(blobs, commitments, proofs); err == nil {
+		t.Fatal("verifying a batch with a wrong proof should have failed")
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eip4844
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+)
+
+var (
+	ErrInvalidTrustedSetup = errors.New("eip4844: invalid trusted setup")
+)
+
+// primitiveRootOfUnity generator of fr* from which the roots of unity of the blob domain are derived
+const primitiveRootOfUnity = 7
+
+// Context holds the trusted setup, and the domain of the blobs, see LoadTrustedSetup
+type Context struct {
+	// srs.Lagrange[i] = [L_{ωᵢ}(τ)]G₁, ωᵢ = rootsOfUnity[i], that is the Lagrange points of the
+	// trusted setup in bit reversed order, srs.G1[0] = G₁, srs.G2 = [G₂, [τ]G₂]
+	srs kzg.SRS
+
+	// rootsOfUnity the roots of unity of order FieldElementsPerBlob, in bit reversed order
+	rootsOfUnity []fr.Element
+}
+
+// LoadTrustedSetup reads the trusted setup in the file at path, see ReadTrustedSetup
+func LoadTrustedSetup(path string) (*Context, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadTrustedSetup(f)
+}
+
+/*
+ReadTrustedSetup reads the trusted setup in the text format of the reference implementation
+(trusted_setup.txt):
+
+ 1. the number of G₁ points, FieldElementsPerBlob
+ 2. the number of G₂ points, at least 2
+ 3. the G₁ points [L_{ωⁱ}(τ)]G₁ in Lagrange form, in natural order, one compressed point in hexadecimal per line
+ 4. the G₂ points [τⁱ]G₂, one compressed point in hexadecimal per line
+
+Whitespaces separate the tokens, and the data after the G₂ points is ignored. The points are checked
+to be in the correct subgroup.
+*/
+func ReadTrustedSetup(r io.Reader) (*Context, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	next := func() (string, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", ErrInvalidTrustedSetup
+		}
+		return scanner.Text(), nil
+	}
+
+	var nbG1, nbG2 int
+	for _, n := range []*int{&nbG1, &nbG2} {
+		token, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fmt.Sscan(token, n); err != nil {
+			return nil, ErrInvalidTrustedSetup
+		}
+	}
+	if nbG1 != FieldElementsPerBlob || nbG2 < 2 {
+		return nil, ErrInvalidTrustedSetup
+	}
+
+	ctx := newContext()
+	ctx.srs.Lagrange = make([]bls12381.G1Affine, nbG1)
+	for i := range ctx.srs.Lagrange {
+		token, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if err := setHex(&ctx.srs.Lagrange[i], token); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < nbG2; i++ {
+		token, err := next()
+		if err != nil {
+			return nil, err
+		}
+		var p bls12381.G2Affine
+		if err := setHex(&p, token); err != nil {
+			return nil, err
+		}
+		if i < len(ctx.srs.G2) {
+			ctx.srs.G2[i] = p
+		}
+	}
+	_, _, _, g2 := bls12381.Generators()
+	if !ctx.srs.G2[0].Equal(&g2) {
+		return nil, ErrInvalidTrustedSetup
+	}
+
+	lagrange := make([]bls12381.G1Jac, nbG1)
+	for i := range lagrange {
+		lagrange[i].FromAffine(&ctx.srs.Lagrange[i])
+	}
+	fft.BitReverseG1(lagrange)
+	ctx.srs.Lagrange = bls12381.BatchJacobianToAffineG1(lagrange)
+	return ctx, nil
+}
+
+// newContext returns a Context without trusted setup
+func newContext() *Context {
+	var ctx Context
+
+	// ω = 7^((r-1)/FieldElementsPerBlob)
+	var e big.Int
+	e.Sub(fr.Modulus(), big.NewInt(1)).Div(&e, big.NewInt(FieldElementsPerBlob))
+	var omega fr.Element
+	omega.SetUint64(primitiveRootOfUnity).Exp(omega, &e)
+
+	ctx.rootsOfUnity = make([]fr.Element, FieldElementsPerBlob)
+	ctx.rootsOfUnity[0].SetOne()
+	for i := 1; i < len(ctx.rootsOfUnity); i++ {
+		ctx.rootsOfUnity[i].Mul(&ctx.rootsOfUnity[i-1], &omega)
+	}
+	fft.BitReverse(ctx.rootsOfUnity)
+
+	_, _, g1, _ := bls12381.Generators()
+	ctx.srs.G1 = []bls12381.G1Affine{g1}
+	return &ctx
+}
+
+// setHex sets p to the compressed point encoded in hexadecimal in s, with an optional 0x prefix
+func setHex(p interface{ SetBytes([]byte) (int, error) }, s string) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return ErrInvalidTrustedSetup
+	}
+	n, err := p.SetBytes(b)
+	if err != nil || n != len(b) {
+		return ErrInvalidTrustedSetup
+	}
+	return nil
+}
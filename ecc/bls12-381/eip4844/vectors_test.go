@@ -0,0 +1,304 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eip4844
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+The official test vectors are read from testdata:
+
+  - testdata/trusted_setup.txt, the trusted setup of the mainnet, in the text format of ReadTrustedSetup
+  - testdata/kzg, the content of the directory general/deneb/kzg of the consensus-spec-tests release,
+    testdata/kzg/<handler>/kzg-mainnet/<case>/data.yaml
+
+The tests fail if the files are missing.
+*/
+const (
+	testdataSetup = "testdata/trusted_setup.txt"
+	testdataKZG   = "testdata/kzg"
+)
+
+// vectorsContext returns the context of the official trusted setup
+func vectorsContext(t *testing.T) *Context {
+	t.Helper()
+	ctx, err := LoadTrustedSetup(testdataSetup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx
+}
+
+// runVectors runs f on each test case of handler in a new T, decode unmarshalling the test case
+func runVectors(t *testing.T, handler string, f func(t *testing.T, ctx *Context, decode func(tc interface{}))) {
+	ctx := vectorsContext(t)
+	files, err := filepath.Glob(filepath.Join(testdataKZG, handler, "*", "*", "data.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no test vectors for %s in %s", handler, testdataKZG)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(filepath.Dir(file)), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f(t, ctx, func(tc interface{}) {
+				if err := yaml.Unmarshal(data, tc); err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+}
+
+// decodeHex decodes the hexadecimal string s, with a 0x prefix, in dst. It returns false
+// if s is not valid, or not of the size of dst, the test case then expects an error.
+func decodeHex(dst []byte, s string) bool {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(b) != len(dst) {
+		return false
+	}
+	copy(dst, b)
+	return true
+}
+
+// checkVerification checks the result of a verification against the expected output:
+// nil for an invalid input, true or false for a valid one
+func checkVerification(t *testing.T, err error, output *bool) {
+	t.Helper()
+	switch {
+	case output == nil:
+		if err == nil || err == kzg.ErrVerifyOpeningProof {
+			t.Fatalf("the input should be rejected, got %v", err)
+		}
+	case *output:
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+		if err != kzg.ErrVerifyOpeningProof {
+			t.Fatalf("expected %v, got %v", kzg.ErrVerifyOpeningProof, err)
+		}
+	}
+}
+
+func TestVectorsBlobToKZGCommitment(t *testing.T) {
+	type testCase struct {
+		Input struct {
+			Blob string `yaml:"blob"`
+		} `yaml:"input"`
+		Output *string `yaml:"output"`
+	}
+	runVectors(t, "blob_to_kzg_commitment", func(t *testing.T, ctx *Context, decode func(tc interface{})) {
+		var tc testCase
+		decode(&tc)
+		var blob Blob
+		if !decodeHex(blob[:], tc.Input.Blob) {
+			if tc.Output != nil {
+				t.Fatal("invalid input with an expected output")
+			}
+			return
+		}
+		commitment, err := ctx.BlobToKZGCommitment(&blob)
+		if tc.Output == nil {
+			if err == nil {
+				t.Fatal("the input should be rejected")
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected Commitment
+		if !decodeHex(expected[:], *tc.Output) || commitment != expected {
+			t.Fatal("wrong commitment")
+		}
+	})
+}
+
+func TestVectorsComputeKZGProof(t *testing.T) {
+	type testCase struct {
+		Input struct {
+			Blob string `yaml:"blob"`
+			Z    string `yaml:"z"`
+		} `yaml:"input"`
+		Output *[]string `yaml:"output"`
+	}
+	runVectors(t, "compute_kzg_proof", func(t *testing.T, ctx *Context, decode func(tc interface{})) {
+		var tc testCase
+		decode(&tc)
+		var blob Blob
+		var z Scalar
+		if !decodeHex(blob[:], tc.Input.Blob) || !decodeHex(z[:], tc.Input.Z) {
+			if tc.Output != nil {
+				t.Fatal("invalid input with an expected output")
+			}
+			return
+		}
+		proof, y, err := ctx.ComputeKZGProof(&blob, z)
+		if tc.Output == nil {
+			if err == nil {
+				t.Fatal("the input should be rejected")
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expectedProof Proof
+		var expectedY Scalar
+		if len(*tc.Output) != 2 || !decodeHex(expectedProof[:], (*tc.Output)[0]) || !decodeHex(expectedY[:], (*tc.Output)[1]) {
+			t.Fatal("invalid expected output")
+		}
+		if proof != expectedProof || y != expectedY {
+			t.Fatal("wrong proof")
+		}
+	})
+}
+
+func TestVectorsComputeBlobKZGProof(t *testing.T) {
+	type testCase struct {
+		Input struct {
+			Blob       string `yaml:"blob"`
+			Commitment string `yaml:"commitment"`
+		} `yaml:"input"`
+		Output *string `yaml:"output"`
+	}
+	runVectors(t, "compute_blob_kzg_proof", func(t *testing.T, ctx *Context, decode func(tc interface{})) {
+		var tc testCase
+		decode(&tc)
+		var blob Blob
+		var commitment Commitment
+		if !decodeHex(blob[:], tc.Input.Blob) || !decodeHex(commitment[:], tc.Input.Commitment) {
+			if tc.Output != nil {
+				t.Fatal("invalid input with an expected output")
+			}
+			return
+		}
+		proof, err := ctx.ComputeBlobKZGProof(&blob, commitment)
+		if tc.Output == nil {
+			if err == nil {
+				t.Fatal("the input should be rejected")
+			}
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected Proof
+		if !decodeHex(expected[:], *tc.Output) || proof != expected {
+			t.Fatal("wrong proof")
+		}
+	})
+}
+
+func TestVectorsVerifyKZGProof(t *testing.T) {
+	type testCase struct {
+		Input struct {
+			Commitment string `yaml:"commitment"`
+			Z          string `yaml:"z"`
+			Y          string `yaml:"y"`
+			Proof      string `yaml:"proof"`
+		} `yaml:"input"`
+		Output *bool `yaml:"output"`
+	}
+	runVectors(t, "verify_kzg_proof", func(t *testing.T, ctx *Context, decode func(tc interface{})) {
+		var tc testCase
+		decode(&tc)
+		var commitment Commitment
+		var z, y Scalar
+		var proof Proof
+		if !decodeHex(commitment[:], tc.Input.Commitment) || !decodeHex(z[:], tc.Input.Z) ||
+			!decodeHex(y[:], tc.Input.Y) || !decodeHex(proof[:], tc.Input.Proof) {
+			if tc.Output != nil {
+				t.Fatal("invalid input with an expected output")
+			}
+			return
+		}
+		checkVerification(t, ctx.VerifyKZGProof(commitment, z, y, proof), tc.Output)
+	})
+}
+
+func TestVectorsVerifyBlobKZGProof(t *testing.T) {
+	type testCase struct {
+		Input struct {
+			Blob       string `yaml:"blob"`
+			Commitment string `yaml:"commitment"`
+			Proof      string `yaml:"proof"`
+		} `yaml:"input"`
+		Output *bool `yaml:"output"`
+	}
+	runVectors(t, "verify_blob_kzg_proof", func(t *testing.T, ctx *Context, decode func(tc interface{})) {
+		var tc testCase
+		decode(&tc)
+		var blob Blob
+		var commitment Commitment
+		var proof Proof
+		if !decodeHex(blob[:], tc.Input.Blob) || !decodeHex(commitment[:], tc.Input.Commitment) ||
+			!decodeHex(proof[:], tc.Input.Proof) {
+			if tc.Output != nil {
+				t.Fatal("invalid input with an expected output")
+			}
+			return
+		}
+		checkVerification(t, ctx.VerifyBlobKZGProof(&blob, commitment, proof), tc.Output)
+	})
+}
+
+func TestVectorsVerifyBlobKZGProofBatch(t *testing.T) {
+	type testCase struct {
+		Input struct {
+			Blobs       []string `yaml:"blobs"`
+			Commitments []string `yaml:"commitments"`
+			Proofs      []string `yaml:"proofs"`
+		} `yaml:"input"`
+		Output *bool `yaml:"output"`
+	}
+	runVectors(t, "verify_blob_kzg_proof_batch", func(t *testing.T, ctx *Context, decode func(tc interface{})) {
+		var tc testCase
+		decode(&tc)
+		blobs := make([]Blob, len(tc.Input.Blobs))
+		commitments := make([]Commitment, len(tc.Input.Commitments))
+		proofs := make([]Proof, len(tc.Input.Proofs))
+		valid := true
+		for i := range blobs {
+			valid = valid && decodeHex(blobs[i][:], tc.Input.Blobs[i])
+		}
+		for i := range commitments {
+			valid = valid && decodeHex(commitments[i][:], tc.Input.Commitments[i])
+		}
+		for i := range proofs {
+			valid = valid && decodeHex(proofs[i][:], tc.Input.Proofs[i])
+		}
+		if !valid {
+			if tc.Output != nil {
+				t.Fatal("invalid input with an expected output")
+			}
+			return
+		}
+		checkVerification(t, ctx.VerifyBlobKZGProofBatch(blobs, commitments, proofs), tc.Output)
+	})
+}
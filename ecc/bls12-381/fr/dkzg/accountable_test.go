@@ -0,0 +1,156 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+// cheater is a party tampering with its contributions to the verifiable commitments and openings
+type cheater struct {
+	communicator.Communicator
+	tamper func(msg *batchOpeningMessage)
+}
+
+func (c *cheater) Gather(buf []byte, root int) ([][]byte, error) {
+	var msg batchOpeningMessage
+	if err := msg.unmarshal(buf); err == nil {
+		c.tamper(&msg)
+		buf = msg.marshal()
+	}
+	return c.Communicator.Gather(buf, root)
+}
+
+// TestLagrangeShards checks that the [Lᵢ(τ₀)]G₁ of testSRS, used by the root node to check the
+// contributions, are the first points of the shards of the parties
+func TestLagrangeShards(t *testing.T) {
+	pt, err := NewPowersOfTau(nbParties, 1, []*big.Int{big.NewInt(42), big.NewInt(27)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lagrange, err := pt.Lagrange(NewPartyDomain(nbParties, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lagrange, testSRS[0].Lagrange) {
+		t.Fatal("lagrange points are not the ones of the SRS")
+	}
+	for i := 0; i < nbParties; i++ {
+		if !lagrange[i].Equal(&testSRS[i].G1[0]) {
+			t.Fatal("lagrange points are not the first points of the shards")
+		}
+	}
+}
+
+func TestVerifiableCommitOpen(t *testing.T) {
+	var y fr.Element
+	y.SetString("4321")
+
+	runParties(t, func(c communicator.Communicator) error {
+		f := polynomial(60, uint64(c.Rank()))
+
+		digest, partialDigests, err := VerifiableCommit(f, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		expectedDigest, err := Commit(f, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		proof, err := VerifiableOpen(f, y, testSRS[c.Rank()], partialDigests, c)
+		if err != nil {
+			return err
+		}
+		expectedProof, _, err := Open(f, y, testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		if c.Rank() != 0 {
+			return nil
+		}
+
+		if !digest.Equal(&expectedDigest) {
+			t.Error("verifiable commitment is not the commitment")
+		}
+		if !reflect.DeepEqual(proof, expectedProof) {
+			t.Error("verifiable opening proof is not the opening proof")
+		}
+		return Verify(&digest, &proof, y, testSRS[0])
+	})
+}
+
+func TestVerifiableCommitOpenCheaters(t *testing.T) {
+	var y fr.Element
+	y.SetString("4321")
+
+	// wrong value for the parties 1 and 3
+	wrongValue := func(msg *batchOpeningMessage) {
+		var one fr.Element
+		one.SetOne()
+		msg.ClaimedValues[0].Add(&msg.ClaimedValues[0], &one)
+	}
+	// point not on the curve for the party 2
+	invalidPoint := func(msg *batchOpeningMessage) {
+		msg.ClaimedDigests[0].Y.Double(&msg.ClaimedDigests[0].Y)
+	}
+	tampers := map[int]func(*batchOpeningMessage){1: wrongValue, 2: invalidPoint, 3: wrongValue}
+	expectedRanks := []int{1, 2, 3}
+
+	// partial digests of the honest parties, for the opening
+	partialDigests := make([]Digest, nbParties)
+	for i := range partialDigests {
+		var err error
+		if partialDigests[i], err = commitShare(polynomial(60, uint64(i)), testSRS[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, open := range []bool{false, true} {
+		runParties(t, func(c communicator.Communicator) error {
+			if tamper, ok := tampers[c.Rank()]; ok {
+				c = &cheater{Communicator: c, tamper: tamper}
+			}
+			f := polynomial(60, uint64(c.Rank()))
+
+			var err error
+			if open {
+				_, err = VerifiableOpen(f, y, testSRS[c.Rank()], partialDigests, c)
+			} else {
+				_, _, err = VerifiableCommit(f, testSRS[c.Rank()], c)
+			}
+			if c.Rank() != 0 {
+				return err
+			}
+
+			var cheatingErr *CheatingPartiesError
+			if !errors.As(err, &cheatingErr) {
+				t.Errorf("open=%v: expected a CheatingPartiesError, got %v", open, err)
+				return nil
+			}
+			if !reflect.DeepEqual(cheatingErr.Ranks, expectedRanks) {
+				t.Errorf("open=%v: expected cheaters %v, got %v", open, expectedRanks, cheatingErr.Ranks)
+			}
+			return nil
+		})
+	}
+}
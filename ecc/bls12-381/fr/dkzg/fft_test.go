@@ -0,0 +1,311 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+)
+
+// randomRows returns nbRows random rows of the given size
+func randomRows(nbRows, size int) [][]fr.Element {
+	rows := make([][]fr.Element, nbRows)
+	for i := range rows {
+		rows[i] = make([]fr.Element, size)
+		for j := range rows[i] {
+			rows[i][j].SetRandom()
+		}
+	}
+	return rows
+}
+
+// evalBivariate returns ∑ᵢfᵢ(y)Lᵢ(x), rows[i] being the coefficients of fᵢ
+func evalBivariate(rows [][]fr.Element, x, y fr.Element, domain *PartyDomain) fr.Element {
+	l := domain.LagrangeAt(x)
+	var res, t fr.Element
+	for i := range rows {
+		t = eval(rows[i], y)
+		t.Mul(&t, &l[i])
+		res.Add(&res, &t)
+	}
+	return res
+}
+
+func TestFFTYExtended(t *testing.T) {
+	const size, k = 16, 4
+	d := NewBivariateDomain(NewPartyDomain(nbParties, false), size)
+	extended := fft.NewDomain(k * size)
+
+	// evaluations of a polynomial of size N on the coset g.H_{kN}, in natural order
+	p := randomRows(1, size)[0]
+	expected := make([]fr.Element, k*size)
+	copy(expected, p)
+	extended.FFT(expected, fft.DIF, true)
+	fft.BitReverse(expected)
+
+	// the same evaluations, as k evaluations on the cosets g.ηˡ.H_Y, η being of order kN
+	var shift fr.Element
+	shift.Set(&extended.FrMultiplicativeGen)
+	for l := 0; l < k; l++ {
+		part := make([]fr.Element, size)
+		copy(part, p)
+		d.FFTY(part, shift)
+		for j := range part {
+			if !part[j].Equal(&expected[l+k*j]) {
+				t.Fatal("FFTY on a coset of the extended domain is not consistent with the FFT on the extended domain")
+			}
+		}
+
+		d.FFTInverseY(part, shift)
+		for j := range part {
+			if !part[j].Equal(&p[j]) {
+				t.Fatal("FFTInverseY is not the inverse of FFTY")
+			}
+		}
+		shift.Mul(&shift, &extended.Generator)
+	}
+}
+
+func TestBivariateCosetFFT(t *testing.T) {
+	const size = 16
+	for _, coset := range []bool{false, true} {
+		d := NewBivariateDomain(NewPartyDomain(nbParties, coset), size)
+		rows := randomRows(nbParties, size)
+		var shiftX, shiftY fr.Element
+		shiftX.SetRandom()
+		shiftY.SetRandom()
+
+		for _, shiftX := range []fr.Element{shiftX, d.X.Shift()} {
+			runParties(t, func(c communicator.Communicator) error {
+				columns, err := d.CosetFFT(rows[c.Rank()], shiftX, shiftY, c)
+				if err != nil {
+					return err
+				}
+
+				// the party holds the values at (shiftX.ωʳ, shiftY.νʲ) for the columns j of its block
+				m, b := int(d.X.Cardinality), d.BlockSize()
+				if len(columns) != m*b {
+					t.Fatal("wrong size of the block of columns")
+				}
+				var x, y fr.Element
+				x.Set(&shiftX)
+				for r := 0; r < m; r++ {
+					for j := 0; j < b; j++ {
+						var expected fr.Element
+						if col := c.Rank()*b + j; col < size {
+							y.Exp(d.Y.Generator, big.NewInt(int64(col))).Mul(&y, &shiftY)
+							expected = evalBivariate(rows, x, y, d.X)
+						}
+						if !columns[j*m+r].Equal(&expected) {
+							t.Errorf("coset=%v: wrong value at row %d, column %d", coset, r, j)
+							return nil
+						}
+					}
+					x.Mul(&x, &d.X.Generator)
+				}
+
+				// round trip
+				row, err := d.CosetFFTInverse(columns, shiftX, shiftY, c)
+				if err != nil {
+					return err
+				}
+				for j := range row {
+					if !row[j].Equal(&rows[c.Rank()][j]) {
+						t.Errorf("coset=%v: CosetFFTInverse is not the inverse of CosetFFT", coset)
+						return nil
+					}
+				}
+				return nil
+			})
+		}
+	}
+}
+
+// shortFrames is a communicator receiving a truncated frame from the party 1 in AllToAll
+type shortFrames struct {
+	communicator.Communicator
+}
+
+func (c shortFrames) AllToAll(bufs [][]byte) ([][]byte, error) {
+	received, err := c.Communicator.AllToAll(bufs)
+	if err != nil {
+		return nil, err
+	}
+	received[1] = received[1][:len(received[1])-1]
+	return received, nil
+}
+
+func TestTransposeInvalidFrames(t *testing.T) {
+	const size = 16
+	d := NewBivariateDomain(NewPartyDomain(nbParties, false), size)
+	runParties(t, func(c communicator.Communicator) error {
+		if _, err := d.Transpose(make([]fr.Element, size), shortFrames{c}); err != ErrInvalidMessage {
+			t.Errorf("Transpose: expected %v, got %v", ErrInvalidMessage, err)
+		}
+		columns := make([]fr.Element, d.BlockSize()*int(d.X.Cardinality))
+		if _, err := d.TransposeRows(columns, shortFrames{c}); err != ErrInvalidMessage {
+			t.Errorf("TransposeRows: expected %v, got %v", ErrInvalidMessage, err)
+		}
+		return nil
+	})
+}
+
+/*
+TestDistributedQuotient computes the quotients of a Pianist-like constraint A(X, Y)B(X, Y) - O(X, Y),
+which vanishes on H_X × H_Y:
+
+	A(X, Y)B(X, Y) - O(X, Y) = Z_Y(Y)Q_Y(X, Y) + Z_X(X)Q_X(X, Y)
+
+Q_Y is computed locally by each party, Q_X with the distributed FFT.
+*/
+func TestDistributedQuotient(t *testing.T) {
+	// Q_X at the padding points is not representable, so the world isn't padded
+	const worldSize, size = 4, 16
+	d := NewBivariateDomain(NewPartyDomain(worldSize, false), size)
+	a := randomRows(worldSize, size)
+	b := randomRows(worldSize, size)
+
+	runWorld(t, worldSize, func(c communicator.Communicator) error {
+		one := fr.One()
+		ai, bi := a[c.Rank()], b[c.Rank()]
+
+		// oᵢ interpolates aᵢbᵢ on H_Y
+		aEval := append([]fr.Element{}, ai...)
+		bEval := append([]fr.Element{}, bi...)
+		d.FFTY(aEval, one)
+		d.FFTY(bEval, one)
+		oi := make([]fr.Element, size)
+		for j := range oi {
+			oi[j].Mul(&aEval[j], &bEval[j])
+		}
+		d.FFTInverseY(oi, one)
+
+		// qᵢ = (aᵢbᵢ - oᵢ) / Z_Y, of degree < N, from its values on the coset s.H_Y
+		s := d.Y.FrMultiplicativeGen
+		zY := func(shift fr.Element) fr.Element {
+			var z fr.Element
+			z.Exp(shift, big.NewInt(size)).Sub(&z, &one)
+			return z
+		}
+		evalOn := func(p []fr.Element, shift fr.Element) []fr.Element {
+			res := append([]fr.Element{}, p...)
+			d.FFTY(res, shift)
+			return res
+		}
+		qi := make([]fr.Element, size)
+		{
+			aS, bS, oS := evalOn(ai, s), evalOn(bi, s), evalOn(oi, s)
+			zYInv := zY(s)
+			zYInv.Inverse(&zYInv)
+			for j := range qi {
+				qi[j].Mul(&aS[j], &bS[j]).Sub(&qi[j], &oS[j]).Mul(&qi[j], &zYInv)
+			}
+			d.FFTInverseY(qi, s)
+		}
+
+		// Q_X = (AB - O - Z_YQ_Y) / Z_X is of degree < 2N in Y: its values on sx.<ω> × t.H_Y for
+		// t = s and t = sη, η of order 2N, give Q_X mod (Yᴺ - tᴺ), that is lo + tᴺhi, Q_X = lo + Yᴺhi.
+		sx := d.X.FrMultiplicativeGen
+		var zXInv fr.Element
+		zXInv.Exp(sx, big.NewInt(int64(d.X.Cardinality))).Sub(&zXInv, &one).Inverse(&zXInv)
+		var eta fr.Element
+		eta.Set(&fft.NewDomain(2 * size).Generator)
+		shifts := []fr.Element{s, s}
+		shifts[1].Mul(&shifts[1], &eta)
+		reduced := make([][]fr.Element, 2)
+		for k, shift := range shifts {
+			var cols [4][]fr.Element
+			for l, p := range [][]fr.Element{ai, bi, oi, qi} {
+				var err error
+				if cols[l], err = d.CosetFFT(p, sx, shift, c); err != nil {
+					return err
+				}
+			}
+			z := zY(shift)
+			quotient := make([]fr.Element, len(cols[0]))
+			var tmp fr.Element
+			for j := range quotient {
+				quotient[j].Mul(&cols[0][j], &cols[1][j]).Sub(&quotient[j], &cols[2][j])
+				tmp.Mul(&z, &cols[3][j])
+				quotient[j].Sub(&quotient[j], &tmp).Mul(&quotient[j], &zXInv)
+			}
+			var err error
+			if reduced[k], err = d.CosetFFTInverse(quotient, sx, shift, c); err != nil {
+				return err
+			}
+		}
+		var sN, twoInv, twoSNInv fr.Element
+		sN.Exp(s, big.NewInt(size))
+		twoInv.SetUint64(2).Inverse(&twoInv)
+		twoSNInv.SetUint64(2).Mul(&twoSNInv, &sN).Inverse(&twoSNInv)
+		qx := make([]fr.Element, 2*size)
+		for j := 0; j < size; j++ {
+			qx[j].Add(&reduced[0][j], &reduced[1][j]).Mul(&qx[j], &twoInv)
+			qx[j+size].Sub(&reduced[0][j], &reduced[1][j]).Mul(&qx[j+size], &twoSNInv)
+		}
+
+		// check the identity at a random point on the root node
+		frames, err := c.Gather(elementsToBytes(append(qi, qx...)), 0)
+		if err != nil || c.Rank() != 0 {
+			return err
+		}
+		qY := make([][]fr.Element, worldSize)
+		qX := make([][]fr.Element, worldSize)
+		o := make([][]fr.Element, worldSize)
+		for i := range frames {
+			v := make([]fr.Element, 3*size)
+			bytesToElements(v, frames[i])
+			qY[i], qX[i] = v[:size], v[size:]
+		}
+		for i := range o {
+			// oᵢ is recomputed from aᵢ and bᵢ
+			aEval := evalOn(a[i], one)
+			bEval := evalOn(b[i], one)
+			o[i] = make([]fr.Element, size)
+			for j := range o[i] {
+				o[i][j].Mul(&aEval[j], &bEval[j])
+			}
+			d.FFTInverseY(o[i], one)
+		}
+
+		var x, y fr.Element
+		x.SetRandom()
+		y.SetRandom()
+		left := evalBivariate(a, x, y, d.X)
+		tmp := evalBivariate(b, x, y, d.X)
+		left.Mul(&left, &tmp)
+		tmp = evalBivariate(o, x, y, d.X)
+		left.Sub(&left, &tmp)
+
+		right := evalBivariate(qY, x, y, d.X)
+		z := zY(y)
+		right.Mul(&right, &z)
+		tmp = evalBivariate(qX, x, y, d.X)
+		z.Exp(x, big.NewInt(int64(d.X.Cardinality))).Sub(&z, &one)
+		tmp.Mul(&tmp, &z)
+		right.Add(&right, &tmp)
+
+		if !left.Equal(&right) {
+			t.Error("AB - O != Z_YQ_Y + Z_XQ_X")
+		}
+		return nil
+	})
+}
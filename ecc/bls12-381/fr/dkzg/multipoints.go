@@ -0,0 +1,457 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+)
+
+var (
+	ErrInvalidPointSet               = errors.New("dkzg: the sets of opening points must be non empty, without duplicates")
+	ErrVerifyBatchOpeningMultiPoints = errors.New("dkzg: can't verify batch opening proof at multiple points")
+)
+
+// MultiPointsOpeningProof opening proof of many polynomials, each of them at a set of points,
+// see BatchOpenMultiPoints.
+//
+// implements io.ReaderFrom and io.WriterTo
+type MultiPointsOpeningProof struct {
+	// W commitment of h(Y) = ∑ₖγᵏ(Fₖ(X, Y) - Rₖ(X, Y)) / Z_{Sₖ}(Y)
+	W bls12381.G1Affine
+
+	// WPrime commitment of L(Y) / (Y - z), see BatchOpenMultiPoints
+	WPrime bls12381.G1Affine
+
+	// ClaimedDigests purported digests, ClaimedDigests[k][j] is the digest of Fₖ(X, points[k][j])
+	ClaimedDigests [][]bls12381.G1Affine
+
+	// ClaimedValues purported values, ClaimedValues[k][j][i] is the value of the k-th polynomial
+	// of the party i at points[k][j]
+	ClaimedValues [][][]fr.Element
+}
+
+/*
+BatchOpenMultiPoints opens the polynomials Fₖ(X, Y) = ∑ᵢ fₖ,ᵢ(Y)Lᵢ(X), Fₖ being opened at the
+points of the set Sₖ = points[k] in the Y dimension. It is the scheme of Boneh, Drake, Fisch and
+Gabizon (Shplonk, https://eprint.iacr.org/2020/081), with a single distributed quotient:
+
+ 1. The nodes send the values fₖ,ᵢ(s), s ∈ Sₖ, and their digests, to the root node, in a single message.
+    Rₖ(X, Y) denotes the polynomial of degree < |Sₖ| in Y interpolating Fₖ(X, s) on Sₖ.
+ 2. γ is derived from the points, the digests and the claimed digests.
+ 3. W commits to h(Y) = ∑ₖγᵏ(Fₖ(X, Y) - Rₖ(X, Y)) / Z_{Sₖ}(Y), where Z_{Sₖ}(Y) = ∏_{s ∈ Sₖ}(Y - s).
+    The node i computes its share, that is the quotient of ∑ₖγᵏfₖ,ᵢ by Z_{Sₖ}.
+ 4. z is derived from W. With T = ∪ₖSₖ,
+    L(Y) = ∑ₖγᵏZ_{T\Sₖ}(z)(Fₖ(X, Y) - Rₖ(X, z)) - Z_T(z)h(Y) vanishes at z, and W' commits to L(Y) / (Y - z).
+
+* polynomials the polynomials fₖ,ᵢ of the node
+* digests the digests of the Fₖ, needed to derive the challenges
+* points the sets of points at which the polynomials are opened
+
+Only the root node returns the proof, the other nodes return an empty proof.
+*/
+func BatchOpenMultiPoints(polynomials [][]fr.Element, digests []Digest, points [][]fr.Element, hf hash.Hash, srs *SRS, c communicator.Communicator) (MultiPointsOpeningProof, error) {
+	nbDigests := len(digests)
+	if nbDigests == 0 || nbDigests != len(polynomials) || nbDigests != len(points) {
+		return MultiPointsOpeningProof{}, ErrInvalidNbDigests
+	}
+	for k := range polynomials {
+		if len(polynomials[k]) == 0 || len(polynomials[k]) > len(srs.G1) {
+			return MultiPointsOpeningProof{}, ErrInvalidPolynomialSize
+		}
+	}
+	t, err := pointsUnion(points)
+	if err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// 1. values of the node at the points, and their digests
+	var msg batchOpeningMessage
+	for k := range points {
+		for j := range points[k] {
+			v := eval(polynomials[k], points[k][j])
+			var vBigInt big.Int
+			v.ToBigIntRegular(&vBigInt)
+			var d bls12381.G1Affine
+			d.ScalarMultiplication(&srs.G1[0], &vBigInt)
+			msg.ClaimedValues = append(msg.ClaimedValues, v)
+			msg.ClaimedDigests = append(msg.ClaimedDigests, d)
+		}
+	}
+	frames, err := c.Gather(msg.marshal(), 0)
+	if err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// root node: claimed digests, and γ
+	var proof MultiPointsOpeningProof
+	var gamma fr.Element
+	fs := fiatshamir.NewTranscript(hf, "gamma", "z")
+	if c.Rank() == 0 {
+		if proof, err = aggregateMultiPointsClaims(frames, points); err != nil {
+			return MultiPointsOpeningProof{}, err
+		}
+		if gamma, err = deriveMultiPointsGamma(&fs, digests, points, proof.ClaimedDigests); err != nil {
+			return MultiPointsOpeningProof{}, err
+		}
+	}
+	if err := broadcastElement(&gamma, c); err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// 3. h = ∑ₖγᵏ fₖ / Z_{Sₖ}, quotients only
+	largestPoly := 0
+	for k := range polynomials {
+		if len(polynomials[k]) > largestPoly {
+			largestPoly = len(polynomials[k])
+		}
+	}
+	h := make([]fr.Element, largestPoly)
+	var gammak fr.Element
+	gammak.SetOne()
+	for k := range polynomials {
+		q := make([]fr.Element, len(polynomials[k]))
+		copy(q, polynomials[k])
+		for _, s := range points[k] {
+			if len(q) == 0 {
+				// deg(fₖ) < |Sₖ|, the quotient is 0
+				break
+			}
+			q = dividePolyByXminusA(q, eval(q, s), s)
+		}
+		var tmp fr.Element
+		for j := range q {
+			tmp.Mul(&q[j], &gammak)
+			h[j].Add(&h[j], &tmp)
+		}
+		gammak.Mul(&gammak, &gamma)
+	}
+	w, err := Commit(h, srs, c)
+	if err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// 4. z
+	var z fr.Element
+	if c.Rank() == 0 {
+		proof.W = w
+		if z, err = deriveMultiPointsZ(&fs, &proof.W); err != nil {
+			return MultiPointsOpeningProof{}, err
+		}
+	}
+	if err := broadcastElement(&z, c); err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+
+	// L = ∑ₖγᵏZ_{T\Sₖ}(z)(fₖ - rₖ(z)) - Z_T(z)h
+	zT, zTMinusS := vanishingAt(z, t, points)
+	l := make([]fr.Element, largestPoly)
+	var rz, tmp fr.Element
+	gammak.SetOne()
+	offset := 0
+	for k := range polynomials {
+		// rₖ(z) from the values of the node at the points of Sₖ
+		rz = interpolateAt(z, points[k], msg.ClaimedValues[offset:offset+len(points[k])])
+		offset += len(points[k])
+
+		var factor fr.Element
+		factor.Mul(&gammak, &zTMinusS[k])
+		for j := range polynomials[k] {
+			tmp.Mul(&polynomials[k][j], &factor)
+			l[j].Add(&l[j], &tmp)
+		}
+		tmp.Mul(&rz, &factor)
+		l[0].Sub(&l[0], &tmp)
+		gammak.Mul(&gammak, &gamma)
+	}
+	for j := range h {
+		tmp.Mul(&h[j], &zT)
+		l[j].Sub(&l[j], &tmp)
+	}
+
+	// L(z) = 0
+	var zero fr.Element
+	wPrime, err := Commit(dividePolyByXminusA(l, zero, z), srs, c)
+	if err != nil {
+		return MultiPointsOpeningProof{}, err
+	}
+	if c.Rank() != 0 {
+		return MultiPointsOpeningProof{}, nil
+	}
+	proof.WPrime = wPrime
+
+	return proof, nil
+}
+
+/*
+BatchVerifyMultiPointsOpening verifies a proof computed by BatchOpenMultiPoints, with one pairing check.
+
+With [Rₖ(z)]G₁ = ∑_{s ∈ Sₖ}ℓₖ,ₛ(z)ClaimedDigests[k][s], ℓₖ,ₛ being the Lagrange polynomials on Sₖ,
+F = ∑ₖγᵏZ_{T\Sₖ}(z)(digests[k] - [Rₖ(z)]G₁) - Z_T(z)W is a commitment to L(Y), and we check
+e(F + zW', G₂) = e(W', [τ₁]G₂).
+*/
+func BatchVerifyMultiPointsOpening(digests []Digest, proof *MultiPointsOpeningProof, points [][]fr.Element, hf hash.Hash, srs *SRS) error {
+	nbDigests := len(digests)
+	if nbDigests == 0 || nbDigests != len(points) || nbDigests != len(proof.ClaimedDigests) {
+		return ErrInvalidNbDigests
+	}
+	for k := range points {
+		if len(points[k]) != len(proof.ClaimedDigests[k]) {
+			return ErrInvalidNbDigests
+		}
+	}
+	t, err := pointsUnion(points)
+	if err != nil {
+		return err
+	}
+
+	fs := fiatshamir.NewTranscript(hf, "gamma", "z")
+	gamma, err := deriveMultiPointsGamma(&fs, digests, points, proof.ClaimedDigests)
+	if err != nil {
+		return err
+	}
+	z, err := deriveMultiPointsZ(&fs, &proof.W)
+	if err != nil {
+		return err
+	}
+	zT, zTMinusS := vanishingAt(z, t, points)
+
+	// F + zW' as a single multi exponentiation
+	bases := make([]bls12381.G1Affine, 0, 2+nbDigests+len(t))
+	scalars := make([]fr.Element, 0, cap(bases))
+	var gammak, factor, coeff fr.Element
+	gammak.SetOne()
+	for k := 0; k < nbDigests; k++ {
+		factor.Mul(&gammak, &zTMinusS[k])
+		bases = append(bases, digests[k])
+		scalars = append(scalars, factor)
+
+		lagrange := lagrangeAt(z, points[k])
+		for j := range points[k] {
+			coeff.Mul(&factor, &lagrange[j]).Neg(&coeff)
+			bases = append(bases, proof.ClaimedDigests[k][j])
+			scalars = append(scalars, coeff)
+		}
+		gammak.Mul(&gammak, &gamma)
+	}
+	zT.Neg(&zT)
+	bases = append(bases, proof.W, proof.WPrime)
+	scalars = append(scalars, zT, z)
+
+	var f bls12381.G1Affine
+	if _, err := f.MultiExp(bases, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+
+	// e(F + zW', G₂).e(-W', [τ₁]G₂) ==? 1
+	var negWPrime bls12381.G1Affine
+	negWPrime.Neg(&proof.WPrime)
+	check, err := bls12381.PairingCheck(
+		[]bls12381.G1Affine{f, negWPrime},
+		[]bls12381.G2Affine{srs.G2[0], srs.G2[1]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrVerifyBatchOpeningMultiPoints
+	}
+	return nil
+}
+
+// aggregateMultiPointsClaims decodes the messages of the nodes on the root node, and sums the digests
+func aggregateMultiPointsClaims(frames [][]byte, points [][]fr.Element) (MultiPointsOpeningProof, error) {
+	var proof MultiPointsOpeningProof
+	nbClaims := 0
+	proof.ClaimedValues = make([][][]fr.Element, len(points))
+	for k := range points {
+		proof.ClaimedValues[k] = make([][]fr.Element, len(points[k]))
+		for j := range points[k] {
+			proof.ClaimedValues[k][j] = make([]fr.Element, len(frames))
+		}
+		nbClaims += len(points[k])
+	}
+
+	claimedDigests := make([]bls12381.G1Jac, nbClaims)
+	for i := range frames {
+		var msg batchOpeningMessage
+		if err := msg.unmarshal(frames[i]); err != nil {
+			return MultiPointsOpeningProof{}, err
+		}
+		if len(msg.ClaimedValues) != nbClaims {
+			return MultiPointsOpeningProof{}, ErrInvalidMessage
+		}
+		offset := 0
+		for k := range points {
+			for j := range points[k] {
+				proof.ClaimedValues[k][j][i] = msg.ClaimedValues[offset]
+				claimedDigests[offset].AddMixed(&msg.ClaimedDigests[offset])
+				offset++
+			}
+		}
+	}
+
+	proof.ClaimedDigests = make([][]bls12381.G1Affine, len(points))
+	offset := 0
+	for k := range points {
+		proof.ClaimedDigests[k] = make([]bls12381.G1Affine, len(points[k]))
+		for j := range points[k] {
+			proof.ClaimedDigests[k][j].FromJacobian(&claimedDigests[offset])
+			offset++
+		}
+	}
+	return proof, nil
+}
+
+// deriveMultiPointsGamma derives γ, binded to the points, the digests and the claimed digests
+func deriveMultiPointsGamma(fs *fiatshamir.Transcript, digests []Digest, points [][]fr.Element, claimedDigests [][]bls12381.G1Affine) (fr.Element, error) {
+	for k := range points {
+		for j := range points[k] {
+			if err := fs.Bind("gamma", points[k][j].Marshal()); err != nil {
+				return fr.Element{}, err
+			}
+		}
+	}
+	for k := range digests {
+		if err := fs.Bind("gamma", digests[k].Marshal()); err != nil {
+			return fr.Element{}, err
+		}
+	}
+	for k := range claimedDigests {
+		for j := range claimedDigests[k] {
+			if err := fs.Bind("gamma", claimedDigests[k][j].Marshal()); err != nil {
+				return fr.Element{}, err
+			}
+		}
+	}
+	gammaByte, err := fs.ComputeChallenge("gamma")
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var gamma fr.Element
+	gamma.SetBytes(gammaByte)
+	return gamma, nil
+}
+
+// deriveMultiPointsZ derives z, binded to γ and W
+func deriveMultiPointsZ(fs *fiatshamir.Transcript, w *bls12381.G1Affine) (fr.Element, error) {
+	if err := fs.Bind("z", w.Marshal()); err != nil {
+		return fr.Element{}, err
+	}
+	zByte, err := fs.ComputeChallenge("z")
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var z fr.Element
+	z.SetBytes(zByte)
+	return z, nil
+}
+
+// pointsUnion returns T = ∪ₖSₖ, and checks that the sets are non empty, without duplicates
+func pointsUnion(points [][]fr.Element) ([]fr.Element, error) {
+	var t []fr.Element
+	for k := range points {
+		if len(points[k]) == 0 {
+			return nil, ErrInvalidPointSet
+		}
+		for j := range points[k] {
+			for l := 0; l < j; l++ {
+				if points[k][l].Equal(&points[k][j]) {
+					return nil, ErrInvalidPointSet
+				}
+			}
+			if !contains(t, &points[k][j]) {
+				t = append(t, points[k][j])
+			}
+		}
+	}
+	return t, nil
+}
+
+func contains(set []fr.Element, e *fr.Element) bool {
+	for i := range set {
+		if set[i].Equal(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// vanishingAt returns Z_T(z) and the Z_{T\Sₖ}(z)
+func vanishingAt(z fr.Element, t []fr.Element, points [][]fr.Element) (fr.Element, []fr.Element) {
+	var zT, tmp fr.Element
+	zT.SetOne()
+	for i := range t {
+		tmp.Sub(&z, &t[i])
+		zT.Mul(&zT, &tmp)
+	}
+	zTMinusS := make([]fr.Element, len(points))
+	for k := range points {
+		zTMinusS[k].SetOne()
+		for i := range t {
+			if contains(points[k], &t[i]) {
+				continue
+			}
+			tmp.Sub(&z, &t[i])
+			zTMinusS[k].Mul(&zTMinusS[k], &tmp)
+		}
+	}
+	return zT, zTMinusS
+}
+
+// lagrangeAt returns the ℓₛ(z), ℓₛ being the Lagrange polynomials on the set of points s
+func lagrangeAt(z fr.Element, s []fr.Element) []fr.Element {
+	res := make([]fr.Element, len(s))
+	den := make([]fr.Element, len(s))
+	var tmp fr.Element
+	for j := range s {
+		res[j].SetOne()
+		den[j].SetOne()
+		for l := range s {
+			if l == j {
+				continue
+			}
+			tmp.Sub(&z, &s[l])
+			res[j].Mul(&res[j], &tmp)
+			tmp.Sub(&s[j], &s[l])
+			den[j].Mul(&den[j], &tmp)
+		}
+	}
+	den = fr.BatchInvert(den)
+	for j := range res {
+		res[j].Mul(&res[j], &den[j])
+	}
+	return res
+}
+
+// interpolateAt returns r(z), r being the polynomial of degree < len(s) such that r(s[j]) = values[j]
+func interpolateAt(z fr.Element, s, values []fr.Element) fr.Element {
+	lagrange := lagrangeAt(z, s)
+	var res, tmp fr.Element
+	for j := range lagrange {
+		tmp.Mul(&lagrange[j], &values[j])
+		res.Add(&res, &tmp)
+	}
+	return res
+}
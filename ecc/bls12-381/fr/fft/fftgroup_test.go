@@ -0,0 +1,199 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package fft
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+func TestFFTG1(t *testing.T) {
+	const size = 64
+	domain := NewDomain(size)
+
+	// [aᵢ]G, so that the FFT of the points is [FFT(a)ᵢ]G
+	scalars := make([]fr.Element, size)
+	for i := range scalars {
+		scalars[i].SetRandom()
+	}
+	points := randomG1(scalars)
+
+	for _, coset := range []bool{false, true} {
+		for _, decimation := range []Decimation{DIF, DIT} {
+			a := make([]fr.Element, size)
+			copy(a, scalars)
+			p := make([]curve.G1Jac, size)
+			for i := range p {
+				p[i].FromAffine(&points[i])
+			}
+			if decimation == DIT {
+				BitReverse(a)
+				BitReverseG1(p)
+			}
+			domain.FFT(a, decimation, coset)
+			domain.FFTG1(p, decimation, coset)
+			checkG1(t, p, a)
+
+			// back to the points, in natural order
+			if decimation == DIF {
+				domain.FFTInverse(a, DIT, coset)
+				domain.FFTInverseG1(p, DIT, coset)
+			} else {
+				domain.FFTInverse(a, DIF, coset)
+				domain.FFTInverseG1(p, DIF, coset)
+				BitReverse(a)
+				BitReverseG1(p)
+			}
+			checkG1(t, p, a)
+			checkG1(t, p, scalars)
+		}
+	}
+
+	// in affine coordinates
+	a := make([]fr.Element, size)
+	copy(a, scalars)
+	p := make([]curve.G1Affine, size)
+	copy(p, points)
+	domain.FFT(a, DIF)
+	domain.FFTG1Affine(p, DIF)
+	expected := randomG1(a)
+	for i := range p {
+		if !p[i].Equal(&expected[i]) {
+			t.Fatal("affine FFT of the points is not consistent with the FFT of the scalars")
+		}
+	}
+	domain.FFTInverseG1Affine(p, DIT)
+	for i := range p {
+		if !p[i].Equal(&points[i]) {
+			t.Fatal("affine inverse FFT of the points is not consistent with the FFT of the scalars")
+		}
+	}
+}
+
+// randomG1 returns the [scalars[i]]G
+func randomG1(scalars []fr.Element) []curve.G1Affine {
+	_scalars := make([]fr.Element, len(scalars))
+	for i := range scalars {
+		_scalars[i] = scalars[i]
+		_scalars[i].FromMont()
+	}
+	_, _, gen, _ := curve.Generators()
+	return curve.BatchScalarMultiplicationG1(&gen, _scalars)
+}
+
+// checkG1 checks that p[i] = [a[i]]G
+func checkG1(t *testing.T, p []curve.G1Jac, a []fr.Element) {
+	t.Helper()
+	expected := randomG1(a)
+	for i := range p {
+		var e curve.G1Jac
+		e.FromAffine(&expected[i])
+		if !p[i].Equal(&e) {
+			t.Fatal("FFT of the points is not consistent with the FFT of the scalars")
+		}
+	}
+}
+
+func TestFFTG2(t *testing.T) {
+	const size = 64
+	domain := NewDomain(size)
+
+	// [aᵢ]G, so that the FFT of the points is [FFT(a)ᵢ]G
+	scalars := make([]fr.Element, size)
+	for i := range scalars {
+		scalars[i].SetRandom()
+	}
+	points := randomG2(scalars)
+
+	for _, coset := range []bool{false, true} {
+		for _, decimation := range []Decimation{DIF, DIT} {
+			a := make([]fr.Element, size)
+			copy(a, scalars)
+			p := make([]curve.G2Jac, size)
+			for i := range p {
+				p[i].FromAffine(&points[i])
+			}
+			if decimation == DIT {
+				BitReverse(a)
+				BitReverseG2(p)
+			}
+			domain.FFT(a, decimation, coset)
+			domain.FFTG2(p, decimation, coset)
+			checkG2(t, p, a)
+
+			// back to the points, in natural order
+			if decimation == DIF {
+				domain.FFTInverse(a, DIT, coset)
+				domain.FFTInverseG2(p, DIT, coset)
+			} else {
+				domain.FFTInverse(a, DIF, coset)
+				domain.FFTInverseG2(p, DIF, coset)
+				BitReverse(a)
+				BitReverseG2(p)
+			}
+			checkG2(t, p, a)
+			checkG2(t, p, scalars)
+		}
+	}
+
+	// in affine coordinates
+	a := make([]fr.Element, size)
+	copy(a, scalars)
+	p := make([]curve.G2Affine, size)
+	copy(p, points)
+	domain.FFT(a, DIF)
+	domain.FFTG2Affine(p, DIF)
+	expected := randomG2(a)
+	for i := range p {
+		if !p[i].Equal(&expected[i]) {
+			t.Fatal("affine FFT of the points is not consistent with the FFT of the scalars")
+		}
+	}
+	domain.FFTInverseG2Affine(p, DIT)
+	for i := range p {
+		if !p[i].Equal(&points[i]) {
+			t.Fatal("affine inverse FFT of the points is not consistent with the FFT of the scalars")
+		}
+	}
+}
+
+// randomG2 returns the [scalars[i]]G
+func randomG2(scalars []fr.Element) []curve.G2Affine {
+	_scalars := make([]fr.Element, len(scalars))
+	for i := range scalars {
+		_scalars[i] = scalars[i]
+		_scalars[i].FromMont()
+	}
+	_, _, _, gen := curve.Generators()
+	return curve.BatchScalarMultiplicationG2(&gen, _scalars)
+}
+
+// checkG2 checks that p[i] = [a[i]]G
+func checkG2(t *testing.T, p []curve.G2Jac, a []fr.Element) {
+	t.Helper()
+	expected := randomG2(a)
+	for i := range p {
+		var e curve.G2Jac
+		e.FromAffine(&expected[i])
+		if !p[i].Equal(&e) {
+			t.Fatal("FFT of the points is not consistent with the FFT of the scalars")
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package kzg
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+var (
+	ErrNoHidingGenerator = errors.New("the SRS has no hiding generator")
+	ErrVerifyHidingProof = errors.New("can't verify hiding opening proof")
+)
+
+// NewHidingSRS returns a new SRS using alpha as randomness source, with the hiding generators
+// [h]G₁ and [αh]G₁ in srs.H, h being an independent randomness source.
+//
+// In production, a SRS generated through MPC should be used.
+func NewHidingSRS(size uint64, bAlpha, bH *big.Int) (*SRS, error) {
+	srs, err := NewSRS(size, bAlpha)
+	if err != nil {
+		return nil, err
+	}
+
+	var h, alphaH fr.Element
+	h.SetBigInt(bH)
+	alphaH.SetBigInt(bAlpha).Mul(&alphaH, &h)
+	var bAlphaH big.Int
+	alphaH.ToBigIntRegular(&bAlphaH)
+
+	srs.H[0].ScalarMultiplication(&srs.G1[0], bH)
+	srs.H[1].ScalarMultiplication(&srs.G1[0], &bAlphaH)
+
+	return srs, nil
+}
+
+// Blinding random polynomial ρ₀ + ρ₁X hiding a commitment, as returned by HidingCommit.
+// It must be kept secret, and is needed to open the commitment.
+type Blinding [2]fr.Element
+
+// eval returns ρ₀ + ρ₁.point
+func (b *Blinding) eval(point fr.Element) fr.Element {
+	var res fr.Element
+	res.Mul(&b[1], &point).Add(&res, &b[0])
+	return res
+}
+
+// HidingOpeningProof KZG proof for opening a hiding commitment at a single point.
+//
+// implements io.ReaderFrom and io.WriterTo
+type HidingOpeningProof struct {
+	// H quotient polynomial (f - f(z))/(x-z), blinded by ρ₁[h]G₁
+	H bls12381.G1Affine
+
+	// ClaimedValue purported value
+	ClaimedValue fr.Element
+
+	// ClaimedBlinding value of the blinding polynomial at z, ρ₀ + ρ₁z
+	ClaimedBlinding fr.Element
+}
+
+/*
+HidingCommit commits to a polynomial, in canonical form, in Montgomery form, adding a random blinding:
+
+	C = [f(α)]G₁ + ρ₀[h]G₁ + ρ₁[αh]G₁
+
+that is a commitment to f + hr, r = ρ₀ + ρ₁X being the returned blinding polynomial. The commitment
+is perfectly hiding, and computationally binding. It must be opened at a single point: the openings
+at two points reveal r.
+*/
+func HidingCommit(p []fr.Element, srs *SRS, nbTasks ...int) (Digest, Blinding, error) {
+	if srs.H[0].IsInfinity() {
+		return Digest{}, Blinding{}, ErrNoHidingGenerator
+	}
+
+	var blinding Blinding
+	for i := range blinding {
+		if _, err := blinding[i].SetRandom(); err != nil {
+			return Digest{}, Blinding{}, err
+		}
+	}
+
+	res, err := Commit(p, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, Blinding{}, err
+	}
+
+	var blind Digest
+	if _, err := blind.MultiExp(srs.H[:], blinding[:], ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return Digest{}, Blinding{}, err
+	}
+	res.Add(&res, &blind)
+
+	return res, blinding, nil
+}
+
+// HidingOpen computes an opening proof at point of the polynomial p, committed with HidingCommit.
+//
+// (r - r(z))/(x-z) = ρ₁, so the quotient of f + hr is blinded by ρ₁[h]G₁.
+func HidingOpen(p []fr.Element, blinding Blinding, point fr.Element, srs *SRS) (HidingOpeningProof, error) {
+	if srs.H[0].IsInfinity() {
+		return HidingOpeningProof{}, ErrNoHidingGenerator
+	}
+
+	proof, err := Open(p, point, srs)
+	if err != nil {
+		return HidingOpeningProof{}, err
+	}
+
+	var rho1 big.Int
+	blinding[1].ToBigIntRegular(&rho1)
+	var blind bls12381.G1Affine
+	blind.ScalarMultiplication(&srs.H[0], &rho1)
+
+	res := HidingOpeningProof{
+		ClaimedValue:    proof.ClaimedValue,
+		ClaimedBlinding: blinding.eval(point),
+	}
+	res.H.Add(&proof.H, &blind)
+
+	return res, nil
+}
+
+// HidingVerify verifies a KZG opening proof of a hiding commitment at a single point
+//
+//	e(C - [f(a)]G₁ - [r(a)][h]G₁, G₂) = e(H, [α-a]G₂)
+func HidingVerify(commitment *Digest, proof *HidingOpeningProof, point fr.Element, srs *SRS) error {
+	if srs.H[0].IsInfinity() {
+		return ErrNoHidingGenerator
+	}
+
+	// [f(a)]G₁ + [r(a)][h]G₁
+	var claimed bls12381.G1Affine
+	if _, err := claimed.MultiExp(
+		[]bls12381.G1Affine{srs.G1[0], srs.H[0]},
+		[]fr.Element{proof.ClaimedValue, proof.ClaimedBlinding},
+		ecc.MultiExpConfig{ScalarsMont: true},
+	); err != nil {
+		return err
+	}
+
+	// C - [f(a)]G₁ - [r(a)][h]G₁ + [a]H
+	var pointBigInt big.Int
+	point.ToBigIntRegular(&pointBigInt)
+	var aH, left bls12381.G1Affine
+	aH.ScalarMultiplication(&proof.H, &pointBigInt)
+	left.Sub(commitment, &claimed).Add(&left, &aH)
+
+	// [-H(α)]G₁
+	var negH bls12381.G1Affine
+	negH.Neg(&proof.H)
+
+	// e(C - [f(a)]G₁ - [r(a)][h]G₁ + [a]H, G₂).e([-H], [α]G₂) ==? 1
+	check, err := bls12381.PairingCheck(
+		[]bls12381.G1Affine{left, negH},
+		[]bls12381.G2Affine{srs.G2[0], srs.G2[1]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrVerifyHidingProof
+	}
+	return nil
+}
@@ -18,8 +18,11 @@ package plookup
 
 import (
 	"math/big"
+	"sync"
 	"testing"
 
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/dkzg"
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
 )
 
@@ -118,6 +121,85 @@ func TestLookupTable(t *testing.T) {
 
 }
 
+// runParties runs f on each party of an in-process world of nbParties parties
+func runParties(t *testing.T, nbParties int, f func(c communicator.Communicator) error) {
+	t.Helper()
+	comms := communicator.NewChannels(nbParties)
+	errs := make([]error, nbParties)
+	var wg sync.WaitGroup
+	wg.Add(nbParties)
+	for i := 0; i < nbParties; i++ {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(comms[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("party %d: %v", i, err)
+		}
+	}
+}
+
+func TestDistributedLookupVector(t *testing.T) {
+
+	const nbParties = 3
+
+	// the table is shared, each party has its own queries
+	lookupVector := make(Table, 16)
+	fvectors := make([]Table, nbParties)
+	for i := 0; i < 16; i++ {
+		lookupVector[i].SetUint64(uint64(2 * i))
+	}
+	for p := range fvectors {
+		fvectors[p] = make(Table, 7)
+		for i := 0; i < 7; i++ {
+			fvectors[p][i].Set(&lookupVector[(4*i+3*p+1)%16])
+		}
+	}
+
+	prove := func() (ProofDistributedLookupVector, *dkzg.SRS) {
+		var proof ProofDistributedLookupVector
+		var srs *dkzg.SRS
+		runParties(t, nbParties, func(c communicator.Communicator) error {
+			_srs, err := dkzg.NewSRS(16, []*big.Int{big.NewInt(13), big.NewInt(7)}, nil, c)
+			if err != nil {
+				return err
+			}
+			_proof, err := ProveDistributedLookupVector(_srs, fvectors[c.Rank()], lookupVector, c)
+			if err != nil {
+				return err
+			}
+			if c.Rank() == 0 {
+				proof, srs = _proof, _srs
+			}
+			return nil
+		})
+		return proof, srs
+	}
+
+	// correct proof vector
+	{
+		proof, srs := prove()
+		err := VerifyDistributedLookupVector(srs, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// wrong proofs vector
+	{
+		fvectors[1][0].SetRandom()
+		proof, srs := prove()
+		err := VerifyDistributedLookupVector(srs, proof)
+		if err == nil {
+			t.Fatal("verifying a proof of a value that is not in the table should have failed")
+		}
+	}
+
+}
+
 func BenchmarkPlookup(b *testing.B) {
 
 	srsSize := 1 << 15
@@ -0,0 +1,922 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark-crypto/internal/parallel"
+)
+
+var (
+	ErrInvalidNbDigests              = errors.New("dkzg: number of digests is not the same as the number of polynomials")
+	ErrInvalidPolynomialSize         = errors.New("dkzg: invalid polynomial size (larger than SRS or == 0)")
+	ErrInvalidMessage                = errors.New("dkzg: invalid message")
+	ErrVerifyOpeningProof            = errors.New("dkzg: can't verify opening proof")
+	ErrVerifyBatchOpeningSinglePoint = errors.New("dkzg: can't verify batch opening proof at single point")
+	ErrMinSRSSize                    = errors.New("dkzg: minimum srs size is 2")
+	ErrVerifyClaimedValues           = errors.New("dkzg: claimed values are not consistent with the claimed digest")
+)
+
+// Digest commitment of a polynomial.
+//
+// Digests, and slices of digests, are encoded with the Encoder and Decoder of the curve package.
+type Digest = bls24315.G1Affine
+
+type SRS struct {
+	G1 []bls24315.G1Affine  // G1[i][j] = g^(L_i(\tau[0]) * \tau[1]^j)
+	G2 [3]bls24315.G2Affine // G2[0] = g2, G2[1] = g2^tau[1], G2[2] = g2^tau[0]
+
+	// Lagrange[k] = g^(L_k(\tau[0])) for all the points of the party domain, Lagrange[Rank] = G1[0].
+	// The root node needs them to open F(X, y), see OpenBivariate.
+	Lagrange []bls24315.G1Affine
+
+	// H[0] = g^(h * L_i(\tau[0])), H[1] = g^(h * L_i(\tau[0]) * \tau[1]) blind the share of the party,
+	// HLagrange[k] = g^(h * L_k(\tau[0])) for all the points of the party domain.
+	// They are zero, and nil, if the SRS is not hiding, see NewHidingSRS.
+	H         [2]bls24315.G1Affine
+	HLagrange []bls24315.G1Affine
+
+	Rank      int  // rank i of the party owning the SRS
+	WorldSize int  // number of parties
+	Coset     bool // the party domain is a coset, see PartyDomain
+}
+
+// PartyDomain returns the party domain of the SRS
+func (srs *SRS) PartyDomain() *PartyDomain {
+	return NewPartyDomain(srs.WorldSize, srs.Coset)
+}
+
+// eval returns p(point) where p is interpreted as a polynomial
+// ∑_{i<len(p)}p[i]Xⁱ
+func eval(p []fr.Element, point fr.Element) fr.Element {
+	var res fr.Element
+	n := len(p)
+	res.Set(&p[n-1])
+	for i := n - 2; i >= 0; i-- {
+		res.Mul(&res, &point).Add(&res, &p[i])
+	}
+	return res
+}
+
+// NewSRS returns the SRS of the party c.Rank() using tau as randomness source
+//
+// domain is the party domain of the c.Size() parties, the default one if nil, see PartyDomain.
+//
+// In production, a SRS generated through MPC should be used.
+//
+// implements io.ReaderFrom and io.WriterTo
+func NewSRS(size uint64, tau []*big.Int, domain *PartyDomain, c communicator.Communicator) (*SRS, error) {
+	if domain == nil {
+		domain = NewPartyDomain(c.Size(), false)
+	}
+	if err := domain.check(c.Size()); err != nil {
+		return nil, err
+	}
+
+	_, _, gen1Aff, gen2Aff := bls24315.Generators()
+	tau0 := new(fr.Element).SetBigInt(tau[0])
+	// Lagrange polynomials of all the points of the party domain, at tau[0]
+	lagTau0 := domain.LagrangeAt(*tau0)
+
+	var srs SRS
+	srs.Rank = c.Rank()
+	srs.WorldSize = c.Size()
+	srs.Coset = domain.Coset
+
+	var alpha fr.Element
+	alpha.SetBigInt(tau[1])
+
+	srs.G2[0] = gen2Aff
+	srs.G2[1].ScalarMultiplication(&gen2Aff, tau[1])
+	srs.G2[2].ScalarMultiplication(&gen2Aff, tau[0])
+
+	alphas := make([]fr.Element, size)
+	alphas[0].Set(&lagTau0[c.Rank()])
+	for i := 1; i < len(alphas); i++ {
+		alphas[i].Mul(&alphas[i-1], &alpha)
+	}
+	for i := 0; i < len(alphas); i++ {
+		alphas[i].FromMont()
+	}
+	srs.G1 = bls24315.BatchScalarMultiplicationG1(&gen1Aff, alphas)
+
+	for i := range lagTau0 {
+		lagTau0[i].FromMont()
+	}
+	srs.Lagrange = bls24315.BatchScalarMultiplicationG1(&gen1Aff, lagTau0)
+	return &srs, nil
+}
+
+/*
+The distributed commit algorithm computes the following:
+ 1. Each node has a polynomial f_i(y) = \sum_{j=0}^{N-1} f_{i, j} * y^j.
+ 2. The commit algorithm commits to: F(x, y) = \sum_{i=0}^{M-1} f_i(y) * L_i(x), where L_i(x) is the Lagrange basis polynomial of i.
+ 3. The commitment is g^{F(\tau[0], \tau[1])} = \Pi_{i=0}^{M-1} g^{f_i(\tau[1]) * L_i(\tau[0])} = \Pi_{i=0}^{M-1}\Pi_{j=0}^{N-1} g^{f_{i, j} * \tau{1}^j * L_i(\tau[0])} = \Pi_{i=0}^{M-1}\Pi_{j=0}^{N-1} (g^{L_i(\tau[0])*\tau[1]^j}) ^ f_{i, j} = \Pi_{i=0}^{M-1}\Pi_{j=0}^{N-1} U ^ f_{i, j}, where U = srs.G1[j].
+
+Only the root node returns the commitment, the other nodes return an empty Digest, see AllCommit.
+*/
+func Commit(p []fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (Digest, error) {
+	// Each compute node computes the commitment of its own polynomial,
+	// the commitments are summed along a tree rooted at the root node
+	subCom, err := commitShare(p, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, err
+	}
+	subComBytes := G1AffineToBytes(subCom)
+	if err := c.Reduce(subComBytes, 0, addG1); err != nil {
+		return Digest{}, err
+	}
+	if c.Rank() != 0 {
+		// Only the root node returns the final commitment
+		return Digest{}, nil
+	}
+	return BytesToG1Affine(subComBytes), nil
+}
+
+// AllCommit is Commit, with the commitment returned to all the nodes so that
+// each of them can keep running the protocol transcript.
+func AllCommit(p []fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (Digest, error) {
+	subCom, err := commitShare(p, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, err
+	}
+	subComBytes := G1AffineToBytes(subCom)
+	if err := c.AllReduce(subComBytes, addG1); err != nil {
+		return Digest{}, err
+	}
+	return BytesToG1Affine(subComBytes), nil
+}
+
+// commitShare commits to the polynomial of the node, that is f_i(Y) * L_i(\tau[0])
+func commitShare(p []fr.Element, srs *SRS, nbTasks ...int) (bls24315.G1Affine, error) {
+	if len(p) == 0 || len(p) > len(srs.G1) {
+		return bls24315.G1Affine{}, ErrInvalidPolynomialSize
+	}
+
+	var res bls24315.G1Affine
+
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	if len(nbTasks) > 0 {
+		config.NbTasks = nbTasks[0]
+	}
+
+	if _, err := res.MultiExp(srs.G1[:len(p)], p, config); err != nil {
+		return bls24315.G1Affine{}, err
+	}
+	return res, nil
+}
+
+// addG1 is the communicator.ReduceOp summing G1 points encoded with G1AffineToBytes,
+// or arrays of them, element wise
+func addG1(acc, buf []byte) error {
+	if len(acc) != len(buf) || len(acc)%bls24315.SizeOfG1AffineUncompressed != 0 {
+		return ErrInvalidMessage
+	}
+	for i := 0; i < len(acc); i += bls24315.SizeOfG1AffineUncompressed {
+		a := BytesToG1Affine(acc[i:])
+		b := BytesToG1Affine(buf[i:])
+		a.Add(&a, &b)
+		copy(acc[i:], G1AffineToBytes(a))
+	}
+	return nil
+}
+
+// implements io.ReaderFrom and io.WriterTo
+type OpeningProof struct {
+	// H quotient polynomial (f(Y, X) - f(Y, alpha)) / (X - alpha)
+	H bls24315.G1Affine
+
+	// ClaimedDigest purported the digest of value
+	ClaimedDigest bls24315.G1Affine
+
+	// ClaimedValues purported values f_i(y), ClaimedValues[i] is the value of the party i
+	ClaimedValues []fr.Element
+}
+
+// dividePolyByXminusA computes (f-f(a))/(x-a), in canonical basis, in regular form
+// f memory is re-used for the result
+func dividePolyByXminusA(f []fr.Element, fa, a fr.Element) []fr.Element {
+
+	// first we compute f-f(a)
+	f[0].Sub(&f[0], &fa)
+
+	// now we use syntetic division to divide by x-a
+	var t fr.Element
+	for i := len(f) - 2; i >= 0; i-- {
+		t.Mul(&f[i+1], &a)
+
+		f[i].Add(&f[i], &t)
+	}
+
+	// the result is of degree deg(f)-1
+	return f[1:]
+}
+
+/*
+Given y=y_0, the big polynomail F(x, y_0) becomes F(x, y_0) = \sum_{i=0}^{M-1} f_i(y_0) * L_i(x).
+It now becomes a uni-variate polynomial, let it be F'(x) = \sum_{i=0}^{M-1} f_i(y_0) * L_i(x).
+New commitment is g^{F'(\tau[0])} = \Pi_{i=0}^{M-1} g^{f_i(y_0) * L_i(\tau[0])} = \Pi_{i=0}^{M-1}f_i(y_0)g^{G1[i][0]}.
+
+Proof that old commitment is consistent with new commitment:
+F(\tau[0], \tau[1]) - F(x, \tau[1]) / (\tau[0] - x) = h(x)
+*/
+func Open(p []fr.Element, y fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (OpeningProof, []fr.Element, error) {
+	if len(p) == 0 || len(p) > len(srs.G1) {
+		return OpeningProof{}, nil, ErrInvalidPolynomialSize
+	}
+
+	// Build the next level commitment
+	// Eval at F(\tau[0], y) = \sum_{i=0}^{M-1} f_i(y) * L_i(\tau[0])
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	if len(nbTasks) > 0 {
+		config.NbTasks = nbTasks[0]
+	}
+
+	// compute f_i(y)
+	fY := eval(p, y)
+	var fYBigInt big.Int
+	fY.ToBigIntRegular(&fYBigInt)
+
+	// digest of f_i(y)
+	var comFY bls24315.G1Affine
+	comFY.ScalarMultiplication(&srs.G1[0], &fYBigInt)
+
+	// compute H
+	_p := make([]fr.Element, len(p))
+	copy(_p, p)
+	h := dividePolyByXminusA(_p, fY, y)
+
+	_p = nil // h re-use this memory
+
+	// commit to H
+	comH, err := Commit(h, srs, c)
+	if err != nil {
+		return OpeningProof{}, nil, err
+	}
+
+	// Send the new commitment to the root node
+	fYBytes := fY.Bytes()
+	allFYBytes, err := c.Gather(fYBytes[:], 0)
+	if err != nil {
+		return OpeningProof{}, nil, err
+	}
+	comFYBytes := G1AffineToBytes(comFY)
+	if err := c.Reduce(comFYBytes, 0, addG1); err != nil {
+		return OpeningProof{}, nil, err
+	}
+	if c.Rank() != 0 {
+		return OpeningProof{}, nil, nil
+	}
+
+	// Root node
+	allFY := make([]fr.Element, c.Size())
+	for i := 0; i < c.Size(); i++ {
+		allFY[i].SetBytes(allFYBytes[i])
+	}
+	comFY = BytesToG1Affine(comFYBytes)
+
+	return OpeningProof{
+		H:             comH,
+		ClaimedDigest: comFY,
+		ClaimedValues: allFY,
+	}, allFY, nil
+}
+
+// BatchOpeningProof opening proof for many polynomials at the same point
+//
+// implements io.ReaderFrom and io.WriterTo
+type BatchOpeningProof struct {
+	// H quotient polynomial Sum_i gamma**i*(f - f(z))/(x-z)
+	H bls24315.G1Affine
+
+	// ClaimedDigests purported digests of the values
+	ClaimedDigests []bls24315.G1Affine
+
+	// ClaimedValues purported values, ClaimedValues[k][i] is the value of the k-th polynomial of the party i
+	ClaimedValues [][]fr.Element
+}
+
+// Verify verifies a KZG opening proof at a single point
+func Verify(commitment *Digest, proof *OpeningProof, point fr.Element, srs *SRS) error {
+	// [f(a)]G₁
+	var claimedValueG1Aff bls24315.G1Jac
+	claimedValueG1Aff.FromAffine(&proof.ClaimedDigest)
+
+	// [f(α) - f(a)]G₁
+	var fminusfaG1Jac bls24315.G1Jac
+	fminusfaG1Jac.FromAffine(commitment)
+	fminusfaG1Jac.SubAssign(&claimedValueG1Aff)
+
+	// [-H(α)]G₁
+	var negH bls24315.G1Affine
+	negH.Neg(&proof.H)
+
+	// [α-a]G₂
+	var alphaMinusaG2Jac, genG2Jac, alphaG2Jac bls24315.G2Jac
+	var pointBigInt big.Int
+	point.ToBigIntRegular(&pointBigInt)
+	genG2Jac.FromAffine(&srs.G2[0])
+	alphaG2Jac.FromAffine(&srs.G2[1])
+	alphaMinusaG2Jac.ScalarMultiplication(&genG2Jac, &pointBigInt).
+		Neg(&alphaMinusaG2Jac).
+		AddAssign(&alphaG2Jac)
+
+	// [α-a]G₂
+	var xminusaG2Aff bls24315.G2Affine
+	xminusaG2Aff.FromJacobian(&alphaMinusaG2Jac)
+
+	// [f(α) - f(a)]G₁
+	var fminusfaG1Aff bls24315.G1Affine
+	fminusfaG1Aff.FromJacobian(&fminusfaG1Jac)
+
+	// e([f(α) - f(a)]G₁, G₂).e([-H(α)]G₁, [α-a]G₂) ==? 1
+	check, err := bls24315.PairingCheck(
+		[]bls24315.G1Affine{fminusfaG1Aff, negH},
+		[]bls24315.G2Affine{srs.G2[0], xminusaG2Aff},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrVerifyOpeningProof
+	}
+	return nil
+}
+
+// VerifyClaimedValues checks that claimedDigest = ∑ᵢvalues[i][Lᵢ(τ₀)]G₁, values[i] being the value
+// of the party i, as in OpeningProof.ClaimedValues. Verify only checks claimedDigest.
+func VerifyClaimedValues(claimedDigest *Digest, values []fr.Element, srs *SRS) error {
+	if len(values) != srs.WorldSize || len(srs.Lagrange) < len(values) {
+		return ErrInvalidNbDigests
+	}
+	var digest Digest
+	if _, err := digest.MultiExp(srs.Lagrange[:len(values)], values, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+	if !digest.Equal(claimedDigest) {
+		return ErrVerifyClaimedValues
+	}
+	return nil
+}
+
+// BivariateOpeningProof opening proof of F(X, Y) at a point (x, y)
+//
+// implements io.ReaderFrom and io.WriterTo
+type BivariateOpeningProof struct {
+	// HY quotient polynomial (F(X, Y) - F(X, y)) / (Y - y)
+	HY bls24315.G1Affine
+
+	// ClaimedDigest digest of F(X, y)
+	ClaimedDigest bls24315.G1Affine
+
+	// HX quotient polynomial (F(X, y) - F(x, y)) / (X - x)
+	HX bls24315.G1Affine
+
+	// ClaimedValue purported value F(x, y)
+	ClaimedValue fr.Element
+}
+
+/*
+OpenBivariate computes an opening proof of F(X, Y) at (x, y).
+
+The first level is the opening of F(X, Y) at Y = y, see Open.
+The root node then opens F'(X) = F(X, y) = \sum_{i=0}^{M-1} f_i(y) * L_i(X) at X = x.
+The quotient h'(X) = (F'(X) - F'(x)) / (X - x) is computed in Lagrange form on the party domain,
+and committed using the g^{L_i(\tau[0])} of srs.Lagrange.
+
+Only the root node returns the proof, the other nodes return an empty proof.
+*/
+func OpenBivariate(p []fr.Element, x, y fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (BivariateOpeningProof, error) {
+	proofY, fY, err := Open(p, y, srs, c, nbTasks...)
+	if err != nil {
+		return BivariateOpeningProof{}, err
+	}
+
+	if c.Rank() != 0 {
+		return BivariateOpeningProof{}, nil
+	}
+
+	// Root node
+	domain := srs.PartyDomain()
+	if len(srs.Lagrange) != int(domain.Cardinality) {
+		return BivariateOpeningProof{}, ErrInvalidPartyDomain
+	}
+
+	// the padded parties hold fᵢ = 0
+	fYPadded := make([]fr.Element, domain.Cardinality)
+	copy(fYPadded, fY)
+	claimedValue, h := divideLagrangeByXminusA(fYPadded, x, domain)
+
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	if len(nbTasks) > 0 {
+		config.NbTasks = nbTasks[0]
+	}
+	var comH bls24315.G1Affine
+	if _, err := comH.MultiExp(srs.Lagrange, h, config); err != nil {
+		return BivariateOpeningProof{}, err
+	}
+
+	return BivariateOpeningProof{
+		HY:            proofY.H,
+		ClaimedDigest: proofY.ClaimedDigest,
+		HX:            comH,
+		ClaimedValue:  claimedValue,
+	}, nil
+}
+
+// divideLagrangeByXminusA returns f(a) and (f-f(a))/(x-a), f and the result being given by their
+// values on the points of domain, padding included.
+func divideLagrangeByXminusA(f []fr.Element, a fr.Element, domain *PartyDomain) (fr.Element, []fr.Element) {
+	n := len(f)
+	points := domain.Points()
+
+	// a - xᵢ, and M.gᴹ inverted in the same batch
+	za, mgm := domain.vanishing(a)
+	aMinusPoints := make([]fr.Element, n+1)
+	k := -1
+	for i := 0; i < n; i++ {
+		aMinusPoints[i].Sub(&a, &points[i])
+		if aMinusPoints[i].IsZero() {
+			k = i
+		}
+	}
+	aMinusPoints[n] = mgm
+	aMinusPointsInv := fr.BatchInvert(aMinusPoints)
+
+	var fa fr.Element
+	if k == -1 {
+		// f(a) = (aᴹ - gᴹ) / (M.gᴹ) * ∑ᵢxᵢfᵢ/(a - xᵢ)
+		var t fr.Element
+		for i := 0; i < n; i++ {
+			t.Mul(&points[i], &aMinusPointsInv[i]).Mul(&t, &f[i])
+			fa.Add(&fa, &t)
+		}
+		fa.Mul(&fa, &za).Mul(&fa, &aMinusPointsInv[n])
+	} else {
+		fa.Set(&f[k])
+	}
+
+	// hᵢ = (fᵢ - f(a)) / (xᵢ - a) when xᵢ ≠ a
+	h := make([]fr.Element, n)
+	for i := 0; i < n; i++ {
+		if i == k {
+			continue
+		}
+		h[i].Sub(&fa, &f[i]).Mul(&h[i], &aMinusPointsInv[i])
+	}
+
+	// if a = xₖ, hₖ = -∑_{i≠k}(xᵢ/xₖ)hᵢ = -∑_{i≠k}ωⁱ⁻ᵏhᵢ
+	if k != -1 {
+		var t fr.Element
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			t.Mul(&points[(i-k+n)%n], &h[i])
+			h[k].Sub(&h[k], &t)
+		}
+		if domain.Coset {
+			// points[j] = g.ωʲ
+			h[k].Mul(&h[k], &domain.FrMultiplicativeGenInv)
+		}
+	}
+
+	return fa, h
+}
+
+// VerifyBivariate verifies an opening proof of F(X, Y) at (x, y)
+func VerifyBivariate(commitment *Digest, proof *BivariateOpeningProof, x, y fr.Element, srs *SRS) error {
+	// first level, F(\tau[0], \tau[1]) -> F(\tau[0], y):
+	// e([F(τ₀, τ₁) - F(τ₀, y) + yH_Y]G₁, G₂).e([-H_Y]G₁, [τ₁]G₂) ==? 1
+	// second level, F(\tau[0], y) -> F(x, y):
+	// e([F(τ₀, y) - F(x, y) + xH_X]G₁, G₂).e([-H_X]G₁, [τ₀]G₂) ==? 1
+	// both checks are folded using a random λ
+
+	_, _, gen1Aff, _ := bls24315.Generators()
+
+	var lambda fr.Element
+	if _, err := lambda.SetRandom(); err != nil {
+		return err
+	}
+	var yBigInt, xBigInt, valueBigInt, lambdaBigInt big.Int
+	y.ToBigIntRegular(&yBigInt)
+	x.ToBigIntRegular(&xBigInt)
+	proof.ClaimedValue.ToBigIntRegular(&valueBigInt)
+	lambda.ToBigIntRegular(&lambdaBigInt)
+
+	// [F(τ₀, τ₁) - F(τ₀, y) + yH_Y]G₁
+	var first, tmp bls24315.G1Jac
+	first.FromAffine(commitment)
+	tmp.FromAffine(&proof.ClaimedDigest)
+	first.SubAssign(&tmp)
+	tmp.FromAffine(&proof.HY)
+	tmp.ScalarMultiplication(&tmp, &yBigInt)
+	first.AddAssign(&tmp)
+
+	// [F(τ₀, y) - F(x, y) + xH_X]G₁
+	var second bls24315.G1Jac
+	second.FromAffine(&proof.ClaimedDigest)
+	tmp.FromAffine(&gen1Aff)
+	tmp.ScalarMultiplication(&tmp, &valueBigInt)
+	second.SubAssign(&tmp)
+	tmp.FromAffine(&proof.HX)
+	tmp.ScalarMultiplication(&tmp, &xBigInt)
+	second.AddAssign(&tmp)
+
+	// first + λ.second
+	second.ScalarMultiplication(&second, &lambdaBigInt)
+	first.AddAssign(&second)
+	var foldedAff bls24315.G1Affine
+	foldedAff.FromJacobian(&first)
+
+	// [-H_Y]G₁, [-λH_X]G₁
+	var negHY, negLambdaHX bls24315.G1Affine
+	negHY.Neg(&proof.HY)
+	negLambdaHX.ScalarMultiplication(&proof.HX, &lambdaBigInt).Neg(&negLambdaHX)
+
+	check, err := bls24315.PairingCheck(
+		[]bls24315.G1Affine{foldedAff, negHY, negLambdaHX},
+		[]bls24315.G2Affine{srs.G2[0], srs.G2[1], srs.G2[2]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrVerifyOpeningProof
+	}
+	return nil
+}
+
+// BatchOpenSinglePoint creates a batch opening proof at point of a list of polynomials.
+// It's an interactive protocol, made non interactive using Fiat Shamir.
+//
+// * point is the point at which the polynomials are opened.
+// * digests is the list of committed polynomials to open, need to derive the challenge using Fiat Shamir.
+// * polynomials is the list of polynomials to open, they are supposed to be of the same size.
+func BatchOpenSinglePoint(polynomials [][]fr.Element, digests []Digest, point fr.Element, hf hash.Hash, srs *SRS, c communicator.Communicator) (BatchOpeningProof, [][]fr.Element, error) {
+	// check for invalid sizes
+	nbDigests := len(digests)
+	if nbDigests != len(polynomials) {
+		return BatchOpeningProof{}, nil, ErrInvalidNbDigests
+	}
+
+	// TODO ensure the polynomials are of the same size
+	largestPoly := -1
+	for _, p := range polynomials {
+		if len(p) == 0 || len(p) > len(srs.G1) {
+			return BatchOpeningProof{}, nil, ErrInvalidPolynomialSize
+		}
+		if len(p) > largestPoly {
+			largestPoly = len(p)
+		}
+	}
+
+	// compute the purported values
+	claimedValues := make([]fr.Element, len(polynomials))
+	claimedDigests := make([]bls24315.G1Affine, len(polynomials))
+	for i := 0; i < len(polynomials); i++ {
+		claimedValues[i] = eval(polynomials[i], point)
+		var claimedValueBigInt big.Int
+		claimedValues[i].ToBigIntRegular(&claimedValueBigInt)
+		claimedDigests[i].ScalarMultiplication(&srs.G1[0], &claimedValueBigInt)
+	}
+	// derive the challenge γ, binded to the point and the commitments
+	gamma, err := deriveGamma(point, digests, hf, c)
+	if err != nil {
+		return BatchOpeningProof{}, nil, err
+	}
+
+	// ∑ᵢγⁱf(a)
+	var fY fr.Element
+	// wait for polynomial evaluations to be completed (res.ClaimedValues)
+	fY = claimedValues[nbDigests-1]
+	for i := nbDigests - 2; i >= 0; i-- {
+		fY.Mul(&fY, &gamma).
+			Add(&fY, &claimedValues[i])
+	}
+
+	// compute ∑ᵢγⁱfᵢ
+	// note: if we are willing to paralellize that, we could clone the poly and scale them by
+	// gamma n in parallel, before reducing into foldedPolynomials
+	foldedPolynomials := make([]fr.Element, largestPoly)
+	copy(foldedPolynomials, polynomials[0])
+	acc := gamma
+	for i := 1; i < len(polynomials); i++ {
+		parallel.Execute(len(polynomials[i]), func(start, end int) {
+			var pj fr.Element
+			for j := start; j < end; j++ {
+				pj.Mul(&polynomials[i][j], &acc)
+				foldedPolynomials[j].Add(&foldedPolynomials[j], &pj)
+			}
+		})
+		acc.Mul(&acc, &gamma)
+	}
+
+	// compute H
+	h := dividePolyByXminusA(foldedPolynomials, fY, point)
+	foldedPolynomials = nil // same memory as h
+
+	comH, err := Commit(h, srs, c)
+	if err != nil {
+		return BatchOpeningProof{}, nil, err
+	}
+
+	// Send the claimed values and digests of the node to the root node, in a single frame
+	frame := (&batchOpeningMessage{
+		ClaimedValues:  claimedValues,
+		ClaimedDigests: claimedDigests,
+	}).marshal()
+	frames, err := c.Gather(frame, 0)
+	if err != nil {
+		return BatchOpeningProof{}, nil, err
+	}
+	if c.Rank() != 0 {
+		return BatchOpeningProof{}, nil, nil
+	}
+
+	// Root node
+	allClaimedValues := make([][]fr.Element, nbDigests)
+	for k := 0; k < nbDigests; k++ {
+		allClaimedValues[k] = make([]fr.Element, c.Size())
+	}
+	claimedDigestsJac := make([]bls24315.G1Jac, nbDigests)
+	for i := 0; i < c.Size(); i++ {
+		var msg batchOpeningMessage
+		if err := msg.unmarshal(frames[i]); err != nil {
+			return BatchOpeningProof{}, nil, err
+		}
+		if len(msg.ClaimedValues) != nbDigests {
+			return BatchOpeningProof{}, nil, ErrInvalidMessage
+		}
+		for k := 0; k < nbDigests; k++ {
+			allClaimedValues[k][i] = msg.ClaimedValues[k]
+			claimedDigestsJac[k].AddMixed(&msg.ClaimedDigests[k])
+		}
+	}
+	for k := 0; k < nbDigests; k++ {
+		claimedDigests[k].FromJacobian(&claimedDigestsJac[k])
+	}
+
+	return BatchOpeningProof{
+		H:              comH,
+		ClaimedDigests: claimedDigests,
+		ClaimedValues:  allClaimedValues,
+	}, allClaimedValues, nil
+}
+
+// FoldProof fold the digests and the proofs in batchOpeningProof using Fiat Shamir
+// to obtain an opening proof at a single point.
+//
+// * digests list of digests on which batchOpeningProof is based
+// * batchOpeningProof opening proof of digests
+// * returns the folded version of batchOpeningProof, Digest, the folded version of digests
+func FoldProof(digests []Digest, batchOpeningProof *BatchOpeningProof, point fr.Element, hf hash.Hash) (OpeningProof, Digest, error) {
+	nbDigests := len(digests)
+
+	// check consistancy between numbers of claims vs number of digests
+	if nbDigests != len(batchOpeningProof.ClaimedDigests) {
+		return OpeningProof{}, Digest{}, ErrInvalidNbDigests
+	}
+
+	// derive the challenge γ, binded to the point and the commitments
+	gamma, err := computeGamma(point, digests, hf)
+	if err != nil {
+		return OpeningProof{}, Digest{}, ErrInvalidNbDigests
+	}
+
+	// fold the claimed values and digests
+	// gammai = [1,γ,γ²,..,γⁿ⁻¹]
+	gammai := make([]fr.Element, nbDigests)
+	gammai[0].SetOne()
+	for i := 1; i < nbDigests; i++ {
+		gammai[i].Mul(&gammai[i-1], &gamma)
+	}
+
+	foldedDigests, foldedEvaluations, err := fold(digests, batchOpeningProof.ClaimedDigests, gammai)
+	if err != nil {
+		return OpeningProof{}, Digest{}, err
+	}
+
+	// create the folded opening proof
+	var res OpeningProof
+	res.ClaimedDigest.Set(&foldedEvaluations)
+	res.H.Set(&batchOpeningProof.H)
+
+	// fold the values of each party, if any
+	if len(batchOpeningProof.ClaimedValues) != 0 {
+		if len(batchOpeningProof.ClaimedValues) != nbDigests {
+			return OpeningProof{}, Digest{}, ErrInvalidNbDigests
+		}
+		worldSize := len(batchOpeningProof.ClaimedValues[0])
+		res.ClaimedValues = make([]fr.Element, worldSize)
+		var t fr.Element
+		for k := 0; k < nbDigests; k++ {
+			if len(batchOpeningProof.ClaimedValues[k]) != worldSize {
+				return OpeningProof{}, Digest{}, ErrInvalidNbDigests
+			}
+			for i := 0; i < worldSize; i++ {
+				t.Mul(&batchOpeningProof.ClaimedValues[k][i], &gammai[k])
+				res.ClaimedValues[i].Add(&res.ClaimedValues[i], &t)
+			}
+		}
+	}
+
+	return res, foldedDigests, nil
+}
+
+// BatchVerifySinglePoint verifies a batched opening proof at a single point of a list of polynomials.
+//
+// * digests list of digests on which opening proof is done
+// * batchOpeningProof proof of correct opening on the digests
+func BatchVerifySinglePoint(digests []Digest, batchOpeningProof *BatchOpeningProof, point fr.Element, hf hash.Hash, srs *SRS) error {
+	// fold the proof
+	foldedProof, foldedDigest, err := FoldProof(digests, batchOpeningProof, point, hf)
+	if err != nil {
+		return err
+	}
+
+	// verify the foldedProof againts the foldedDigest
+	err = Verify(&foldedDigest, &foldedProof, point, srs)
+	return err
+}
+
+// BatchVerifyMultiPoints batch verifies a list of opening proofs at different points.
+// The purpose of the batching is to have only one pairing for verifying several proofs.
+//
+// * digests list of committed polynomials
+// * proofs list of opening proofs, one for each digest
+// * points the list of points at which the opening are done
+func BatchVerifyMultiPoints(digests []Digest, proofs []OpeningProof, points []fr.Element, srs *SRS) error {
+	// check consistancy nb proogs vs nb digests
+	if len(digests) != len(proofs) || len(digests) != len(points) {
+		return ErrInvalidNbDigests
+	}
+
+	// if only one digest, call Verify
+	if len(digests) == 1 {
+		return Verify(&digests[0], &proofs[0], points[0], srs)
+	}
+
+	// sample random numbers λᵢ for sampling
+	randomNumbers := make([]fr.Element, len(digests))
+	randomNumbers[0].SetOne()
+	for i := 1; i < len(randomNumbers); i++ {
+		_, err := randomNumbers[i].SetRandom()
+		if err != nil {
+			return err
+		}
+	}
+
+	// fold the committed quotients compute ∑ᵢλᵢ[Hᵢ(α)]G₁
+	var foldedQuotients bls24315.G1Affine
+	quotients := make([]bls24315.G1Affine, len(proofs))
+	for i := 0; i < len(randomNumbers); i++ {
+		quotients[i].Set(&proofs[i].H)
+	}
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	_, err := foldedQuotients.MultiExp(quotients, randomNumbers, config)
+	if err != nil {
+		return nil
+	}
+
+	// fold digests and evalCommits
+	evalCommits := make([]bls24315.G1Affine, len(digests))
+	for i := 0; i < len(randomNumbers); i++ {
+		evalCommits[i].Set(&proofs[i].ClaimedDigest)
+	}
+
+	// fold the digests: ∑ᵢλᵢ[f_i(α)]G₁
+	// fold the evals  : ∑ᵢλᵢfᵢ(aᵢ)
+	foldedDigests, foldedEvalsCommit, err := fold(digests, evalCommits, randomNumbers)
+	if err != nil {
+		return err
+	}
+
+	// compute foldedDigests = ∑ᵢλᵢ[fᵢ(α)]G₁ - [∑ᵢλᵢfᵢ(aᵢ)]G₁
+	foldedDigests.Sub(&foldedDigests, &foldedEvalsCommit)
+
+	// combien the points and the quotients using γᵢ
+	// ∑ᵢλᵢ[p_i]([Hᵢ(α)]G₁)
+	var foldedPointsQuotients bls24315.G1Affine
+	for i := 0; i < len(randomNumbers); i++ {
+		randomNumbers[i].Mul(&randomNumbers[i], &points[i])
+	}
+	_, err = foldedPointsQuotients.MultiExp(quotients, randomNumbers, config)
+	if err != nil {
+		return err
+	}
+
+	// ∑ᵢλᵢ[f_i(α)]G₁ - [∑ᵢλᵢfᵢ(aᵢ)]G₁ + ∑ᵢλᵢ[p_i]([Hᵢ(α)]G₁)
+	// = [∑ᵢλᵢf_i(α) - ∑ᵢλᵢfᵢ(aᵢ) + ∑ᵢλᵢpᵢHᵢ(α)]G₁
+	foldedDigests.Add(&foldedDigests, &foldedPointsQuotients)
+
+	// -∑ᵢλᵢ[Qᵢ(α)]G₁
+	foldedQuotients.Neg(&foldedQuotients)
+
+	// pairing check
+	// e([∑ᵢλᵢ(fᵢ(α) - fᵢ(pᵢ) + pᵢHᵢ(α))]G₁, G₂).e([-∑ᵢλᵢ[Hᵢ(α)]G₁), [α]G₂)
+	check, err := bls24315.PairingCheck(
+		[]bls24315.G1Affine{foldedDigests, foldedQuotients},
+		[]bls24315.G2Affine{srs.G2[0], srs.G2[1]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrVerifyOpeningProof
+	}
+	return nil
+
+}
+
+// fold folds digests and evaluations using the list of factors as random numbers.
+//
+// * digests list of digests to fold
+// * evaluations list of evaluations to fold
+// * factors list of multiplicative factors used for the folding (in Montgomery form)
+//
+// * Returns ∑ᵢcᵢdᵢ, ∑ᵢcᵢf(aᵢ)
+func fold(di []Digest, fai []bls24315.G1Affine, ci []fr.Element) (Digest, Digest, error) {
+	// fold the claimed values ∑ᵢcᵢf(aᵢ)
+	var foldedEvaluations bls24315.G1Affine
+	foldedEvaluations.MultiExp(fai, ci, ecc.MultiExpConfig{ScalarsMont: true})
+
+	// fold the digests ∑ᵢ[cᵢ]([fᵢ(α)]G₁)
+	var foldedDigests Digest
+	_, err := foldedDigests.MultiExp(di, ci, ecc.MultiExpConfig{ScalarsMont: true})
+	if err != nil {
+		return foldedDigests, foldedEvaluations, err
+	}
+
+	// folding done
+	return foldedDigests, foldedEvaluations, nil
+
+}
+
+// computeGamma derives a challenge using Fiat Shamir to fold proofs.
+func computeGamma(point fr.Element, digests []Digest, hf hash.Hash) (fr.Element, error) {
+	// derive the challenge gamma, binded to the point and the commitments
+	fs := fiatshamir.NewTranscript(hf, "gamma")
+	if err := fs.Bind("gamma", point.Marshal()); err != nil {
+		return fr.Element{}, err
+	}
+	for i := 0; i < len(digests); i++ {
+		if err := fs.Bind("gamma", digests[i].Marshal()); err != nil {
+			return fr.Element{}, err
+		}
+	}
+	gammaByte, err := fs.ComputeChallenge("gamma")
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var gamma fr.Element
+	gamma.SetBytes(gammaByte)
+	return gamma, nil
+}
+
+// deriveGamma derives the challenge on the root node, and sends it to all the nodes.
+func deriveGamma(point fr.Element, digests []Digest, hf hash.Hash, c communicator.Communicator) (fr.Element, error) {
+	var gamma fr.Element
+	if c.Rank() == 0 {
+		var err error
+		if gamma, err = computeGamma(point, digests, hf); err != nil {
+			return fr.Element{}, err
+		}
+	}
+	if err := broadcastElement(&gamma, c); err != nil {
+		return fr.Element{}, err
+	}
+	return gamma, nil
+}
+
+// broadcastElement sends e from the root node to all the nodes
+func broadcastElement(e *fr.Element, c communicator.Communicator) error {
+	buf := e.Bytes()
+	if err := c.Broadcast(buf[:], 0); err != nil {
+		return err
+	}
+	e.SetBytes(buf[:])
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+)
+
+var (
+	ErrNoHidingGenerator = errors.New("dkzg: the SRS has no hiding generator")
+)
+
+// NewHidingSRS is NewSRS, with the hiding generators of the party c.Rank() in srs.H and
+// the ones of all the parties in srs.HLagrange, h being an independent randomness source.
+//
+// In production, a SRS generated through MPC should be used.
+func NewHidingSRS(size uint64, tau []*big.Int, h *big.Int, domain *PartyDomain, c communicator.Communicator) (*SRS, error) {
+	if domain == nil {
+		domain = NewPartyDomain(c.Size(), false)
+	}
+	srs, err := NewSRS(size, tau, domain, c)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, gen1Aff, _ := bls24315.Generators()
+	var tau0, tau1, _h fr.Element
+	tau0.SetBigInt(tau[0])
+	tau1.SetBigInt(tau[1])
+	_h.SetBigInt(h)
+
+	// h * L_k(\tau[0]) for all the points of the party domain
+	hLagTau0 := domain.LagrangeAt(tau0)
+	for i := range hLagTau0 {
+		hLagTau0[i].Mul(&hLagTau0[i], &_h)
+	}
+	var hAlpha fr.Element
+	hAlpha.Mul(&hLagTau0[c.Rank()], &tau1)
+	var bHAlpha big.Int
+	hAlpha.ToBigIntRegular(&bHAlpha)
+	srs.H[1].ScalarMultiplication(&gen1Aff, &bHAlpha)
+
+	for i := range hLagTau0 {
+		hLagTau0[i].FromMont()
+	}
+	srs.HLagrange = bls24315.BatchScalarMultiplicationG1(&gen1Aff, hLagTau0)
+	srs.H[0] = srs.HLagrange[c.Rank()]
+
+	return srs, nil
+}
+
+// Blinding random polynomial ρ₀ + ρ₁Y hiding the share of a party, as returned by HidingCommit.
+// It must be kept secret by the party, and is needed to open the commitment.
+type Blinding [2]fr.Element
+
+// eval returns ρ₀ + ρ₁.point
+func (b *Blinding) eval(point fr.Element) fr.Element {
+	var res fr.Element
+	res.Mul(&b[1], &point).Add(&res, &b[0])
+	return res
+}
+
+// HidingOpeningProof opening proof of a hiding commitment at Y = y
+//
+// implements io.ReaderFrom and io.WriterTo
+type HidingOpeningProof struct {
+	// H quotient polynomial (F(X, Y) - F(X, y)) / (Y - y), blinded by ∑ᵢρᵢ₁[h * Lᵢ(τ₀)]G₁
+	H bls24315.G1Affine
+
+	// ClaimedDigest purported digest of the values and of the blindings
+	ClaimedDigest bls24315.G1Affine
+
+	// ClaimedValues purported values f_i(y), ClaimedValues[i] is the value of the party i
+	ClaimedValues []fr.Element
+
+	// ClaimedBlindings purported values r_i(y) of the blinding polynomials of the parties
+	ClaimedBlindings []fr.Element
+}
+
+/*
+HidingCommit is Commit, each party blinding its own share with a random polynomial rᵢ = ρᵢ₀ + ρᵢ₁Y:
+
+	Cᵢ = [fᵢ(τ₁)Lᵢ(τ₀)]G₁ + ρᵢ₀[h * Lᵢ(τ₀)]G₁ + ρᵢ₁[h * Lᵢ(τ₀) * τ₁]G₁
+
+so that the commitment is a commitment to F + hR, R(X, Y) = ∑ᵢrᵢ(Y)Lᵢ(X). The commitment is perfectly hiding, and
+computationally binding. It must be opened at a single point: the openings at two points reveal the blindings.
+
+Only the root node returns the commitment, each node returns its own blinding.
+*/
+func HidingCommit(p []fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (Digest, Blinding, error) {
+	if srs.H[0].IsInfinity() {
+		return Digest{}, Blinding{}, ErrNoHidingGenerator
+	}
+
+	var blinding Blinding
+	for i := range blinding {
+		if _, err := blinding[i].SetRandom(); err != nil {
+			return Digest{}, Blinding{}, err
+		}
+	}
+
+	subCom, err := commitShare(p, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, Blinding{}, err
+	}
+	var blind bls24315.G1Affine
+	if _, err := blind.MultiExp(srs.H[:], blinding[:], ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return Digest{}, Blinding{}, err
+	}
+	subCom.Add(&subCom, &blind)
+
+	subComBytes := G1AffineToBytes(subCom)
+	if err := c.Reduce(subComBytes, 0, addG1); err != nil {
+		return Digest{}, Blinding{}, err
+	}
+	if c.Rank() != 0 {
+		return Digest{}, blinding, nil
+	}
+	return BytesToG1Affine(subComBytes), blinding, nil
+}
+
+// HidingOpen is Open for a polynomial committed with HidingCommit.
+//
+// (rᵢ(Y) - rᵢ(y))/(Y - y) = ρᵢ₁, so each party blinds its share of the quotient by ρᵢ₁[h * Lᵢ(τ₀)]G₁.
+// Only the root node returns the proof.
+func HidingOpen(p []fr.Element, blinding Blinding, y fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (HidingOpeningProof, error) {
+	if srs.H[0].IsInfinity() {
+		return HidingOpeningProof{}, ErrNoHidingGenerator
+	}
+	if len(p) == 0 || len(p) > len(srs.G1) {
+		return HidingOpeningProof{}, ErrInvalidPolynomialSize
+	}
+
+	// compute f_i(y), r_i(y)
+	fY := eval(p, y)
+	rY := blinding.eval(y)
+
+	// compute H
+	_p := make([]fr.Element, len(p))
+	copy(_p, p)
+	h := dividePolyByXminusA(_p, fY, y)
+
+	subH, err := commitShare(h, srs, nbTasks...)
+	if err != nil {
+		return HidingOpeningProof{}, err
+	}
+	var rho1 big.Int
+	blinding[1].ToBigIntRegular(&rho1)
+	var blind bls24315.G1Affine
+	blind.ScalarMultiplication(&srs.H[0], &rho1)
+	subH.Add(&subH, &blind)
+
+	subHBytes := G1AffineToBytes(subH)
+	if err := c.Reduce(subHBytes, 0, addG1); err != nil {
+		return HidingOpeningProof{}, err
+	}
+	frames, err := c.Gather(elementsToBytes([]fr.Element{fY, rY}), 0)
+	if err != nil {
+		return HidingOpeningProof{}, err
+	}
+	if c.Rank() != 0 {
+		return HidingOpeningProof{}, nil
+	}
+
+	// Root node
+	proof := HidingOpeningProof{
+		H:                BytesToG1Affine(subHBytes),
+		ClaimedValues:    make([]fr.Element, c.Size()),
+		ClaimedBlindings: make([]fr.Element, c.Size()),
+	}
+	var values [2]fr.Element
+	for i, frame := range frames {
+		if len(frame) != 2*fr.Bytes {
+			return HidingOpeningProof{}, ErrInvalidMessage
+		}
+		bytesToElements(values[:], frame)
+		proof.ClaimedValues[i], proof.ClaimedBlindings[i] = values[0], values[1]
+	}
+	proof.ClaimedDigest, err = hidingDigest(proof.ClaimedValues, proof.ClaimedBlindings, srs)
+	if err != nil {
+		return HidingOpeningProof{}, err
+	}
+
+	return proof, nil
+}
+
+// hidingDigest returns ∑ᵢvalues[i][Lᵢ(τ₀)]G₁ + blindings[i][h * Lᵢ(τ₀)]G₁
+func hidingDigest(values, blindings []fr.Element, srs *SRS) (Digest, error) {
+	n := srs.WorldSize
+	if len(values) != n || len(blindings) != n || len(srs.Lagrange) < n || len(srs.HLagrange) < n {
+		return Digest{}, ErrInvalidNbDigests
+	}
+	points := make([]bls24315.G1Affine, 0, 2*n)
+	points = append(points, srs.Lagrange[:n]...)
+	points = append(points, srs.HLagrange[:n]...)
+	scalars := make([]fr.Element, 0, 2*n)
+	scalars = append(scalars, values...)
+	scalars = append(scalars, blindings...)
+
+	var res Digest
+	if _, err := res.MultiExp(points, scalars, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return Digest{}, err
+	}
+	return res, nil
+}
+
+// HidingVerify verifies an opening proof of a hiding commitment at Y = point, that is the pairing
+// check of Verify, and the consistency of the claimed values and blindings with the claimed digest.
+func HidingVerify(commitment *Digest, proof *HidingOpeningProof, point fr.Element, srs *SRS) error {
+	if srs.H[0].IsInfinity() {
+		return ErrNoHidingGenerator
+	}
+
+	digest, err := hidingDigest(proof.ClaimedValues, proof.ClaimedBlindings, srs)
+	if err != nil {
+		return err
+	}
+	if !digest.Equal(&proof.ClaimedDigest) {
+		return ErrVerifyClaimedValues
+	}
+
+	return Verify(commitment, &OpeningProof{H: proof.H, ClaimedDigest: proof.ClaimedDigest}, point, srs)
+}
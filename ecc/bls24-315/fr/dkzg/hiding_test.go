@@ -0,0 +1,117 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+)
+
+func TestHidingVerifySinglePoint(t *testing.T) {
+	runParties(t, func(c communicator.Communicator) error {
+		srs, err := NewHidingSRS(64, []*big.Int{big.NewInt(42), big.NewInt(27)}, big.NewInt(7), nil, c)
+		if err != nil {
+			return err
+		}
+		f := polynomial(60, uint64(c.Rank()))
+
+		// the blinded commitment differs from the plain one
+		digest, blinding, err := HidingCommit(f, srs, c)
+		if err != nil {
+			return err
+		}
+		plain, err := Commit(f, srs, c)
+		if err != nil {
+			return err
+		}
+		if c.Rank() == 0 && digest.Equal(&plain) {
+			t.Error("the hiding commitment should be blinded")
+		}
+
+		var point fr.Element
+		point.SetString("4321")
+		proof, err := HidingOpen(f, blinding, point, srs, c)
+		if err != nil || c.Rank() != 0 {
+			return err
+		}
+
+		// verify correct proof
+		if err := HidingVerify(&digest, &proof, point, srs); err != nil {
+			return err
+		}
+		expected := eval(f, point)
+		if !proof.ClaimedValues[0].Equal(&expected) {
+			t.Error("wrong claimed value of the root node")
+		}
+
+		// serialization
+		var buf bytes.Buffer
+		if _, err := proof.WriteTo(&buf); err != nil {
+			return err
+		}
+		var _proof HidingOpeningProof
+		if _, err := _proof.ReadFrom(&buf); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(proof, _proof) {
+			t.Error("hiding opening proof serialization failed")
+		}
+
+		// verify wrong proofs
+		proof.ClaimedValues[1].Double(&proof.ClaimedValues[1])
+		if err := HidingVerify(&digest, &proof, point, srs); err != ErrVerifyClaimedValues {
+			t.Error("verifying a wrong claimed value should have failed")
+		}
+		proof.ClaimedValues[1] = _proof.ClaimedValues[1]
+		if err := HidingVerify(&plain, &proof, point, srs); err != ErrVerifyOpeningProof {
+			t.Error("verifying against the plain commitment should have failed")
+		}
+
+		// the SRS must be hiding
+		if _, _, err := HidingCommit(f, testSRS[0], communicator.NewChannels(1)[0]); err != ErrNoHidingGenerator {
+			t.Error("committing with a SRS without hiding generator should have failed")
+		}
+		return nil
+	})
+}
+
+func TestSerializationHidingSRS(t *testing.T) {
+	runWorld(t, 3, func(c communicator.Communicator) error {
+		srs, err := NewHidingSRS(32, []*big.Int{big.NewInt(42), big.NewInt(27)}, big.NewInt(7), nil, c)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		if _, err := srs.WriteTo(&buf); err != nil {
+			return err
+		}
+		var _srs SRS
+		if _, err := _srs.ReadFrom(&buf); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(srs, &_srs) {
+			t.Error("hiding SRS serialization failed")
+		}
+		return nil
+	})
+}
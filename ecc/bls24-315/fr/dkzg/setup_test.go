@@ -0,0 +1,163 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315"
+)
+
+func TestShards(t *testing.T) {
+	const size = 32
+	tau := []*big.Int{big.NewInt(42), big.NewInt(27)}
+
+	pt, err := NewPowersOfTau(nbParties, size, tau)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, coset := range []bool{false, true} {
+		domain := NewPartyDomain(nbParties, coset)
+		shards, err := pt.Shards(domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shards) != nbParties {
+			t.Fatal("there should be one shard per party")
+		}
+
+		// the shards are the SRS generated from the toxic waste
+		comms := communicator.NewChannels(nbParties)
+		for i := 0; i < nbParties; i++ {
+			srs, err := NewSRS(size, tau, domain, comms[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(srs, shards[i]) {
+				t.Fatalf("coset=%v: shard %d is not the SRS of the party", coset, i)
+			}
+			if err := VerifyShard(shards[i], i, pt); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// wrong rank
+		if err := VerifyShard(shards[1], 2, pt); err == nil {
+			t.Fatal("verifying a shard with the wrong rank should have failed")
+		}
+
+		// tampered shard
+		tampered := *shards[1]
+		tampered.G1 = make([]Digest, size)
+		copy(tampered.G1, shards[1].G1)
+		tampered.G1[5].Add(&tampered.G1[5], &tampered.G1[5])
+		if err := VerifyShard(&tampered, 1, pt); err == nil {
+			t.Fatal("verifying a tampered shard should have failed")
+		}
+
+		// shard of the other party domain
+		tampered = *shards[1]
+		tampered.Coset = !coset
+		if err := VerifyShard(&tampered, 1, pt); err == nil {
+			t.Fatal("verifying a shard on the wrong party domain should have failed")
+		}
+	}
+
+	// tampered powers of tau, consistent with the shard
+	if err := pt.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tamper := range []func(*PowersOfTau){
+		func(pt *PowersOfTau) { pt.G1[0][1].Add(&pt.G1[0][1], &pt.G1[0][1]) },
+		func(pt *PowersOfTau) { pt.G1[nbParties-1][0].Add(&pt.G1[nbParties-1][0], &pt.G1[0][0]) },
+		func(pt *PowersOfTau) { pt.G2[1], pt.G2[2] = pt.G2[2], pt.G2[1] },
+		func(pt *PowersOfTau) { pt.G1[0][0].Add(&pt.G1[0][0], &pt.G1[0][0]) },
+	} {
+		tampered := PowersOfTau{G1: make([][]Digest, len(pt.G1)), G2: pt.G2}
+		for i := range pt.G1 {
+			tampered.G1[i] = append([]Digest{}, pt.G1[i]...)
+		}
+		tamper(&tampered)
+		if err := tampered.Verify(); err != ErrInvalidPowersOfTau {
+			t.Fatalf("expected %v, got %v", ErrInvalidPowersOfTau, err)
+		}
+	}
+
+	// the rows don't match the party domain
+	if _, err := pt.Shards(NewPartyDomain(2*nbParties, false)); err == nil {
+		t.Fatal("deriving shards for another number of parties should have failed")
+	}
+}
+
+func TestWriteShards(t *testing.T) {
+	pt, err := NewPowersOfTau(nbParties, 16, []*big.Int{big.NewInt(42), big.NewInt(27)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip of the powers of tau
+	var buf bytes.Buffer
+	written, err := pt.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _pt PowersOfTau
+	read, err := _pt.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != read || !reflect.DeepEqual(pt, &_pt) {
+		t.Fatal("powers of tau serialization failed")
+	}
+
+	// the number of rows is bounded before allocating
+	buf.Reset()
+	if _, err := pt.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	encoded := buf.Bytes()
+	binary.BigEndian.PutUint32(encoded[3*bls24315.SizeOfG2AffineCompressed:], maxDecodedLength+1)
+	var oversized PowersOfTau
+	if _, err := oversized.ReadFrom(bytes.NewReader(encoded)); err != ErrInvalidPowersOfTau {
+		t.Fatalf("expected %v, got %v", ErrInvalidPowersOfTau, err)
+	}
+
+	// one file per party
+	dir := t.TempDir()
+	domain := NewPartyDomain(nbParties, false)
+	if err := _pt.WriteShards(dir, domain); err != nil {
+		t.Fatal(err)
+	}
+	shards, err := pt.Shards(domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < nbParties; i++ {
+		srs, err := ReadShard(dir, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(srs, shards[i]) {
+			t.Fatalf("shard %d read from file differs", i)
+		}
+	}
+}
@@ -14,5 +14,5 @@
 
 // Code generated by consensys/gnark-crypto DO NOT EDIT
 
-// Package fft provides in-place discrete Fourier transform.
+// Package fft provides in-place discrete Fourier transform, of field elements, and of G1 and G2 points.
 package fft
@@ -0,0 +1,634 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package fft
+
+import (
+	"math/big"
+	"math/bits"
+	"runtime"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/internal/parallel"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+)
+
+// FFTG1 computes (recursively) the discrete Fourier transform of the points a, the twiddles being
+// the ones of the fr.Element FFT, and stores the result in a.
+// if decimation == DIT (decimation in time), the input must be in bit-reversed order
+// if decimation == DIF (decimation in frequency), the output will be in bit-reversed order
+// if coset if set, the FFT(a) returns the evaluation of a on a coset.
+func (domain *Domain) FFTG1(a []curve.G1Jac, decimation Decimation, coset ...bool) {
+
+	numCPU := uint64(runtime.NumCPU())
+
+	_coset := false
+	if len(coset) > 0 {
+		_coset = coset[0]
+	}
+
+	// if coset != 0, scale by coset table
+	if _coset {
+		if decimation == DIT {
+			scaleG1(a, domain.CosetTableReversed, nil)
+		} else {
+			scaleG1(a, domain.CosetTable, nil)
+		}
+	}
+
+	// find the stage where we should stop spawning go routines in our recursive calls
+	// (ie when we have as many go routines running as we have available CPUs)
+	maxSplits := bits.TrailingZeros64(ecc.NextPowerOfTwo(numCPU))
+	if numCPU <= 1 {
+		maxSplits = -1
+	}
+
+	switch decimation {
+	case DIF:
+		difFFTG1(a, domain.Twiddles, 0, maxSplits, nil)
+	case DIT:
+		ditFFTG1(a, domain.Twiddles, 0, maxSplits, nil)
+	default:
+		panic("not implemented")
+	}
+}
+
+// FFTInverseG1 computes (recursively) the inverse discrete Fourier transform of the points a and stores the result in a
+// if decimation == DIT (decimation in time), the input must be in bit-reversed order
+// if decimation == DIF (decimation in frequency), the output will be in bit-reversed order
+// coset sets the shift of the fft (0 = no shift, standard fft)
+func (domain *Domain) FFTInverseG1(a []curve.G1Jac, decimation Decimation, coset ...bool) {
+
+	numCPU := uint64(runtime.NumCPU())
+
+	_coset := false
+	if len(coset) > 0 {
+		_coset = coset[0]
+	}
+
+	// find the stage where we should stop spawning go routines in our recursive calls
+	// (ie when we have as many go routines running as we have available CPUs)
+	maxSplits := bits.TrailingZeros64(ecc.NextPowerOfTwo(numCPU))
+	if numCPU <= 1 {
+		maxSplits = -1
+	}
+	switch decimation {
+	case DIF:
+		difFFTG1(a, domain.TwiddlesInv, 0, maxSplits, nil)
+	case DIT:
+		ditFFTG1(a, domain.TwiddlesInv, 0, maxSplits, nil)
+	default:
+		panic("not implemented")
+	}
+
+	// scale by CardinalityInv
+	if !_coset {
+		scaleG1(a, nil, &domain.CardinalityInv)
+		return
+	}
+	if decimation == DIT {
+		scaleG1(a, domain.CosetTableInv, &domain.CardinalityInv)
+		return
+	}
+
+	// decimation == DIF
+	scaleG1(a, domain.CosetTableInvReversed, &domain.CardinalityInv)
+}
+
+// FFTG1Affine is FFTG1 on points in affine coordinates, the result being converted back to
+// affine coordinates with a single field inversion
+func (domain *Domain) FFTG1Affine(a []curve.G1Affine, decimation Decimation, coset ...bool) {
+	jac := toJacobianG1(a)
+	domain.FFTG1(jac, decimation, coset...)
+	batchJacobianToAffineG1(a, jac)
+}
+
+// FFTInverseG1Affine is FFTInverseG1 on points in affine coordinates, the result being converted
+// back to affine coordinates with a single field inversion
+func (domain *Domain) FFTInverseG1Affine(a []curve.G1Affine, decimation Decimation, coset ...bool) {
+	jac := toJacobianG1(a)
+	domain.FFTInverseG1(jac, decimation, coset...)
+	batchJacobianToAffineG1(a, jac)
+}
+
+// BitReverseG1 applies the bit-reversal permutation to a.
+// len(a) must be a power of 2 (as in every single function in this file)
+func BitReverseG1(a []curve.G1Jac) {
+	n := uint64(len(a))
+	nn := uint64(64 - bits.TrailingZeros64(n))
+
+	for i := uint64(0); i < n; i++ {
+		irev := bits.Reverse64(i) >> nn
+		if irev > i {
+			a[i], a[irev] = a[irev], a[i]
+		}
+	}
+}
+
+// butterflyG1 computes (a, b) ← (a + b, a - b)
+func butterflyG1(a, b *curve.G1Jac) {
+	t := *a
+	a.AddAssign(b)
+	t.SubAssign(b)
+	*b = t
+}
+
+// mulG1 computes p ← [s]p
+func mulG1(p *curve.G1Jac, s *fr.Element) {
+	var b big.Int
+	s.ToBigIntRegular(&b)
+	p.ScalarMultiplication(p, &b)
+}
+
+// scaleG1 multiplies a[i] by table[i] if table is not nil, and by factor if not nil
+func scaleG1(a []curve.G1Jac, table []fr.Element, factor *fr.Element) {
+	parallel.Execute(len(a), func(start, end int) {
+		var s fr.Element
+		for i := start; i < end; i++ {
+			s.SetOne()
+			if table != nil {
+				s.Set(&table[i])
+			}
+			if factor != nil {
+				s.Mul(&s, factor)
+			}
+			mulG1(&a[i], &s)
+		}
+	})
+}
+
+func difFFTG1(a []curve.G1Jac, twiddles [][]fr.Element, stage, maxSplits int, chDone chan struct{}) {
+	if chDone != nil {
+		defer close(chDone)
+	}
+
+	n := len(a)
+	if n == 1 {
+		return
+	}
+	m := n >> 1
+
+	// if stage < maxSplits, we parallelize this butterfly
+	// but we have only numCPU / stage cpus available
+	if (m > butterflyThreshold) && (stage < maxSplits) {
+		// 1 << stage == estimated used CPUs
+		numCPU := runtime.NumCPU() / (1 << (stage))
+		parallel.Execute(m, func(start, end int) {
+			for i := start; i < end; i++ {
+				butterflyG1(&a[i], &a[i+m])
+				if i != 0 {
+					mulG1(&a[i+m], &twiddles[stage][i])
+				}
+			}
+		}, numCPU)
+	} else {
+		// i == 0
+		butterflyG1(&a[0], &a[m])
+		for i := 1; i < m; i++ {
+			butterflyG1(&a[i], &a[i+m])
+			mulG1(&a[i+m], &twiddles[stage][i])
+		}
+	}
+
+	if m == 1 {
+		return
+	}
+
+	nextStage := stage + 1
+	if stage < maxSplits {
+		chDone := make(chan struct{}, 1)
+		go difFFTG1(a[m:n], twiddles, nextStage, maxSplits, chDone)
+		difFFTG1(a[0:m], twiddles, nextStage, maxSplits, nil)
+		<-chDone
+	} else {
+		difFFTG1(a[0:m], twiddles, nextStage, maxSplits, nil)
+		difFFTG1(a[m:n], twiddles, nextStage, maxSplits, nil)
+	}
+
+}
+
+func ditFFTG1(a []curve.G1Jac, twiddles [][]fr.Element, stage, maxSplits int, chDone chan struct{}) {
+	if chDone != nil {
+		defer close(chDone)
+	}
+	n := len(a)
+	if n == 1 {
+		return
+	}
+	m := n >> 1
+
+	nextStage := stage + 1
+
+	if stage < maxSplits {
+		// that's the only time we fire go routines
+		chDone := make(chan struct{}, 1)
+		go ditFFTG1(a[m:], twiddles, nextStage, maxSplits, chDone)
+		ditFFTG1(a[0:m], twiddles, nextStage, maxSplits, nil)
+		<-chDone
+	} else {
+		ditFFTG1(a[0:m], twiddles, nextStage, maxSplits, nil)
+		ditFFTG1(a[m:n], twiddles, nextStage, maxSplits, nil)
+
+	}
+
+	// if stage < maxSplits, we parallelize this butterfly
+	// but we have only numCPU / stage cpus available
+	if (m > butterflyThreshold) && (stage < maxSplits) {
+		// 1 << stage == estimated used CPUs
+		numCPU := runtime.NumCPU() / (1 << (stage))
+		parallel.Execute(m, func(start, end int) {
+			for k := start; k < end; k++ {
+				if k != 0 {
+					mulG1(&a[k+m], &twiddles[stage][k])
+				}
+				butterflyG1(&a[k], &a[k+m])
+			}
+		}, numCPU)
+
+	} else {
+		butterflyG1(&a[0], &a[m])
+		for k := 1; k < m; k++ {
+			mulG1(&a[k+m], &twiddles[stage][k])
+			butterflyG1(&a[k], &a[k+m])
+		}
+	}
+}
+
+// toJacobianG1 returns the points a in Jacobian coordinates
+func toJacobianG1(a []curve.G1Affine) []curve.G1Jac {
+	res := make([]curve.G1Jac, len(a))
+	parallel.Execute(len(a), func(start, end int) {
+		for i := start; i < end; i++ {
+			res[i].FromAffine(&a[i])
+		}
+	})
+	return res
+}
+
+// batchJacobianToAffineG1 sets res to the points in affine coordinates, performing
+// a single field inversion (Montgomery batch inversion trick)
+func batchJacobianToAffineG1(res []curve.G1Affine, points []curve.G1Jac) {
+	if len(points) == 0 {
+		return
+	}
+	zeroes := make([]bool, len(points))
+	accumulator := points[0].Z
+	accumulator.SetOne()
+
+	// batch invert all points[].Z coordinates, storing points[].Z⁻¹ in res[i].X
+	for i := 0; i < len(points); i++ {
+		if points[i].Z.IsZero() {
+			zeroes[i] = true
+			continue
+		}
+		res[i].X = accumulator
+		accumulator.Mul(&accumulator, &points[i].Z)
+	}
+
+	accInverse := accumulator
+	accInverse.Inverse(&accumulator)
+
+	for i := len(points) - 1; i >= 0; i-- {
+		if zeroes[i] {
+			continue
+		}
+		res[i].X.Mul(&res[i].X, &accInverse)
+		accInverse.Mul(&accInverse, &points[i].Z)
+	}
+
+	// batch convert to affine.
+	parallel.Execute(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if zeroes[i] {
+				// (X=0, Y=0) is infinity point in affine
+				res[i].X.SetZero()
+				res[i].Y.SetZero()
+				continue
+			}
+			a := res[i].X
+			b := a
+			b.Square(&a)
+			res[i].X.Mul(&points[i].X, &b)
+			res[i].Y.Mul(&points[i].Y, &b).
+				Mul(&res[i].Y, &a)
+		}
+	})
+}
+
+// FFTG2 computes (recursively) the discrete Fourier transform of the points a, the twiddles being
+// the ones of the fr.Element FFT, and stores the result in a.
+// if decimation == DIT (decimation in time), the input must be in bit-reversed order
+// if decimation == DIF (decimation in frequency), the output will be in bit-reversed order
+// if coset if set, the FFT(a) returns the evaluation of a on a coset.
+func (domain *Domain) FFTG2(a []curve.G2Jac, decimation Decimation, coset ...bool) {
+
+	numCPU := uint64(runtime.NumCPU())
+
+	_coset := false
+	if len(coset) > 0 {
+		_coset = coset[0]
+	}
+
+	// if coset != 0, scale by coset table
+	if _coset {
+		if decimation == DIT {
+			scaleG2(a, domain.CosetTableReversed, nil)
+		} else {
+			scaleG2(a, domain.CosetTable, nil)
+		}
+	}
+
+	// find the stage where we should stop spawning go routines in our recursive calls
+	// (ie when we have as many go routines running as we have available CPUs)
+	maxSplits := bits.TrailingZeros64(ecc.NextPowerOfTwo(numCPU))
+	if numCPU <= 1 {
+		maxSplits = -1
+	}
+
+	switch decimation {
+	case DIF:
+		difFFTG2(a, domain.Twiddles, 0, maxSplits, nil)
+	case DIT:
+		ditFFTG2(a, domain.Twiddles, 0, maxSplits, nil)
+	default:
+		panic("not implemented")
+	}
+}
+
+// FFTInverseG2 computes (recursively) the inverse discrete Fourier transform of the points a and stores the result in a
+// if decimation == DIT (decimation in time), the input must be in bit-reversed order
+// if decimation == DIF (decimation in frequency), the output will be in bit-reversed order
+// coset sets the shift of the fft (0 = no shift, standard fft)
+func (domain *Domain) FFTInverseG2(a []curve.G2Jac, decimation Decimation, coset ...bool) {
+
+	numCPU := uint64(runtime.NumCPU())
+
+	_coset := false
+	if len(coset) > 0 {
+		_coset = coset[0]
+	}
+
+	// find the stage where we should stop spawning go routines in our recursive calls
+	// (ie when we have as many go routines running as we have available CPUs)
+	maxSplits := bits.TrailingZeros64(ecc.NextPowerOfTwo(numCPU))
+	if numCPU <= 1 {
+		maxSplits = -1
+	}
+	switch decimation {
+	case DIF:
+		difFFTG2(a, domain.TwiddlesInv, 0, maxSplits, nil)
+	case DIT:
+		ditFFTG2(a, domain.TwiddlesInv, 0, maxSplits, nil)
+	default:
+		panic("not implemented")
+	}
+
+	// scale by CardinalityInv
+	if !_coset {
+		scaleG2(a, nil, &domain.CardinalityInv)
+		return
+	}
+	if decimation == DIT {
+		scaleG2(a, domain.CosetTableInv, &domain.CardinalityInv)
+		return
+	}
+
+	// decimation == DIF
+	scaleG2(a, domain.CosetTableInvReversed, &domain.CardinalityInv)
+}
+
+// FFTG2Affine is FFTG2 on points in affine coordinates, the result being converted back to
+// affine coordinates with a single field inversion
+func (domain *Domain) FFTG2Affine(a []curve.G2Affine, decimation Decimation, coset ...bool) {
+	jac := toJacobianG2(a)
+	domain.FFTG2(jac, decimation, coset...)
+	batchJacobianToAffineG2(a, jac)
+}
+
+// FFTInverseG2Affine is FFTInverseG2 on points in affine coordinates, the result being converted
+// back to affine coordinates with a single field inversion
+func (domain *Domain) FFTInverseG2Affine(a []curve.G2Affine, decimation Decimation, coset ...bool) {
+	jac := toJacobianG2(a)
+	domain.FFTInverseG2(jac, decimation, coset...)
+	batchJacobianToAffineG2(a, jac)
+}
+
+// BitReverseG2 applies the bit-reversal permutation to a.
+// len(a) must be a power of 2 (as in every single function in this file)
+func BitReverseG2(a []curve.G2Jac) {
+	n := uint64(len(a))
+	nn := uint64(64 - bits.TrailingZeros64(n))
+
+	for i := uint64(0); i < n; i++ {
+		irev := bits.Reverse64(i) >> nn
+		if irev > i {
+			a[i], a[irev] = a[irev], a[i]
+		}
+	}
+}
+
+// butterflyG2 computes (a, b) ← (a + b, a - b)
+func butterflyG2(a, b *curve.G2Jac) {
+	t := *a
+	a.AddAssign(b)
+	t.SubAssign(b)
+	*b = t
+}
+
+// mulG2 computes p ← [s]p
+func mulG2(p *curve.G2Jac, s *fr.Element) {
+	var b big.Int
+	s.ToBigIntRegular(&b)
+	p.ScalarMultiplication(p, &b)
+}
+
+// scaleG2 multiplies a[i] by table[i] if table is not nil, and by factor if not nil
+func scaleG2(a []curve.G2Jac, table []fr.Element, factor *fr.Element) {
+	parallel.Execute(len(a), func(start, end int) {
+		var s fr.Element
+		for i := start; i < end; i++ {
+			s.SetOne()
+			if table != nil {
+				s.Set(&table[i])
+			}
+			if factor != nil {
+				s.Mul(&s, factor)
+			}
+			mulG2(&a[i], &s)
+		}
+	})
+}
+
+func difFFTG2(a []curve.G2Jac, twiddles [][]fr.Element, stage, maxSplits int, chDone chan struct{}) {
+	if chDone != nil {
+		defer close(chDone)
+	}
+
+	n := len(a)
+	if n == 1 {
+		return
+	}
+	m := n >> 1
+
+	// if stage < maxSplits, we parallelize this butterfly
+	// but we have only numCPU / stage cpus available
+	if (m > butterflyThreshold) && (stage < maxSplits) {
+		// 1 << stage == estimated used CPUs
+		numCPU := runtime.NumCPU() / (1 << (stage))
+		parallel.Execute(m, func(start, end int) {
+			for i := start; i < end; i++ {
+				butterflyG2(&a[i], &a[i+m])
+				if i != 0 {
+					mulG2(&a[i+m], &twiddles[stage][i])
+				}
+			}
+		}, numCPU)
+	} else {
+		// i == 0
+		butterflyG2(&a[0], &a[m])
+		for i := 1; i < m; i++ {
+			butterflyG2(&a[i], &a[i+m])
+			mulG2(&a[i+m], &twiddles[stage][i])
+		}
+	}
+
+	if m == 1 {
+		return
+	}
+
+	nextStage := stage + 1
+	if stage < maxSplits {
+		chDone := make(chan struct{}, 1)
+		go difFFTG2(a[m:n], twiddles, nextStage, maxSplits, chDone)
+		difFFTG2(a[0:m], twiddles, nextStage, maxSplits, nil)
+		<-chDone
+	} else {
+		difFFTG2(a[0:m], twiddles, nextStage, maxSplits, nil)
+		difFFTG2(a[m:n], twiddles, nextStage, maxSplits, nil)
+	}
+
+}
+
+func ditFFTG2(a []curve.G2Jac, twiddles [][]fr.Element, stage, maxSplits int, chDone chan struct{}) {
+	if chDone != nil {
+		defer close(chDone)
+	}
+	n := len(a)
+	if n == 1 {
+		return
+	}
+	m := n >> 1
+
+	nextStage := stage + 1
+
+	if stage < maxSplits {
+		// that's the only time we fire go routines
+		chDone := make(chan struct{}, 1)
+		go ditFFTG2(a[m:], twiddles, nextStage, maxSplits, chDone)
+		ditFFTG2(a[0:m], twiddles, nextStage, maxSplits, nil)
+		<-chDone
+	} else {
+		ditFFTG2(a[0:m], twiddles, nextStage, maxSplits, nil)
+		ditFFTG2(a[m:n], twiddles, nextStage, maxSplits, nil)
+
+	}
+
+	// if stage < maxSplits, we parallelize this butterfly
+	// but we have only numCPU / stage cpus available
+	if (m > butterflyThreshold) && (stage < maxSplits) {
+		// 1 << stage == estimated used CPUs
+		numCPU := runtime.NumCPU() / (1 << (stage))
+		parallel.Execute(m, func(start, end int) {
+			for k := start; k < end; k++ {
+				if k != 0 {
+					mulG2(&a[k+m], &twiddles[stage][k])
+				}
+				butterflyG2(&a[k], &a[k+m])
+			}
+		}, numCPU)
+
+	} else {
+		butterflyG2(&a[0], &a[m])
+		for k := 1; k < m; k++ {
+			mulG2(&a[k+m], &twiddles[stage][k])
+			butterflyG2(&a[k], &a[k+m])
+		}
+	}
+}
+
+// toJacobianG2 returns the points a in Jacobian coordinates
+func toJacobianG2(a []curve.G2Affine) []curve.G2Jac {
+	res := make([]curve.G2Jac, len(a))
+	parallel.Execute(len(a), func(start, end int) {
+		for i := start; i < end; i++ {
+			res[i].FromAffine(&a[i])
+		}
+	})
+	return res
+}
+
+// batchJacobianToAffineG2 sets res to the points in affine coordinates, performing
+// a single field inversion (Montgomery batch inversion trick)
+func batchJacobianToAffineG2(res []curve.G2Affine, points []curve.G2Jac) {
+	if len(points) == 0 {
+		return
+	}
+	zeroes := make([]bool, len(points))
+	accumulator := points[0].Z
+	accumulator.SetOne()
+
+	// batch invert all points[].Z coordinates, storing points[].Z⁻¹ in res[i].X
+	for i := 0; i < len(points); i++ {
+		if points[i].Z.IsZero() {
+			zeroes[i] = true
+			continue
+		}
+		res[i].X = accumulator
+		accumulator.Mul(&accumulator, &points[i].Z)
+	}
+
+	accInverse := accumulator
+	accInverse.Inverse(&accumulator)
+
+	for i := len(points) - 1; i >= 0; i-- {
+		if zeroes[i] {
+			continue
+		}
+		res[i].X.Mul(&res[i].X, &accInverse)
+		accInverse.Mul(&accInverse, &points[i].Z)
+	}
+
+	// batch convert to affine.
+	parallel.Execute(len(points), func(start, end int) {
+		for i := start; i < end; i++ {
+			if zeroes[i] {
+				// (X=0, Y=0) is infinity point in affine
+				res[i].X.SetZero()
+				res[i].Y.SetZero()
+				continue
+			}
+			a := res[i].X
+			b := a
+			b.Square(&a)
+			res[i].X.Mul(&points[i].X, &b)
+			res[i].Y.Mul(&points[i].Y, &b).
+				Mul(&res[i].Y, &a)
+		}
+	})
+}
@@ -17,12 +17,13 @@
 package fri
 
 import (
-	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"hash"
 	"math/big"
 	"math/bits"
+	"sort"
 
 	"github.com/consensys/gnark-crypto/accumulator/merkletree"
 	"github.com/consensys/gnark-crypto/ecc"
@@ -32,17 +33,24 @@ import (
 )
 
 var (
-	ErrLowDegree            = errors.New("the fully folded polynomial in not of degree 1")
+	ErrLowDegree            = errors.New("the fully folded polynomial in not of the expected degree")
 	ErrProximityTestFolding = errors.New("one round of interaction failed")
 	ErrOddSize              = errors.New("the size should be even")
-	ErrMerkleRoot           = errors.New("merkle roots of the opening and the proof of proximity don't coincide")
+	ErrMerkleRoot           = errors.New("the merkle cap doesn't match the size of the committed oracle")
 	ErrMerklePath           = errors.New("merkle path proof is wrong")
 	ErrRangePosition        = errors.New("the asked opening position is out of range")
+	ErrNbQueries            = errors.New("the number of queries or foldings doesn't match the configuration")
+	ErrProofOfWork          = errors.New("the proof of work is not valid")
+	ErrProofOfWorkNotFound  = errors.New("no proof of work found within the nonce bound")
+	ErrInvalidConfig        = errors.New("invalid fri configuration")
 )
 
-const rho = 8
+// maxGrindingBits maximum number of grinding bits, the prover tries 2^GrindingBits nonces on average
+const maxGrindingBits = 32
 
-const nbRounds = 1
+// maxNonce bound of the nonces tried by the prover, the search fails with probability
+// < e^{-256} for GrindingBits ≤ maxGrindingBits
+const maxNonce = 1 << (maxGrindingBits + 8)
 
 // 2^{-1}, used several times
 var twoInv fr.Element
@@ -50,34 +58,13 @@ var twoInv fr.Element
 // Digest commitment of a polynomial.
 type Digest []byte
 
-// merkleProof helper structure to build the merkle proof
-// At each round, two contiguous values from the evaluated polynomial
-// are queried. For one value, the full Merkle path will be provided.
-// For the neighbor value, only the leaf is provided (so ProofSet will
-// be empty), since the Merkle path is the same as for the first value.
-type MerkleProof struct {
-
-	// Merkle root
-	MerkleRoot []byte
-
-	// ProofSet stores [leaf ∥ node_1 ∥ .. ∥ merkleRoot ], where the leaf is not
-	// hashed.
-	ProofSet [][]byte
-
-	// number of leaves of the tree.
-	numLeaves uint64
-}
-
-// MerkleProof used to open a polynomial
+// OpeningProof Merkle proof used to open a polynomial, against the Merkle cap
+// of the first step of the proof of proximity
 type OpeningProof struct {
 
-	// those fields are private since they are only needed for
-	// the verification, which is abstracted in the VerifyOpening
-	// method.
-	merkleRoot []byte
-	ProofSet   [][]byte
-	numLeaves  uint64
-	index      uint64
+	// ProofSet stores [leaf ∥ node_1 ∥ .. ], the nodes going up to the Merkle cap,
+	// where the leaf is not hashed.
+	ProofSet [][]byte
 
 	// ClaimedValue value of the leaf. This field is exported
 	// because it's needed for protocols using polynomial commitment
@@ -89,39 +76,103 @@ type OpeningProof struct {
 type IOPP uint
 
 const (
-	// Multiplicative version of FRI, using the map x->x², on a
-	// power of 2 subgroup of Fr^{*}.
+	// Multiplicative version of FRI, using the map x->xᵏ, k the folding arity,
+	// on a power of 2 subgroup of Fr^{*}.
 	RADIX_2_FRI IOPP = iota
 )
 
-// round contains the data corresponding to a single round
-// of fri.
-// It consists of a list of Interactions between the prover and the verifier,
-// where each interaction contains a challenge provided by the verifier, as
-// well as MerkleProofs for the queries of the verifier. The Merkle proofs
-// correspond to the openings of the i-th folded polynomial at 2 points that
-// belong to the same fiber of x -> x².
+// Config parameters of the FRI protocol, trading the proof size against the prover time
+// and the soundness, see SecurityBits.
+type Config struct {
+
+	// Blowup factor ρ⁻¹ = size_code_word/size_polynomial, a power of 2 ≥ 2
+	Blowup uint64
+
+	// NbQueries number of positions queried by the verifier
+	NbQueries int
+
+	// FoldingArity number of evaluations folded together at each step (2, 4 or 8),
+	// the degree of the polynomial is divided by FoldingArity at each step
+	FoldingArity int
+
+	// FinalDegree the folding stops when the degree of the folded polynomial is at most
+	// FinalDegree, its coefficients are then sent in the clear
+	FinalDegree uint64
+
+	// GrindingBits number of leading zero bits of the proof of work the prover has to
+	// solve before the queries are derived, at most 32, 0 disables the grinding
+	GrindingBits int
+
+	// CapHeight the oracles are committed with the Merkle caps of height CapHeight, that
+	// is the 2^CapHeight nodes of a layer of their Merkle trees, instead of the roots. The
+	// Merkle paths of the queries are CapHeight nodes shorter, 0 commits to the roots.
+	CapHeight int
+}
+
+// DefaultConfig returns a configuration with ~100 bits of conjectured security
+func DefaultConfig() Config {
+	return Config{
+		Blowup:       8,
+		NbQueries:    30,
+		FoldingArity: 2,
+		FinalDegree:  0,
+		GrindingBits: 10,
+		CapHeight:    0,
+	}
+}
+
+// Check returns an error if the configuration is not supported
+func (c Config) Check() error {
+	if c.Blowup < 2 || c.Blowup&(c.Blowup-1) != 0 {
+		return fmt.Errorf("%w: the blowup factor should be a power of 2 ≥ 2", ErrInvalidConfig)
+	}
+	if c.NbQueries < 1 {
+		return fmt.Errorf("%w: the number of queries should be positive", ErrInvalidConfig)
+	}
+	if c.FoldingArity != 2 && c.FoldingArity != 4 && c.FoldingArity != 8 {
+		return fmt.Errorf("%w: the folding arity should be 2, 4 or 8", ErrInvalidConfig)
+	}
+	if c.GrindingBits < 0 || c.GrindingBits > maxGrindingBits {
+		return fmt.Errorf("%w: the number of grinding bits should be in [0, %d]", ErrInvalidConfig, maxGrindingBits)
+	}
+	if c.CapHeight < 0 || c.CapHeight > 32 {
+		return fmt.Errorf("%w: the height of the Merkle caps should be in [0, 32]", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// SecurityBits returns the conjectured security level of the configuration, in bits:
+// each query contributes log₂(Blowup) bits, and the grinding GrindingBits bits. The result
+// is capped by the size of the field, from which the folding challenges are drawn.
+func (c Config) SecurityBits() int {
+	res := c.NbQueries*bits.TrailingZeros64(c.Blowup) + c.GrindingBits
+	if res > fr.Bits {
+		res = fr.Bits
+	}
+	return res
+}
+
+// Round contains the data corresponding to a single folding step: the commitment
+// of the folded polynomial, and the openings of the leaves queried by the verifier.
+//
+// The evaluations of the polynomial on a coset {x, ζx, ζ²x, ..} (ζ a root of unity
+// of order the folding arity) are stored in a single leaf, so a query is answered
+// with a single leaf at each step. The leaves of all the queries are opened at once,
+// the nodes shared by their Merkle paths appearing once.
 type Round struct {
 
-	// stores the Interactions between the prover and the verifier.
-	// Each interaction results in a set or merkle proofs, corresponding
-	// to the queries of the verifier.
-	Interactions [][2]MerkleProof
+	// MerkleCap Merkle cap of height CapHeight of the evaluations of the folded
+	// polynomial.
+	MerkleCap [][]byte
 
-	// evaluation stores the evaluation of the fully folded polynomial.
-	// The fully folded polynomial is constant, and is evaluated on a
-	// a set of size \rho. Since the polynomial is supposed to be constant,
-	// only one evaluation, corresponding to the polynomial, is given. Since
-	// the prover cannot know in advance which entry the verifier will query,
-	// providing a single evaluation
-	Evaluation fr.Element
+	// Openings opening of the leaves containing the queries, against MerkleCap.
+	Openings merkletree.MultiProof
 }
 
 // ProofOfProximity proof of proximity, attesting that
 // a function is d-close to a low degree polynomial.
 //
-// It is composed of a series of Interactions, emulated with Fiat Shamir,
-//
+// It is composed of a series of Interactions, emulated with Fiat Shamir.
 type ProofOfProximity struct {
 
 	// ID unique ID attached to the proof of proximity. It's needed for
@@ -129,9 +180,17 @@ type ProofOfProximity struct {
 	// from the proof of proximity.
 	ID []byte
 
-	// round contains the data corresponding to a single round
-	// of fri. There are nbRounds rounds of Interactions.
+	// Rounds contains the commitments of the folded polynomials and the openings
+	// of the queries of the verifier, there is one round per folding step.
 	Rounds []Round
+
+	// FinalPolynomial coefficients of the fully folded polynomial, of degree
+	// at most FinalDegree.
+	FinalPolynomial []fr.Element
+
+	// Nonce solution of the proof of work, bound to the transcript before the
+	// queries are derived.
+	Nonce uint64
 }
 
 // Iopp interface that an iopp should implement
@@ -152,20 +211,30 @@ type Iopp interface {
 	VerifyOpening(position uint64, openingProof OpeningProof, pp ProofOfProximity) error
 }
 
-// GetRho returns the factor ρ = size_code_word/size_polynomial
+// GetRho returns the factor ρ⁻¹ = size_code_word/size_polynomial of the default configuration
+//
+// Deprecated: use Config.Blowup instead.
 func GetRho() int {
-	return rho
+	return int(DefaultConfig().Blowup)
 }
 
 func init() {
 	twoInv.SetUint64(2).Inverse(&twoInv)
 }
 
-// New creates a new IOPP capable to handle degree(size) polynomials.
-func (iopp IOPP) New(size uint64, h hash.Hash) Iopp {
+// New creates a new IOPP capable to handle degree(size) polynomials. If no configuration
+// is provided, DefaultConfig is used. It panics if the configuration is not supported.
+func (iopp IOPP) New(size uint64, h hash.Hash, config ...Config) Iopp {
+	c := DefaultConfig()
+	if len(config) > 0 {
+		c = config[0]
+	}
+	if err := c.Check(); err != nil {
+		panic(err)
+	}
 	switch iopp {
 	case RADIX_2_FRI:
-		return newRadixTwoFri(size, h)
+		return newRadixTwoFri(size, h, c)
 	default:
 		panic("iopp name is not recognized")
 	}
@@ -179,82 +248,181 @@ type radixTwoFri struct {
 	// the oracles.
 	h hash.Hash
 
-	// nbSteps number of Interactions between the prover and the verifier
+	// config parameters of the protocol
+	config Config
+
+	// nbSteps number of foldings
 	nbSteps int
 
+	// finalSize number of coefficients of the fully folded polynomial
+	finalSize uint64
+
 	// domain used to build the Reed Solomon code from the given polynomial.
-	// The size of the domain is ρ*size_polynomial.
+	// The size of the domain is ρ⁻¹*size_polynomial.
 	domain *fft.Domain
+
+	// zetaInv[t] = ζ⁻ᵗ, where ζ is a root of unity of order FoldingArity
+	zetaInv []fr.Element
 }
 
-func newRadixTwoFri(size uint64, h hash.Hash) radixTwoFri {
+func newRadixTwoFri(size uint64, h hash.Hash, config Config) radixTwoFri {
 
 	var res radixTwoFri
+	res.h = h
+	res.config = config
+	arity := uint64(config.FoldingArity)
 
-	// computing the number of steps
+	// the code word should contain at least one coset
 	n := ecc.NextPowerOfTwo(size)
-	nbSteps := bits.TrailingZeros(uint(n))
-	res.nbSteps = nbSteps
+	if n*config.Blowup < arity {
+		n = arity / config.Blowup
+	}
 
-	// extending the domain
-	n = n * rho
+	// building the domain, extended by the blowup factor
+	res.domain = fft.NewDomain(n * config.Blowup)
 
-	// building the domains
-	res.domain = fft.NewDomain(n)
+	// computing the number of steps: the polynomial is folded at least once,
+	// until its degree is at most FinalDegree, or the domain is too small
+	// to be folded again.
+	res.finalSize = n
+	for m := res.domain.Cardinality; m >= arity && (res.nbSteps == 0 || res.finalSize > config.FinalDegree+1); m /= arity {
+		res.finalSize = (res.finalSize + arity - 1) / arity
+		res.nbSteps++
+	}
 
-	// hash function
-	res.h = h
+	// ζ = g^{n/arity} is of order arity
+	var zetaInv fr.Element
+	zetaInv.Exp(res.domain.GeneratorInv, big.NewInt(int64(res.domain.Cardinality/arity)))
+	res.zetaInv = make([]fr.Element, arity)
+	res.zetaInv[0].SetOne()
+	for i := 1; i < len(res.zetaInv); i++ {
+		res.zetaInv[i].Mul(&res.zetaInv[i-1], &zetaInv)
+	}
 
 	return res
 }
 
-// convertCanonicalSorted convert the index i, an entry in a
-// sorted polynomial, to the corresponding entry in canonical
-// representation. n is the size of the polynomial.
-func convertCanonicalSorted(i, n int) int {
+// deriveQueriesPositions derives the indices of the oracle
+// function that the verifier has to pick, one for each folding step,
+// and one for the fully folded polynomial.
+// * pos is the initial position, i.e. the logarithm of the first query
+// * size is the size of the initial domain
+// * The result is a slice of []int, where the i-th entry is the position of the
+// query in the domain of the i-th folded polynomial. The position p on a domain of
+// size n lies in the leaf p mod (n/k) (k is the folding arity), at index p/(n/k).
+func (s radixTwoFri) deriveQueriesPositions(pos int, size int) []int {
 
-	if i < n/2 {
-		return 2 * i
-	} else {
-		l := n - (i + 1)
-		l = 2 * l
-		return n - l - 1
+	res := make([]int, s.nbSteps+1)
+	res[0] = pos
+	for i := 1; i <= s.nbSteps; i++ {
+		size = size / s.config.FoldingArity
+		res[i] = res[i-1] % size
 	}
 
+	return res
 }
 
-// deriveQueriesPositions derives the indices of the oracle
-// function that the verifier has to pick, in sorted form.
-// * pos is the initial position, i.e. the logarithm of the first challenge
-// * size is the size of the initial polynomial
-// * The result is a slice of []int, where each entry is a tuple (iₖ), such that
-// the verifier needs to evaluate ∑ₖ oracle(iₖ)xᵏ to build
-// the folded function.
-func (s radixTwoFri) deriveQueriesPositions(pos int, size int) []int {
+// leaf returns the concatenation of the evaluations stored in the j-th leaf,
+// that is, on a domain of size n, the evaluations at g^{j + t*n/k}, t < k
+func (s radixTwoFri) leaf(evaluations []fr.Element, j int) []byte {
+	k := s.config.FoldingArity
+	m := len(evaluations) / k
+	res := make([]byte, 0, k*fr.Bytes)
+	for t := 0; t < k; t++ {
+		b := evaluations[j+t*m].Bytes()
+		res = append(res, b[:]...)
+	}
+	return res
+}
 
-	_s := size / 2
-	res := make([]int, s.nbSteps)
-	res[0] = pos
-	for i := 1; i < s.nbSteps; i++ {
-		t := (res[i-1] - (res[i-1] % 2)) / 2
-		res[i] = convertCanonicalSorted(t, _s)
-		_s = _s / 2
+// parseLeaf parses the leaf of a Merkle proof, built with the leaf method
+func (s radixTwoFri) parseLeaf(leaf []byte) ([]fr.Element, error) {
+	if len(leaf) != s.config.FoldingArity*fr.Bytes {
+		return nil, ErrMerklePath
+	}
+	res := make([]fr.Element, s.config.FoldingArity)
+	for t := range res {
+		res[t].SetBytes(leaf[t*fr.Bytes : (t+1)*fr.Bytes])
+	}
+	return res, nil
+}
+
+// commit returns the Merkle tree of evaluations (of size n), where the j-th
+// leaf stores the evaluations on the coset of g^j, see leaf.
+func (s radixTwoFri) commit(evaluations []fr.Element) (*merkletree.MemoryTree, error) {
+	leaves := make([][]byte, len(evaluations)/s.config.FoldingArity)
+	for j := range leaves {
+		leaves[j] = s.leaf(evaluations, j)
 	}
+	return merkletree.NewMemoryTree(s.h, leaves)
+}
 
-	return res
+// openLeaves returns the MultiProof of the leaves at indices of the tree of evaluations
+func (s radixTwoFri) openLeaves(t *merkletree.MemoryTree, evaluations []fr.Element, indices []uint64) (merkletree.MultiProof, error) {
+	leaves := make([][]byte, len(indices))
+	for q := range indices {
+		leaves[q] = s.leaf(evaluations, int(indices[q]))
+	}
+	return t.ProveMulti(leaves, indices, s.config.CapHeight)
+}
+
+// verifyLeaves verifies the MultiProof of the leaves at indices, against the Merkle cap
+// of a tree with nbLeaves leaves, and returns the parsed leaves, by index.
+func (s radixTwoFri) verifyLeaves(merkleCap [][]byte, indices []uint64, proof merkletree.MultiProof, nbLeaves uint64) (map[uint64][]fr.Element, error) {
+	if uint64(len(merkleCap)) != s.capSize(nbLeaves) {
+		return nil, ErrMerkleRoot
+	}
+	if !merkletree.VerifyMultiProof(s.h, merkleCap, indices, proof, nbLeaves) {
+		return nil, ErrMerklePath
+	}
+
+	// proof.Leaves are sorted by index, without duplicates
+	sorted := make([]uint64, len(indices))
+	copy(sorted, indices)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	res := make(map[uint64][]fr.Element, len(proof.Leaves))
+	for _, index := range sorted {
+		if _, ok := res[index]; ok {
+			continue
+		}
+		v, err := s.parseLeaf(proof.Leaves[len(res)])
+		if err != nil {
+			return nil, err
+		}
+		res[index] = v
+	}
+	return res, nil
 }
 
-// sort orders the evaluation of a polynomial on a domain
-// such that contiguous entries are in the same fiber:
-// {q(g⁰), q(g^{n/2}), q(g¹), q(g^{1+n/2}),...,q(g^{n/2-1}), q(gⁿ⁻¹)}
-func sort(evaluations []fr.Element) []fr.Element {
-	q := make([]fr.Element, len(evaluations))
-	n := len(evaluations) / 2
-	for i := 0; i < n; i++ {
-		q[2*i].Set(&evaluations[i])
-		q[2*i+1].Set(&evaluations[i+n])
+// capSize returns the number of nodes of the Merkle cap of a tree with nbLeaves leaves,
+// nbLeaves being a power of 2
+func (s radixTwoFri) capSize(nbLeaves uint64) uint64 {
+	if nbLeaves > 1<<s.config.CapHeight {
+		return 1 << s.config.CapHeight
 	}
-	return q
+	return nbLeaves
+}
+
+// splitCap splits the digest of a polynomial into the nodes of its Merkle cap, see capDigest
+func (s radixTwoFri) splitCap(digest []byte) ([][]byte, error) {
+	size := s.h.Size()
+	if len(digest) == 0 || len(digest)%size != 0 {
+		return nil, ErrMerkleRoot
+	}
+	res := make([][]byte, len(digest)/size)
+	for i := range res {
+		res[i] = digest[i*size : (i+1)*size]
+	}
+	return res, nil
+}
+
+// capDigest returns the concatenation of the nodes of a Merkle cap
+func capDigest(merkleCap [][]byte) []byte {
+	var res []byte
+	for i := range merkleCap {
+		res = append(res, merkleCap[i]...)
+	}
+	return res
 }
 
 // Opens a polynomial at gⁱ where i = position.
@@ -271,26 +439,22 @@ func (s radixTwoFri) Open(p []fr.Element, position uint64) (OpeningProof, error)
 	s.domain.FFT(q, fft.DIF)
 	fft.BitReverse(q)
 
-	// sort q to have fibers in contiguous entries. The goal is to have one
-	// Merkle path for both openings of entries which are in the same fiber.
-	q = sort(q)
-
-	// build the Merkle proof, we the position is converted to fit the sorted polynomial
-	pos := convertCanonicalSorted(int(position), len(q))
-
-	tree := merkletree.New(s.h)
-	err := tree.SetIndex(uint64(pos))
+	// build the Merkle proof of the leaf containing position, the opening shares
+	// the commitment of the first folding step.
+	nbLeaves := s.domain.Cardinality / uint64(s.config.FoldingArity)
+	leafIndex := position % nbLeaves
+	tree, err := s.commit(q)
 	if err != nil {
 		return OpeningProof{}, err
 	}
-	for i := 0; i < len(q); i++ {
-		tree.Push(q[i].Marshal())
-	}
 	var res OpeningProof
-	res.merkleRoot, res.ProofSet, res.index, res.numLeaves = tree.Prove()
+	res.ProofSet, err = tree.Prove(s.leaf(q, int(leafIndex)), leafIndex, s.config.CapHeight)
+	if err != nil {
+		return OpeningProof{}, err
+	}
 
-	// set the claimed value, which is the first entry of the Merkle proof
-	res.ClaimedValue.SetBytes(res.ProofSet[0])
+	// set the claimed value, which is stored in the leaf of the Merkle proof
+	res.ClaimedValue.Set(&q[position])
 
 	return res, nil
 }
@@ -299,68 +463,106 @@ func (s radixTwoFri) Open(p []fr.Element, position uint64) (OpeningProof, error)
 // * position the point at which the proof is opened (the point is gⁱ where i = position)
 // * openingProof Merkle path proof
 // * pp proof of proximity, needed because before opening Merkle path proof one should be sure that the
-// committed values come from a polynomial. During the verification of the Merkle path proof, the root
-// hash of the Merkle path is compared to the root hash of the first interaction of the proof of proximity,
-// those should be equal, if not an error is raised.
+// committed values come from a polynomial. The Merkle path proof is verified against the Merkle cap of
+// the first round of the proof of proximity.
 func (s radixTwoFri) VerifyOpening(position uint64, openingProof OpeningProof, pp ProofOfProximity) error {
 
-	// To query the Merkle path, we look at the first series of Interactions, and check whether it's the point
-	// at 'position' or its neighbor that contains the full Merkle path.
-	var fullMerkleProof int
-	if len(pp.Rounds[0].Interactions[0][0].ProofSet) > len(pp.Rounds[0].Interactions[0][1].ProofSet) {
-		fullMerkleProof = 0
-	} else {
-		fullMerkleProof = 1
+	if position >= s.domain.Cardinality {
+		return ErrRangePosition
 	}
-
-	// check that the merkle roots coincide
-	if !bytes.Equal(openingProof.merkleRoot, pp.Rounds[0].Interactions[0][fullMerkleProof].MerkleRoot) {
-		return ErrMerkleRoot
+	if len(pp.Rounds) == 0 {
+		return ErrNbQueries
+	}
+	if len(openingProof.ProofSet) == 0 {
+		return ErrMerklePath
 	}
 
-	// convert position to the sorted version
-	sizePoly := s.domain.Cardinality
-	pos := convertCanonicalSorted(int(position), int(sizePoly))
+	// check the Merkle proof of the leaf containing position, against the cap
+	// of the first round
+	nbLeaves := s.domain.Cardinality / uint64(s.config.FoldingArity)
+	leafIndex := position % nbLeaves
+	leaves, err := s.verifyLeaves(
+		pp.Rounds[0].MerkleCap,
+		[]uint64{leafIndex},
+		merkletree.MultiProof{Leaves: openingProof.ProofSet[:1], Nodes: openingProof.ProofSet[1:]},
+		nbLeaves,
+	)
+	if err != nil {
+		return err
+	}
 
-	// check the Merkle proof
-	res := merkletree.VerifyProof(s.h, openingProof.merkleRoot, openingProof.ProofSet, uint64(pos), openingProof.numLeaves)
-	if !res {
+	// check that the claimed value is the one stored in the leaf
+	if !leaves[leafIndex][position/nbLeaves].Equal(&openingProof.ClaimedValue) {
 		return ErrMerklePath
 	}
+
 	return nil
 
 }
 
-// foldPolynomialLagrangeBasis folds a polynomial p, expressed in Lagrange basis.
+// foldCoset folds the evaluations v of a polynomial p on a coset y*<ζ>, where ζ is a
+// root of unity of order k = len(v), that is v[t] = p(y*ζᵗ).
 //
-// Fᵣ[X]/(Xⁿ-1) is a free module of rank 2 on Fᵣ[Y]/(Y^{n/2}-1). If
-// p∈ Fᵣ[X]/(Xⁿ-1), expressed in Lagrange basis, the function finds the coordinates
-// p₁, p₂ of p in Fᵣ[Y]/(Y^{n/2}-1), expressed in Lagrange basis. Finally, it computes
-// p₁ + x*p₂ and returns it.
+// Writing p = ∑ₜ Xᵗpₜ(Xᵏ), it returns ∑ₜ xᵗpₜ(yᵏ). The folding is done in log₂(k)
+// steps: at each step the polynomial is written P₀(X²)+X*P₁(X²), and folded into
+// P₀(Y)+x*P₁(Y), x being squared between the steps.
 //
-// * p is the polynomial to fold, in Lagrange basis, sorted like this: p = [p(1),p(-1),p(g),p(-g),p(g²),p(-g²),...]
-// * g is a generator of the subgroup of Fᵣ^{*} of size len(p)
-// * x is the folding challenge x, used to return p₁+x*p₂
-func foldPolynomialLagrangeBasis(pSorted []fr.Element, gInv, x fr.Element) []fr.Element {
-
-	// we have the following system
-	// p₁(g²ⁱ)+gⁱp₂(g²ⁱ) = p(gⁱ)
-	// p₁(g²ⁱ)-gⁱp₂(g²ⁱ) = p(-gⁱ)
-	// we solve the system for p₁(g²ⁱ),p₂(g²ⁱ)
-	s := len(pSorted)
-	res := make([]fr.Element, s/2)
-
-	var p1, p2, acc fr.Element
-	acc.SetOne()
-
-	for i := 0; i < s/2; i++ {
+// * v is modified in place
+// * yInv is the inverse of y
+// * x is the folding challenge
+func (s radixTwoFri) foldCoset(v []fr.Element, yInv, x fr.Element) fr.Element {
+
+	// we have the following system, for a = y*ζᵗ
+	// P₀(a²)+aP₁(a²) = p(a)
+	// P₀(a²)-aP₁(a²) = p(-a)
+	// we solve the system for P₀(a²),P₁(a²)
+	k := len(v)
+	var p0, p1, aInv fr.Element
+	for m := k / 2; m >= 1; m /= 2 {
+
+		// the current coset is y*<ζ^{k/2m}>, v[t+m] is the evaluation at -y*ζ^{tk/2m}
+		stride := k / (2 * m)
+		for t := 0; t < m; t++ {
+			aInv.Mul(&yInv, &s.zetaInv[t*stride])
+			p0.Add(&v[t], &v[t+m])
+			p1.Sub(&v[t], &v[t+m]).Mul(&p1, &aInv)
+			v[t].Mul(&p1, &x).Add(&v[t], &p0).Mul(&v[t], &twoInv)
+		}
 
-		p1.Add(&pSorted[2*i], &pSorted[2*i+1])
-		p2.Sub(&pSorted[2*i], &pSorted[2*i+1]).Mul(&p2, &acc)
-		res[i].Mul(&p2, &x).Add(&res[i], &p1).Mul(&res[i], &twoInv)
+		// the next coset is y²*<ζ^{k/m}>
+		yInv.Square(&yInv)
+		x.Square(&x)
+	}
 
-		acc.Mul(&acc, &gInv)
+	return v[0]
+}
 
+// foldPolynomialLagrangeBasis folds a polynomial p, expressed in Lagrange basis.
+//
+// Fᵣ[X]/(Xⁿ-1) is a free module of rank k on Fᵣ[Y]/(Y^{n/k}-1). If
+// p∈ Fᵣ[X]/(Xⁿ-1), expressed in Lagrange basis, the function finds the coordinates
+// pₜ of p in Fᵣ[Y]/(Y^{n/k}-1), expressed in Lagrange basis. Finally, it computes
+// ∑ₜ xᵗpₜ and returns it.
+//
+// * p is the polynomial to fold, in Lagrange basis, in natural order: p = [p(1),p(g),p(g²),...]
+// * gInv is the inverse of a generator of the subgroup of Fᵣ^{*} of size len(p)
+// * x is the folding challenge x
+func (s radixTwoFri) foldPolynomialLagrangeBasis(p []fr.Element, gInv, x fr.Element) []fr.Element {
+
+	// the j-th coset, g^j*<ζ>, is folded into the j-th entry of the result
+	k := s.config.FoldingArity
+	m := len(p) / k
+	res := make([]fr.Element, m)
+	v := make([]fr.Element, k)
+
+	var yInv fr.Element
+	yInv.SetOne()
+	for j := 0; j < m; j++ {
+		for t := 0; t < k; t++ {
+			v[t] = p[j+t*m]
+		}
+		res[j] = s.foldCoset(v, yInv, x)
+		yInv.Mul(&yInv, &gInv)
 	}
 
 	return res
@@ -378,334 +580,352 @@ func paddNaming(s string, size int) string {
 	return string(a)
 }
 
-// buildProofOfProximitySingleRound generates a proof that a function, given as an oracle from
+// newTranscript returns the Fiat Shamir transcript of the protocol, and the names of the
+// challenges:
+// * the xᵢ, used to fold the polynomials, bound to the Merkle caps of the folded polynomials
+// * the grinding challenge, bound to the fully folded polynomial
+// * the seed from which the queries are derived, bound to the nonce of the proof of work
+// The challenges challengesID are derived before those of the protocol, so that protocols
+// built on top of FRI share its transcript.
+func (s radixTwoFri) newTranscript(challengesID ...string) (fiatshamir.Transcript, []string) {
+	xis := make([]string, s.nbSteps+2)
+	for i := 0; i < s.nbSteps; i++ {
+		xis[i] = paddNaming(fmt.Sprintf("x%d", i), fr.Bytes)
+	}
+	xis[s.nbSteps] = paddNaming("grinding", fr.Bytes)
+	xis[s.nbSteps+1] = paddNaming("s0", fr.Bytes)
+	ids := make([]string, 0, len(challengesID)+len(xis))
+	ids = append(ids, challengesID...)
+	ids = append(ids, xis...)
+	return fiatshamir.NewTranscript(s.h, ids...), xis
+}
+
+// checkProofOfWork returns true if H(challenge ∥ nonce) starts with GrindingBits zero bits
+func (s radixTwoFri) checkProofOfWork(challenge []byte, nonce uint64) bool {
+	if s.config.GrindingBits == 0 {
+		return true
+	}
+	var bNonce [8]byte
+	binary.BigEndian.PutUint64(bNonce[:], nonce)
+	s.h.Reset()
+	_, _ = s.h.Write(challenge)
+	_, _ = s.h.Write(bNonce[:])
+	digest := s.h.Sum(nil)
+	s.h.Reset()
+
+	nbBits := s.config.GrindingBits
+	for i := 0; nbBits > 0; i++ {
+		if i >= len(digest) {
+			return false
+		}
+		if nbBits < 8 {
+			return digest[i]>>(8-nbBits) == 0
+		}
+		if digest[i] != 0 {
+			return false
+		}
+		nbBits -= 8
+	}
+	return true
+}
+
+// deriveQueries returns the initial positions of the NbQueries queries, derived from the seed
+func (s radixTwoFri) deriveQueries(seed []byte) []int {
+	res := make([]int, s.config.NbQueries)
+	var bPos, bCardinality big.Int
+	bCardinality.SetUint64(s.domain.Cardinality)
+	var bIndex [8]byte
+	for i := range res {
+		binary.BigEndian.PutUint64(bIndex[:], uint64(i))
+		s.h.Reset()
+		_, _ = s.h.Write(seed)
+		_, _ = s.h.Write(bIndex[:])
+		bPos.SetBytes(s.h.Sum(nil))
+		bPos.Mod(&bPos, &bCardinality)
+		res[i] = int(bPos.Uint64())
+	}
+	s.h.Reset()
+	return res
+}
+
+// BuildProofOfProximity generates a proof that a function, given as an oracle from
 // the verifier point of view, is in fact δ-close to a polynomial.
-// * salt is a variable for multi rounds, it allows to generate different challenges using Fiat Shamir
-// * p is in evaluation form
-func (s radixTwoFri) buildProofOfProximitySingleRound(salt fr.Element, p []fr.Element) (Round, error) {
+func (s radixTwoFri) BuildProofOfProximity(p []fr.Element) (ProofOfProximity, error) {
 
-	// the proof will contain nbSteps Interactions
-	var res Round
-	res.Interactions = make([][2]MerkleProof, s.nbSteps)
+	// evaluate p, in natural order
+	_p := make([]fr.Element, s.domain.Cardinality)
+	copy(_p, p)
+	s.domain.FFT(_p, fft.DIF)
+	fft.BitReverse(_p)
 
 	// Fiat Shamir transcript to derive the challenges. The xᵢ are used to fold the
 	// polynomials.
-	// During the i-th round, the prover has a polynomial P of degree n. The verifier sends
-	// xᵢ∈ Fᵣ to the prover. The prover expresses F in Fᵣ[X,Y]/<Y-X²> as
-	// P₀(Y)+X P₁(Y) where P₀, P₁ are of degree n/2, and he then folds the polynomial
-	// by replacing x by xᵢ.
-	xis := make([]string, s.nbSteps+1)
-	for i := 0; i < s.nbSteps; i++ {
-		xis[i] = paddNaming(fmt.Sprintf("x%d", i), fr.Bytes)
-	}
-	xis[s.nbSteps] = paddNaming("s0", fr.Bytes)
-	fs := fiatshamir.NewTranscript(s.h, xis...)
+	// During the i-th step, the prover has a polynomial P of degree n. The verifier sends
+	// xᵢ∈ Fᵣ to the prover. The prover expresses P as ∑ₜ Xᵗ Pₜ(Xᵏ) where the Pₜ are of
+	// degree n/k, and he then folds the polynomial into ∑ₜ xᵢᵗ Pₜ.
+	fs, xis := s.newTranscript()
 
-	// the salt is binded to the first challenge, to ensure the challenges
-	// are different at each round.
-	err := fs.Bind(xis[0], salt.Marshal())
-	if err != nil {
-		return Round{}, err
-	}
+	proof, _, err := s.buildProofOfProximity(&fs, xis, _p)
+	return proof, err
+}
+
+// buildProofOfProximity generates the proof of proximity of the evaluations (in natural order)
+// of a polynomial on the domain, using the challenges xis of the transcript fs, see newTranscript.
+// It returns the proof, and the initial positions of the queries.
+func (s radixTwoFri) buildProofOfProximity(fs *fiatshamir.Transcript, xis []string, evaluations []fr.Element) (ProofOfProximity, []int, error) {
+
+	var proof ProofOfProximity
 
 	// step 1 : fold the polynomial using the xi
 
 	// evalsAtRound stores the list of the nbSteps polynomial evaluations, each evaluation
-	// corresponds to the evaluation o the folded polynomial at round i.
+	// corresponds to the evaluation of the folded polynomial at step i, committed in trees[i].
 	evalsAtRound := make([][]fr.Element, s.nbSteps)
-
-	// evaluate p and sort the result
-	_p := make([]fr.Element, s.domain.Cardinality)
-	copy(_p, p)
+	trees := make([]*merkletree.MemoryTree, s.nbSteps)
+	proof.Rounds = make([]Round, s.nbSteps)
+	_p := evaluations
 
 	// gInv inverse of the generator of the cyclic group of size the size of the polynomial.
-	// The size of the cyclic group is ρ*s.domainSize, and not s.domainSize.
+	// The size of the cyclic group is ρ⁻¹*s.domainSize, and not s.domainSize.
 	var gInv fr.Element
 	gInv.Set(&s.domain.GeneratorInv)
+	bArity := big.NewInt(int64(s.config.FoldingArity))
 
 	for i := 0; i < s.nbSteps; i++ {
 
-		evalsAtRound[i] = sort(_p)
+		evalsAtRound[i] = _p
 
-		// compute the root hash, needed to derive xi
-		t := merkletree.New(s.h)
-		for k := 0; k < len(_p); k++ {
-			t.Push(evalsAtRound[i][k].Marshal())
-		}
-		rh := t.Root()
-		err := fs.Bind(xis[i], rh)
+		// compute the Merkle cap, needed to derive xi
+		t, err := s.commit(_p)
 		if err != nil {
-			return res, err
+			return proof, nil, err
+		}
+		trees[i] = t
+		proof.Rounds[i].MerkleCap = t.Cap(s.config.CapHeight)
+		if err := fs.Bind(xis[i], capDigest(proof.Rounds[i].MerkleCap)); err != nil {
+			return proof, nil, err
 		}
 
 		// derive the challenge
 		bxi, err := fs.ComputeChallenge(xis[i])
 		if err != nil {
-			return res, err
+			return proof, nil, err
 		}
 		var xi fr.Element
 		xi.SetBytes(bxi)
 
-		// fold _p, reusing its memory
-		_p = foldPolynomialLagrangeBasis(evalsAtRound[i], gInv, xi)
+		// fold _p
+		_p = s.foldPolynomialLagrangeBasis(_p, gInv, xi)
 
-		// g <- g²
-		gInv.Square(&gInv)
+		// g <- gᵏ
+		gInv.Exp(gInv, bArity)
 
 	}
 
-	// last round, provide the evaluation. The fully folded polynomial is of size rho. It should
-	// correspond to the evaluation of a polynomial of degree 1 on ρ points, so those points
-	// are supposed to be on a line.
-	res.Evaluation.Set(&_p[0])
+	// last step, provide the coefficients of the fully folded polynomial.
+	if len(_p) > 1 {
+		finalDomain := fft.NewDomain(uint64(len(_p)))
+		finalDomain.FFTInverse(_p, fft.DIF)
+		fft.BitReverse(_p)
+	}
+	proof.FinalPolynomial = _p[:s.finalSize]
 
-	// step 2: provide the Merkle proofs of the queries
+	// step 2: proof of work
+	for i := range proof.FinalPolynomial {
+		if err := fs.Bind(xis[s.nbSteps], proof.FinalPolynomial[i].Marshal()); err != nil {
+			return proof, nil, err
+		}
+	}
+	grindingChallenge, err := fs.ComputeChallenge(xis[s.nbSteps])
+	if err != nil {
+		return proof, nil, err
+	}
+	for !s.checkProofOfWork(grindingChallenge, proof.Nonce) {
+		proof.Nonce++
+		if proof.Nonce == maxNonce {
+			return proof, nil, ErrProofOfWorkNotFound
+		}
+	}
+
+	// step 3: open the leaves containing the queries
 
 	// derive the verifier queries
-	err = fs.Bind(xis[s.nbSteps], res.Evaluation.Marshal())
-	if err != nil {
-		return res, err
+	var bNonce [8]byte
+	binary.BigEndian.PutUint64(bNonce[:], proof.Nonce)
+	if err := fs.Bind(xis[s.nbSteps+1], bNonce[:]); err != nil {
+		return proof, nil, err
 	}
-	binSeed, err := fs.ComputeChallenge(xis[s.nbSteps])
+	binSeed, err := fs.ComputeChallenge(xis[s.nbSteps+1])
 	if err != nil {
-		return res, err
+		return proof, nil, err
 	}
-	var bPos, bCardinality big.Int
-	bPos.SetBytes(binSeed)
-	bCardinality.SetUint64(s.domain.Cardinality)
-	bPos.Mod(&bPos, &bCardinality)
-	si := s.deriveQueriesPositions(int(bPos.Uint64()), int(s.domain.Cardinality))
+	queries := s.deriveQueries(binSeed)
 
+	// at the i-th step, the query at si[i] lies in the leaf of index si[i+1]
+	indices := s.leafIndices(queries)
 	for i := 0; i < s.nbSteps; i++ {
-
-		// build proofs of queries at s[i]
-		t := merkletree.New(s.h)
-		err := t.SetIndex(uint64(si[i]))
+		proof.Rounds[i].Openings, err = s.openLeaves(trees[i], evalsAtRound[i], indices[i])
 		if err != nil {
-			return res, err
+			return proof, nil, err
 		}
-		for k := 0; k < len(evalsAtRound[i]); k++ {
-			t.Push(evalsAtRound[i][k].Marshal())
-		}
-		mr, ProofSet, _, numLeaves := t.Prove()
-
-		// c denotes the entry that contains the full Merkle proof. The entry 1-c will
-		// only contain 2 elements, which are the neighbor point, and the hash of the
-		// first point. The remaining of the Merkle path is common to both the original
-		// point and its neighbor.
-		c := si[i] % 2
-		res.Interactions[i][c] = MerkleProof{mr, ProofSet, numLeaves}
-		res.Interactions[i][1-c] = MerkleProof{
-			mr,
-			make([][]byte, 2),
-			numLeaves,
-		}
-		res.Interactions[i][1-c].ProofSet[0] = evalsAtRound[i][si[i]+1-2*c].Marshal()
-		s.h.Reset()
-		_, err = s.h.Write(res.Interactions[i][c].ProofSet[0])
-		if err != nil {
-			return res, err
-		}
-		res.Interactions[i][1-c].ProofSet[1] = s.h.Sum(nil)
-
 	}
 
-	return res, nil
-
+	return proof, queries, nil
 }
 
-// BuildProofOfProximity generates a proof that a function, given as an oracle from
-// the verifier point of view, is in fact δ-close to a polynomial.
-func (s radixTwoFri) BuildProofOfProximity(p []fr.Element) (ProofOfProximity, error) {
-
-	// the proof will contain nbSteps Interactions
-	var proof ProofOfProximity
-	proof.Rounds = make([]Round, nbRounds)
-
-	// evaluate p
-	// evaluate p and sort the result
-	_p := make([]fr.Element, s.domain.Cardinality)
-	copy(_p, p)
-	s.domain.FFT(_p, fft.DIF)
-	fft.BitReverse(_p)
+// VerifyProofOfProximity verifies the proof, by checking each query one
+// by one.
+func (s radixTwoFri) VerifyProofOfProximity(proof ProofOfProximity) error {
+	fs, xis := s.newTranscript()
+	_, _, err := s.verifyProofOfProximity(&fs, xis, proof)
+	return err
+}
 
-	var err error
-	var salt, one fr.Element
-	one.SetOne()
-	for i := 0; i < nbRounds; i++ {
-		proof.Rounds[i], err = s.buildProofOfProximitySingleRound(salt, _p)
-		if err != nil {
-			return proof, err
+// leafIndices returns, for each folding step i, the indices of the leaves containing
+// the queries, indices[i][q] = si[i+1] where si are the positions of the q-th query.
+func (s radixTwoFri) leafIndices(queries []int) [][]uint64 {
+	res := make([][]uint64, s.nbSteps)
+	for i := range res {
+		res[i] = make([]uint64, len(queries))
+	}
+	for q := range queries {
+		si := s.deriveQueriesPositions(queries[q], int(s.domain.Cardinality))
+		for i := range res {
+			res[i][q] = uint64(si[i+1])
 		}
-		salt.Add(&salt, &one)
 	}
-
-	return proof, nil
+	return res
 }
 
-// verifyProofOfProximitySingleRound verifies the proof of proximity. It returns an error if the
-// verification fails.
-func (s radixTwoFri) verifyProofOfProximitySingleRound(salt fr.Element, proof Round) error {
+// verifyProofOfProximity verifies the proof of proximity, using the challenges xis of the
+// transcript fs, see newTranscript. It returns the initial positions of the queries, and
+// the opened leaves of the first step, by index.
+func (s radixTwoFri) verifyProofOfProximity(fs *fiatshamir.Transcript, xis []string, proof ProofOfProximity) ([]int, map[uint64][]fr.Element, error) {
 
-	// Fiat Shamir transcript to derive the challenges
-	xis := make([]string, s.nbSteps+1)
-	for i := 0; i < s.nbSteps; i++ {
-		xis[i] = paddNaming(fmt.Sprintf("x%d", i), fr.Bytes)
+	// check the shape of the proof
+	if len(proof.Rounds) != s.nbSteps {
+		return nil, nil, ErrNbQueries
 	}
-	xis[s.nbSteps] = paddNaming("s0", fr.Bytes)
-	fs := fiatshamir.NewTranscript(s.h, xis...)
-
-	xi := make([]fr.Element, s.nbSteps)
-
-	// the salt is binded to the first challenge, to ensure the challenges
-	// are different at each round.
-	err := fs.Bind(xis[0], salt.Marshal())
-	if err != nil {
-		return err
+	if uint64(len(proof.FinalPolynomial)) != s.finalSize {
+		return nil, nil, ErrLowDegree
 	}
 
+	// Fiat Shamir transcript to derive the challenges, bound to the Merkle caps
+	xi := make([]fr.Element, s.nbSteps)
 	for i := 0; i < s.nbSteps; i++ {
-		err := fs.Bind(xis[i], proof.Interactions[i][0].MerkleRoot)
+		err := fs.Bind(xis[i], capDigest(proof.Rounds[i].MerkleCap))
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		bxi, err := fs.ComputeChallenge(xis[i])
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 		xi[i].SetBytes(bxi)
 	}
 
-	// derive the verifier queries
-	// for i := 0; i < len(proof.evaluation); i++ {
-	// 	err := fs.Bind(xis[s.nbSteps], proof.evaluation[i].Marshal())
-	// 	if err != nil {
-	// 		return err
-	// 	}
-	// }
-	err = fs.Bind(xis[s.nbSteps], proof.Evaluation.Marshal())
-	if err != nil {
-		return err
+	// check the proof of work
+	for i := range proof.FinalPolynomial {
+		if err := fs.Bind(xis[s.nbSteps], proof.FinalPolynomial[i].Marshal()); err != nil {
+			return nil, nil, err
+		}
 	}
-	binSeed, err := fs.ComputeChallenge(xis[s.nbSteps])
+	grindingChallenge, err := fs.ComputeChallenge(xis[s.nbSteps])
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	if !s.checkProofOfWork(grindingChallenge, proof.Nonce) {
+		return nil, nil, ErrProofOfWork
 	}
-	var bPos, bCardinality big.Int
-	bPos.SetBytes(binSeed)
-	bCardinality.SetUint64(s.domain.Cardinality)
-	bPos.Mod(&bPos, &bCardinality)
-	si := s.deriveQueriesPositions(int(bPos.Uint64()), int(s.domain.Cardinality))
 
-	// for each round check the Merkle proof and the correctness of the folding
+	// derive the verifier queries
+	var bNonce [8]byte
+	binary.BigEndian.PutUint64(bNonce[:], proof.Nonce)
+	if err := fs.Bind(xis[s.nbSteps+1], bNonce[:]); err != nil {
+		return nil, nil, err
+	}
+	binSeed, err := fs.ComputeChallenge(xis[s.nbSteps+1])
+	if err != nil {
+		return nil, nil, err
+	}
+	queries := s.deriveQueries(binSeed)
 
-	// current size of the polynomial
-	var accGInv fr.Element
-	accGInv.Set(&s.domain.GeneratorInv)
+	// generator of the domain of the fully folded polynomial
+	var gFinal fr.Element
+	gFinal.Set(&s.domain.Generator)
+	bArity := big.NewInt(int64(s.config.FoldingArity))
 	for i := 0; i < s.nbSteps; i++ {
+		gFinal.Exp(gFinal, bArity)
+	}
 
-		// correctness of Merkle proof
-		// c is the entry containing the full Merkle proof.
-		c := si[i] % 2
-		res := merkletree.VerifyProof(
-			s.h,
-			proof.Interactions[i][c].MerkleRoot,
-			proof.Interactions[i][c].ProofSet,
-			uint64(si[i]),
-			proof.Interactions[i][c].numLeaves,
-		)
-		if !res {
-			return ErrMerklePath
+	// check the openings of the leaves containing the queries
+	indices := s.leafIndices(queries)
+	leaves := make([]map[uint64][]fr.Element, s.nbSteps)
+	nbLeaves := s.domain.Cardinality
+	for i := 0; i < s.nbSteps; i++ {
+		nbLeaves /= uint64(s.config.FoldingArity)
+		leaves[i], err = s.verifyLeaves(proof.Rounds[i].MerkleCap, indices[i], proof.Rounds[i].Openings, nbLeaves)
+		if err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// we verify the Merkle proof for the neighbor query, to do that we have
-		// to pick the full Merkle proof of the first entry, stripped off of the leaf and
-		// the first node. We replace the leaf and the first node by the leaf and the first
-		// node of the partial Merkle proof, since the leaf and the first node of both proofs
-		// are the only entries that differ.
-		ProofSet := make([][]byte, len(proof.Interactions[i][c].ProofSet))
-		copy(ProofSet[2:], proof.Interactions[i][c].ProofSet[2:])
-		ProofSet[0] = proof.Interactions[i][1-c].ProofSet[0]
-		ProofSet[1] = proof.Interactions[i][1-c].ProofSet[1]
-		res = merkletree.VerifyProof(
-			s.h,
-			proof.Interactions[i][1-c].MerkleRoot,
-			ProofSet,
-			uint64(si[i]+1-2*c),
-			proof.Interactions[i][1-c].numLeaves,
-		)
-		if !res {
-			return ErrMerklePath
+	// for each query check the correctness of the folding
+	for q := range queries {
+		if err := s.verifyQuery(queries[q], xi, gFinal, leaves, proof.FinalPolynomial); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// correctness of the folding
-		if i < s.nbSteps-1 {
-
-			var fe, fo, l, r, fn fr.Element
-
-			// l = P(gⁱ), r = P(g^{i+n/2})
-			l.SetBytes(proof.Interactions[i][0].ProofSet[0])
-			r.SetBytes(proof.Interactions[i][1].ProofSet[0])
+	return queries, leaves[0], nil
 
-			// (g^{si[i]}, g^{si[i]+1}) is the fiber of g^{2*si[i]}. The system to solve
-			// (for P₀(g^{2si[i]}), P₀(g^{2si[i]}) ) is:
-			// P(g^{si[i]}) = P₀(g^{2si[i]}) +  g^{si[i]/2}*P₀(g^{2si[i]})
-			// P(g^{si[i]+1}) = P₀(g^{2si[i]}) -  g^{si[i]/2}*P₀(g^{2si[i]})
-			bm := big.NewInt(int64(si[i] / 2))
-			var ginv fr.Element
-			ginv.Exp(accGInv, bm)
-			fe.Add(&l, &r)                                      // P₁(g²ⁱ) (to be multiplied by 2⁻¹)
-			fo.Sub(&l, &r).Mul(&fo, &ginv)                      // P₀(g²ⁱ) (to be multiplied by 2⁻¹)
-			fo.Mul(&fo, &xi[i]).Add(&fo, &fe).Mul(&fo, &twoInv) // P₀(g²ⁱ) + xᵢ * P₁(g²ⁱ)
+}
 
-			fn.SetBytes(proof.Interactions[i+1][si[i+1]%2].ProofSet[0])
+// verifyQuery checks the consistency of the successive foldings of the query at position
+// pos, from the initial polynomial to the fully folded polynomial finalPolynomial.
+// * leaves[i] are the opened leaves of the i-th step, by index, whose Merkle proofs were verified
+// * gFinal is the generator of the domain of the fully folded polynomial.
+func (s radixTwoFri) verifyQuery(pos int, xi []fr.Element, gFinal fr.Element, leaves []map[uint64][]fr.Element, finalPolynomial []fr.Element) error {
 
-			if !fo.Equal(&fn) {
-				return ErrProximityTestFolding
-			}
+	si := s.deriveQueriesPositions(pos, int(s.domain.Cardinality))
 
-			// next inverse generator
-			accGInv.Square(&accGInv)
-		}
-
-	}
+	// current size of the domain, and inverse of its generator
+	size := s.domain.Cardinality
+	var accGInv, yInv, folded fr.Element
+	accGInv.Set(&s.domain.GeneratorInv)
+	bArity := big.NewInt(int64(s.config.FoldingArity))
 
-	// last transition
-	var fe, fo, l, r fr.Element
+	for i := 0; i < s.nbSteps; i++ {
 
-	l.SetBytes(proof.Interactions[s.nbSteps-1][0].ProofSet[0])
-	r.SetBytes(proof.Interactions[s.nbSteps-1][1].ProofSet[0])
+		// leaf of index si[i+1], containing si[i], copied since it is folded in place
+		nbLeaves := size / uint64(s.config.FoldingArity)
+		v := make([]fr.Element, s.config.FoldingArity)
+		copy(v, leaves[i][uint64(si[i+1])])
 
-	_si := si[s.nbSteps-1] / 2
+		// the value folded at the previous step should be in the leaf
+		if i > 0 && !v[uint64(si[i])/nbLeaves].Equal(&folded) {
+			return ErrProximityTestFolding
+		}
 
-	accGInv.Exp(accGInv, big.NewInt(int64(_si)))
+		// correctness of the folding: the leaf contains the evaluations on
+		// g^{si[i+1]}*<ζ>
+		yInv.Exp(accGInv, big.NewInt(int64(si[i+1])))
+		folded = s.foldCoset(v, yInv, xi[i])
 
-	fe.Add(&l, &r)                                                // P₁(g²ⁱ) (to be multiplied by 2⁻¹)
-	fo.Sub(&l, &r).Mul(&fo, &accGInv)                             // P₀(g²ⁱ) (to be multiplied by 2⁻¹)
-	fo.Mul(&fo, &xi[s.nbSteps-1]).Add(&fo, &fe).Mul(&fo, &twoInv) // P₀(g²ⁱ) + xᵢ * P₁(g²ⁱ)
+		// next inverse generator
+		accGInv.Exp(accGInv, bArity)
+		size = nbLeaves
+	}
 
-	// Last step: the final evaluation should be the evaluation of a degree 0 polynomial,
-	// so it must be constant.
-	if !fo.Equal(&proof.Evaluation) {
+	// Last step: the fully folded value should be the evaluation of the final polynomial
+	var x, eval fr.Element
+	x.Exp(gFinal, big.NewInt(int64(si[s.nbSteps])))
+	for i := len(finalPolynomial) - 1; i >= 0; i-- {
+		eval.Mul(&eval, &x).Add(&eval, &finalPolynomial[i])
+	}
+	if !eval.Equal(&folded) {
 		return ErrProximityTestFolding
 	}
 
 	return nil
 }
-
-// VerifyProofOfProximity verifies the proof, by checking each interaction one
-// by one.
-func (s radixTwoFri) VerifyProofOfProximity(proof ProofOfProximity) error {
-
-	var salt, one fr.Element
-	one.SetOne()
-	for i := 0; i < nbRounds; i++ {
-		err := s.verifyProofOfProximitySingleRound(salt, proof.Rounds[i])
-		if err != nil {
-			return err
-		}
-		salt.Add(&salt, &one)
-	}
-	return nil
-
-}
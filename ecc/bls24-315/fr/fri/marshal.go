@@ -0,0 +1,394 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package fri
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/consensys/gnark-crypto/accumulator/merkletree"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+)
+
+var (
+	ErrNonCanonicalElement = errors.New("fri: non canonical encoding of a field element")
+	ErrInvalidProofSet     = errors.New("fri: invalid encoding of a Merkle path")
+	ErrProofTooLarge       = errors.New("fri: the proof is too large")
+)
+
+// maxProofSize upper bound on the lengths read while decoding, as in merkletree.MultiProof
+const maxProofSize = 1 << 20
+
+// maxNodeSize upper bound on the size of the Merkle nodes, the digests of the hash functions
+// used in practice being at most 512 bits long
+const maxNodeSize = 64
+
+// maxNbRounds upper bound on the number of rounds of a ProofOfProximity, each folding step
+// dividing the size of the domain, a uint64, by at least 2
+const maxNbRounds = 64
+
+// The encodings are big endian. Byte slices and slices of field elements are prefixed by
+// their length as a uint32. Lists of Merkle nodes are encoded compactly: the number of
+// nodes, and, if there are nodes, the size of a node (uint32) followed by the concatenated
+// nodes. Merkle paths ([leaf ∥ node_1 ∥ .. ]) are encoded as the number of entries, the
+// leaf, and the nodes in the same way. The openings of the leaves use the encoding of
+// merkletree.MultiProof. The decoders reject the lengths larger than maxProofSize.
+
+// WriteTo writes the binary encoding of a Round: the nodes of the Merkle cap, followed
+// by the openings
+func (round *Round) WriteTo(w io.Writer) (int64, error) {
+	enc := encoder{w: w}
+	enc.writeNodes(round.MerkleCap)
+	enc.writeMultiProof(&round.Openings)
+	return enc.n, enc.err
+}
+
+// ReadFrom decodes a Round from reader
+func (round *Round) ReadFrom(r io.Reader) (int64, error) {
+	dec := decoder{r: r}
+	round.MerkleCap = dec.readNodes()
+	dec.readMultiProof(&round.Openings)
+	return dec.n, dec.err
+}
+
+// WriteTo writes the binary encoding of a ProofOfProximity: the ID, the number of rounds,
+// the rounds, the final polynomial and the nonce.
+func (proof *ProofOfProximity) WriteTo(w io.Writer) (int64, error) {
+	enc := encoder{w: w}
+	enc.writeBytes(proof.ID)
+	enc.writeUint32(uint32(len(proof.Rounds)))
+	for i := range proof.Rounds {
+		if enc.err != nil {
+			break
+		}
+		n, err := proof.Rounds[i].WriteTo(w)
+		enc.n += n
+		enc.err = err
+	}
+	enc.writeElements(proof.FinalPolynomial)
+	enc.writeUint64(proof.Nonce)
+	return enc.n, enc.err
+}
+
+// ReadFrom decodes a ProofOfProximity from reader
+func (proof *ProofOfProximity) ReadFrom(r io.Reader) (int64, error) {
+	dec := decoder{r: r}
+	proof.ID = dec.readBytes()
+
+	nbRounds := dec.readUint32()
+	if dec.err != nil {
+		return dec.n, dec.err
+	}
+	if nbRounds > maxNbRounds {
+		return dec.n, ErrProofTooLarge
+	}
+	proof.Rounds = make([]Round, nbRounds)
+	for i := range proof.Rounds {
+		n, err := proof.Rounds[i].ReadFrom(r)
+		dec.n += n
+		if err != nil {
+			return dec.n, err
+		}
+	}
+
+	proof.FinalPolynomial = dec.readElements()
+	proof.Nonce = dec.readUint64()
+	return dec.n, dec.err
+}
+
+// WriteTo writes the binary encoding of an OpeningProof
+func (proof *OpeningProof) WriteTo(w io.Writer) (int64, error) {
+	enc := encoder{w: w}
+	enc.writeProofSet(proof.ProofSet)
+	enc.writeElement(&proof.ClaimedValue)
+	return enc.n, enc.err
+}
+
+// ReadFrom decodes an OpeningProof from reader
+func (proof *OpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := decoder{r: r}
+	proof.ProofSet = dec.readProofSet()
+	dec.readElement(&proof.ClaimedValue)
+	return dec.n, dec.err
+}
+
+// WriteTo writes the binary encoding of a BatchOpeningProof: the claimed values of each
+// polynomial, the proof of proximity, the number of polynomials, and the openings of
+// each polynomial.
+func (proof *BatchOpeningProof) WriteTo(w io.Writer) (int64, error) {
+	enc := encoder{w: w}
+	enc.writeUint32(uint32(len(proof.ClaimedValues)))
+	for i := range proof.ClaimedValues {
+		enc.writeElements(proof.ClaimedValues[i])
+	}
+	if enc.err != nil {
+		return enc.n, enc.err
+	}
+
+	n, err := proof.ProofOfProximity.WriteTo(w)
+	enc.n += n
+	if err != nil {
+		return enc.n, err
+	}
+
+	enc.writeUint32(uint32(len(proof.Openings)))
+	for i := range proof.Openings {
+		enc.writeMultiProof(&proof.Openings[i])
+	}
+
+	return enc.n, enc.err
+}
+
+// ReadFrom decodes a BatchOpeningProof from reader
+func (proof *BatchOpeningProof) ReadFrom(r io.Reader) (int64, error) {
+	dec := decoder{r: r}
+	nbPolynomials := dec.readLength()
+	if dec.err != nil {
+		return dec.n, dec.err
+	}
+	proof.ClaimedValues = make([][]fr.Element, nbPolynomials)
+	for i := range proof.ClaimedValues {
+		proof.ClaimedValues[i] = dec.readElements()
+	}
+	if dec.err != nil {
+		return dec.n, dec.err
+	}
+
+	n, err := proof.ProofOfProximity.ReadFrom(r)
+	dec.n += n
+	if err != nil {
+		return dec.n, err
+	}
+
+	nbPolynomials = dec.readLength()
+	if dec.err != nil {
+		return dec.n, dec.err
+	}
+	proof.Openings = make([]merkletree.MultiProof, nbPolynomials)
+	for i := range proof.Openings {
+		dec.readMultiProof(&proof.Openings[i])
+	}
+
+	return dec.n, dec.err
+}
+
+// encoder writes binary encodings to w, keeping track of the number of bytes written. Once
+// an error occurred, the subsequent writes are ignored.
+type encoder struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (enc *encoder) write(b []byte) {
+	if enc.err != nil {
+		return
+	}
+	var written int
+	written, enc.err = enc.w.Write(b)
+	enc.n += int64(written)
+}
+
+func (enc *encoder) writeUint32(v uint32) {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	enc.write(buf[:])
+}
+
+func (enc *encoder) writeUint64(v uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	enc.write(buf[:])
+}
+
+func (enc *encoder) writeBytes(b []byte) {
+	enc.writeUint32(uint32(len(b)))
+	enc.write(b)
+}
+
+func (enc *encoder) writeElement(e *fr.Element) {
+	buf := e.Bytes()
+	enc.write(buf[:])
+}
+
+func (enc *encoder) writeElements(v []fr.Element) {
+	enc.writeUint32(uint32(len(v)))
+	for i := range v {
+		enc.writeElement(&v[i])
+	}
+}
+
+// writeProofSet writes the Merkle path [leaf ∥ node_1 ∥ ..]: the number of entries, the leaf,
+// the size of the nodes and the nodes
+func (enc *encoder) writeProofSet(proofSet [][]byte) {
+	enc.writeUint32(uint32(len(proofSet)))
+	if len(proofSet) == 0 {
+		return
+	}
+	enc.writeBytes(proofSet[0])
+	enc.writeNodeData(proofSet[1:])
+}
+
+// writeNodes writes the number of nodes, the size of the nodes and the nodes
+func (enc *encoder) writeNodes(nodes [][]byte) {
+	enc.writeUint32(uint32(len(nodes)))
+	enc.writeNodeData(nodes)
+}
+
+// writeNodeData writes the size of the nodes and the concatenated nodes, if any
+func (enc *encoder) writeNodeData(nodes [][]byte) {
+	if len(nodes) == 0 {
+		return
+	}
+	nodeSize := len(nodes[0])
+	if nodeSize > maxNodeSize && enc.err == nil {
+		enc.err = ErrInvalidProofSet
+	}
+	for i := 1; i < len(nodes); i++ {
+		if len(nodes[i]) != nodeSize && enc.err == nil {
+			enc.err = ErrInvalidProofSet
+		}
+	}
+	enc.writeUint32(uint32(nodeSize))
+	for i := range nodes {
+		enc.write(nodes[i])
+	}
+}
+
+func (enc *encoder) writeMultiProof(proof *merkletree.MultiProof) {
+	if enc.err != nil {
+		return
+	}
+	var n int64
+	n, enc.err = proof.WriteTo(enc.w)
+	enc.n += n
+}
+
+// decoder reads binary encodings from r, keeping track of the number of bytes read. Once
+// an error occurred, the subsequent reads are ignored.
+type decoder struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+func (dec *decoder) read(b []byte) {
+	if dec.err != nil {
+		return
+	}
+	var read int
+	read, dec.err = io.ReadFull(dec.r, b)
+	dec.n += int64(read)
+}
+
+func (dec *decoder) readUint32() uint32 {
+	var buf [4]byte
+	dec.read(buf[:])
+	return binary.BigEndian.Uint32(buf[:])
+}
+
+func (dec *decoder) readUint64() uint64 {
+	var buf [8]byte
+	dec.read(buf[:])
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// readLength reads a length, bounded by maxProofSize
+func (dec *decoder) readLength() uint32 {
+	n := dec.readUint32()
+	if dec.err == nil && n > maxProofSize {
+		dec.err = ErrProofTooLarge
+	}
+	return n
+}
+
+func (dec *decoder) readBytes() []byte {
+	n := dec.readLength()
+	if dec.err != nil || n == 0 {
+		return nil
+	}
+	res := make([]byte, n)
+	dec.read(res)
+	return res
+}
+
+func (dec *decoder) readElement(e *fr.Element) {
+	var buf [fr.Bytes]byte
+	dec.read(buf[:])
+	if dec.err != nil {
+		return
+	}
+	e.SetBytes(buf[:])
+	if e.Bytes() != buf {
+		dec.err = ErrNonCanonicalElement
+	}
+}
+
+func (dec *decoder) readElements() []fr.Element {
+	n := dec.readLength()
+	if dec.err != nil || n == 0 {
+		return nil
+	}
+	res := make([]fr.Element, n)
+	for i := range res {
+		dec.readElement(&res[i])
+	}
+	return res
+}
+
+func (dec *decoder) readProofSet() [][]byte {
+	n := dec.readLength()
+	if dec.err != nil || n == 0 {
+		return nil
+	}
+	leaf := dec.readBytes()
+	return append([][]byte{leaf}, dec.readNodeData(n-1)...)
+}
+
+func (dec *decoder) readNodes() [][]byte {
+	return dec.readNodeData(dec.readLength())
+}
+
+func (dec *decoder) readNodeData(n uint32) [][]byte {
+	if dec.err != nil || n == 0 {
+		return nil
+	}
+	nodeSize := dec.readUint32()
+	if dec.err != nil {
+		return nil
+	}
+	if nodeSize == 0 || nodeSize > maxNodeSize {
+		dec.err = ErrInvalidProofSet
+		return nil
+	}
+	res := make([][]byte, n)
+	for i := range res {
+		res[i] = make([]byte, nodeSize)
+		dec.read(res[i])
+	}
+	return res
+}
+
+func (dec *decoder) readMultiProof(proof *merkletree.MultiProof) {
+	if dec.err != nil {
+		return
+	}
+	var n int64
+	n, dec.err = proof.ReadFrom(dec.r)
+	dec.n += n
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package fri
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+)
+
+// serializable object, with a binary encoding
+type serializable interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+// roundTrip encodes from and decodes it in to, and checks that both are equal
+func roundTrip(t *testing.T, from, to serializable) {
+	t.Helper()
+	var buf bytes.Buffer
+	written, err := from.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(buf.Len()) {
+		t.Fatal("wrong number of bytes written")
+	}
+	read, err := to.ReadFrom(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != written {
+		t.Fatal("wrong number of bytes read")
+	}
+	if !reflect.DeepEqual(from, to) {
+		t.Fatal("reconstructed object doesn't match the original")
+	}
+
+	// truncated encodings should be rejected
+	truncated := reflect.New(reflect.TypeOf(to).Elem()).Interface().(serializable)
+	if _, err := truncated.ReadFrom(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Fatal("decoding a truncated encoding should fail")
+	}
+}
+
+func TestSerialization(t *testing.T) {
+
+	const size = 64
+	config := testConfig
+	config.CapHeight = 2
+	iop := RADIX_2_FRI.New(size, sha256.New(), config)
+	p := make([]fr.Element, size)
+	for i := range p {
+		p[i].SetRandom()
+	}
+
+	proof, err := iop.BuildProofOfProximity(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.ID = []byte("fri")
+	var _proof ProofOfProximity
+	roundTrip(t, &proof, &_proof)
+	if err := iop.VerifyProofOfProximity(_proof); err != nil {
+		t.Fatal(err)
+	}
+	var round Round
+	roundTrip(t, &proof.Rounds[1], &round)
+
+	// the decoded opening proof can be verified
+	openingProof, err := iop.Open(p, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _openingProof OpeningProof
+	roundTrip(t, &openingProof, &_openingProof)
+	if err := iop.VerifyOpening(5, _openingProof, _proof); err != nil {
+		t.Fatal(err)
+	}
+
+	// batch opening proof
+	pcs := RADIX_2_FRI.NewPCS(size, sha256.New(), config)
+	digest, err := pcs.Commit(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := [][]fr.Element{make([]fr.Element, 2)}
+	points[0][0].SetRandom()
+	points[0][1].SetRandom()
+	batchProof, err := pcs.BatchOpen([][]fr.Element{p}, []Digest{digest}, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _batchProof BatchOpeningProof
+	roundTrip(t, &batchProof, &_batchProof)
+	if err := pcs.BatchVerify([]Digest{digest}, points, _batchProof); err != nil {
+		t.Fatal(err)
+	}
+
+	// non canonical field element
+	var buf bytes.Buffer
+	if _, err := openingProof.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	copy(b[len(b)-fr.Bytes:], fr.Modulus().Bytes())
+	if _, err := _openingProof.ReadFrom(bytes.NewReader(b)); err != ErrNonCanonicalElement {
+		t.Fatal("non canonical field elements should be rejected")
+	}
+
+	// oversized length prefixes
+	var oversized bytes.Buffer
+	enc := encoder{w: &oversized}
+	enc.writeUint32(maxProofSize + 1)
+	for _, v := range []serializable{&ProofOfProximity{}, &BatchOpeningProof{}} {
+		if _, err := v.ReadFrom(bytes.NewReader(oversized.Bytes())); err != ErrProofTooLarge {
+			t.Fatalf("expected %v, got %v", ErrProofTooLarge, err)
+		}
+	}
+	oversized.Reset()
+	enc.writeBytes(proof.ID)
+	enc.writeUint32(maxNbRounds + 1)
+	if _, err := _proof.ReadFrom(bytes.NewReader(oversized.Bytes())); err != ErrProofTooLarge {
+		t.Fatal("proofs with too many rounds should be rejected")
+	}
+	oversized.Reset()
+	enc.writeUint32(1)
+	enc.writeUint32(maxProofSize + 1)
+	if _, err := _batchProof.ReadFrom(bytes.NewReader(oversized.Bytes())); err != ErrProofTooLarge {
+		t.Fatal("oversized claimed values should be rejected")
+	}
+
+	// oversized Merkle caps and nodes
+	oversized.Reset()
+	enc.writeUint32(maxProofSize + 1)
+	if _, err := round.ReadFrom(bytes.NewReader(oversized.Bytes())); err != ErrProofTooLarge {
+		t.Fatal("oversized Merkle caps should be rejected")
+	}
+	oversized.Reset()
+	enc.writeUint32(1)
+	enc.writeUint32(maxNodeSize + 1)
+	if _, err := round.ReadFrom(bytes.NewReader(oversized.Bytes())); err != ErrInvalidProofSet {
+		t.Fatal("oversized Merkle nodes should be rejected")
+	}
+	oversized.Reset()
+	enc.writeUint32(maxProofSize + 1)
+	if _, err := _openingProof.ReadFrom(bytes.NewReader(oversized.Bytes())); err != ErrProofTooLarge {
+		t.Fatal("oversized Merkle paths should be rejected")
+	}
+
+	// Merkle caps with nodes of different sizes can't be encoded
+	proof.Rounds[1].MerkleCap[0] = proof.Rounds[1].MerkleCap[0][1:]
+	if _, err := proof.WriteTo(&buf); err != ErrInvalidProofSet {
+		t.Fatal("encoding an invalid Merkle cap should fail")
+	}
+}
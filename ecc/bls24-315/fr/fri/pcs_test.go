@@ -0,0 +1,144 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package fri
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+)
+
+func TestBatchOpen(t *testing.T) {
+
+	const size = 60
+	pcs := RADIX_2_FRI.NewPCS(size, sha256.New(), testConfig)
+
+	// polynomials of different sizes, opened at different points
+	polynomials := make([][]fr.Element, 3)
+	digests := make([]Digest, len(polynomials))
+	points := make([][]fr.Element, len(polynomials))
+	for i := range polynomials {
+		polynomials[i] = make([]fr.Element, size-10*i)
+		for j := range polynomials[i] {
+			polynomials[i][j].SetRandom()
+		}
+		var err error
+		digests[i], err = pcs.Commit(polynomials[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		points[i] = make([]fr.Element, i+1)
+		for j := range points[i] {
+			points[i][j].SetRandom()
+		}
+	}
+	points[2][0].Set(&points[0][0])
+
+	proof, err := pcs.BatchOpen(polynomials, digests, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range polynomials {
+		for j := range points[i] {
+			expected := eval(polynomials[i], points[i][j])
+			if !proof.ClaimedValues[i][j].Equal(&expected) {
+				t.Fatal("wrong claimed value")
+			}
+		}
+	}
+	if err := pcs.BatchVerify(digests, points, proof); err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong claimed value
+	var one fr.Element
+	one.SetOne()
+	proof.ClaimedValues[1][1].Add(&proof.ClaimedValues[1][1], &one)
+	if err := pcs.BatchVerify(digests, points, proof); err == nil {
+		t.Fatal("verifying a wrong claimed value should have failed")
+	}
+	proof.ClaimedValues[1][1].Sub(&proof.ClaimedValues[1][1], &one)
+
+	// wrong point
+	points[0][0].Add(&points[0][0], &one)
+	if err := pcs.BatchVerify(digests, points, proof); err == nil {
+		t.Fatal("verifying a wrong point should have failed")
+	}
+	points[0][0].Sub(&points[0][0], &one)
+
+	// wrong digest
+	digests[0], digests[1] = digests[1], digests[0]
+	if err := pcs.BatchVerify(digests, points, proof); err == nil {
+		t.Fatal("verifying a wrong digest should have failed")
+	}
+	digests[0], digests[1] = digests[1], digests[0]
+	if err := pcs.BatchVerify(digests, points, proof); err != nil {
+		t.Fatal(err)
+	}
+
+	// points in the domain
+	points[0][0].Set(&pcs.iopp.domain.Generator)
+	if _, err := pcs.BatchOpen(polynomials, digests, points); err != ErrPointInDomain {
+		t.Fatal("opening in the evaluation domain should fail")
+	}
+
+	// too large polynomial
+	if _, err := pcs.Commit(make([]fr.Element, 65)); err != ErrPolynomialSize {
+		t.Fatal("committing to a too large polynomial should fail")
+	}
+}
+
+func TestBatchOpenDegreeBound(t *testing.T) {
+
+	const size = 64
+	pcs := RADIX_2_FRI.NewPCS(size, sha256.New(), testConfig)
+
+	// a prover lifting the size check commits to a polynomial of degree size,
+	// whose DEEP quotient has degree size-1, and opens it at its correct value
+	cheater := pcs
+	cheater.iopp.config.Blowup = 1
+	p := make([]fr.Element, size+1)
+	for i := range p {
+		p[i].SetRandom()
+	}
+	digest, err := cheater.Commit(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := [][]fr.Element{make([]fr.Element, 1)}
+	points[0][0].SetRandom()
+	proof, err := cheater.BatchOpen([][]fr.Element{p}, []Digest{digest}, points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pcs.BatchVerify([]Digest{digest}, points, proof); err == nil {
+		t.Fatal("opening a polynomial of degree size should fail")
+	}
+
+	// the same polynomial, truncated to the bound, is accepted
+	p = p[:size]
+	if digest, err = pcs.Commit(p); err != nil {
+		t.Fatal(err)
+	}
+	if proof, err = pcs.BatchOpen([][]fr.Element{p}, []Digest{digest}, points); err != nil {
+		t.Fatal(err)
+	}
+	if err := pcs.BatchVerify([]Digest{digest}, points, proof); err != nil {
+		t.Fatal(err)
+	}
+}
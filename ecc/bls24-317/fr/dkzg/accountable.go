@@ -0,0 +1,314 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
+)
+
+// CheatingPartiesError is returned by the root node when the contributions of some parties are invalid
+type CheatingPartiesError struct {
+	Ranks []int // ranks of the parties whose contribution is invalid, in increasing order
+}
+
+func (e *CheatingPartiesError) Error() string {
+	return fmt.Sprintf("dkzg: invalid contributions from the parties of ranks %v", e.Ranks)
+}
+
+/*
+VerifiableCommit is Commit, the root node checking the contribution of each party.
+
+The contribution of the party i is its partial commitment Cᵢ = [fᵢ(τ₁)Lᵢ(τ₀)]G₁, computed on its shard of the SRS.
+The root node picks a random point r, and each party sends fᵢ(r) and the commitment Hᵢ of (fᵢ - fᵢ(r))/(Y - r),
+that is a KZG opening proof of Cᵢ against its shard. The openings are batch verified, see checkContributions.
+
+The root node checks the openings against srs.Lagrange, [Lᵢ(τ₀)]G₁ being the first point of the shard
+of the party i.
+
+The root node returns the commitment and the partial commitments of the parties, needed by VerifiableOpen,
+or a *CheatingPartiesError. The other nodes return empty values.
+*/
+func VerifiableCommit(p []fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (Digest, []Digest, error) {
+	if c.Rank() == 0 && len(srs.Lagrange) < c.Size() {
+		return Digest{}, nil, ErrInvalidNbDigests
+	}
+	subCom, err := commitShare(p, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, nil, err
+	}
+	subComs, err := c.Gather(G1AffineToBytes(subCom), 0)
+	if err != nil {
+		return Digest{}, nil, err
+	}
+
+	// random opening point, chosen by the root node
+	var r fr.Element
+	if c.Rank() == 0 {
+		if _, err := r.SetRandom(); err != nil {
+			return Digest{}, nil, err
+		}
+	}
+	if err := broadcastElement(&r, c); err != nil {
+		return Digest{}, nil, err
+	}
+
+	// opening proof of the partial commitment at r
+	contribution, err := openShare(p, r, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, nil, err
+	}
+	frames, err := c.Gather(contribution.marshal(), 0)
+	if err != nil {
+		return Digest{}, nil, err
+	}
+	if c.Rank() != 0 {
+		return Digest{}, nil, nil
+	}
+
+	// Root node
+	var cheaters []int
+	partialDigests := make([]Digest, c.Size())
+	for i := range subComs {
+		partialDigests[i] = BytesToG1Affine(subComs[i])
+	}
+	values, quotients, invalid := decodeContributions(frames, partialDigests)
+	cheaters = append(cheaters, invalid...)
+
+	invalid, err = checkContributions(partialDigests, values, quotients, r, srs, cheaters)
+	if err != nil {
+		return Digest{}, nil, err
+	}
+	if cheaters = append(cheaters, invalid...); len(cheaters) != 0 {
+		return Digest{}, nil, newCheatingPartiesError(cheaters)
+	}
+
+	var digest bls24317.G1Jac
+	for i := range partialDigests {
+		digest.AddMixed(&partialDigests[i])
+	}
+	var res Digest
+	res.FromJacobian(&digest)
+	return res, partialDigests, nil
+}
+
+/*
+VerifiableOpen is Open, the root node checking the contribution of each party against its partial
+commitment, as returned by VerifiableCommit.
+
+The party i sends fᵢ(y) and the commitment Hᵢ of (fᵢ - fᵢ(y))/(Y - y), which is its share of the
+opening proof. The root node computes the claimed digest ∑ᵢfᵢ(y)[Lᵢ(τ₀)]G₁ itself.
+
+partialDigests are only used by the root node, which returns the proof or a *CheatingPartiesError.
+*/
+func VerifiableOpen(p []fr.Element, y fr.Element, srs *SRS, partialDigests []Digest, c communicator.Communicator, nbTasks ...int) (OpeningProof, error) {
+	if c.Rank() == 0 && (len(srs.Lagrange) < c.Size() || len(partialDigests) != c.Size()) {
+		return OpeningProof{}, ErrInvalidNbDigests
+	}
+	contribution, err := openShare(p, y, srs, nbTasks...)
+	if err != nil {
+		return OpeningProof{}, err
+	}
+	frames, err := c.Gather(contribution.marshal(), 0)
+	if err != nil {
+		return OpeningProof{}, err
+	}
+	if c.Rank() != 0 {
+		return OpeningProof{}, nil
+	}
+
+	// Root node
+	values, quotients, cheaters := decodeContributions(frames, partialDigests)
+	invalid, err := checkContributions(partialDigests, values, quotients, y, srs, cheaters)
+	if err != nil {
+		return OpeningProof{}, err
+	}
+	if cheaters = append(cheaters, invalid...); len(cheaters) != 0 {
+		return OpeningProof{}, newCheatingPartiesError(cheaters)
+	}
+
+	var proof OpeningProof
+	var h bls24317.G1Jac
+	for i := range quotients {
+		h.AddMixed(&quotients[i])
+	}
+	proof.H.FromJacobian(&h)
+	if _, err := proof.ClaimedDigest.MultiExp(srs.Lagrange[:len(values)], values, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return OpeningProof{}, err
+	}
+	proof.ClaimedValues = values
+	return proof, nil
+}
+
+// openShare returns the contribution of the node to the opening of F at Y = y: the value
+// fᵢ(y), and the commitment of (fᵢ - fᵢ(y))/(Y - y) on the shard of the node
+func openShare(p []fr.Element, y fr.Element, srs *SRS, nbTasks ...int) (*batchOpeningMessage, error) {
+	if len(p) == 0 || len(p) > len(srs.G1) {
+		return nil, ErrInvalidPolynomialSize
+	}
+	fY := eval(p, y)
+	_p := make([]fr.Element, len(p))
+	copy(_p, p)
+	h := dividePolyByXminusA(_p, fY, y)
+
+	var comH bls24317.G1Affine
+	if len(h) != 0 {
+		var err error
+		if comH, err = commitShare(h, srs, nbTasks...); err != nil {
+			return nil, err
+		}
+	}
+	return &batchOpeningMessage{
+		ClaimedValues:  []fr.Element{fY},
+		ClaimedDigests: []bls24317.G1Affine{comH},
+	}, nil
+}
+
+// decodeContributions decodes the values and quotients sent by the parties, and checks that the
+// points (partialDigests and quotients) are valid. The ranks of the parties whose message or points
+// are invalid are returned.
+func decodeContributions(frames [][]byte, partialDigests []Digest) ([]fr.Element, []bls24317.G1Affine, []int) {
+	values := make([]fr.Element, len(frames))
+	quotients := make([]bls24317.G1Affine, len(frames))
+	var cheaters []int
+	for i := range frames {
+		var msg batchOpeningMessage
+		if err := msg.unmarshal(frames[i]); err != nil || len(msg.ClaimedValues) != 1 {
+			cheaters = append(cheaters, i)
+			continue
+		}
+		values[i] = msg.ClaimedValues[0]
+		var err error
+		if quotients[i], err = checkedG1(&msg.ClaimedDigests[0]); err != nil {
+			cheaters = append(cheaters, i)
+			continue
+		}
+		if partialDigests[i], err = checkedG1(&partialDigests[i]); err != nil {
+			cheaters = append(cheaters, i)
+		}
+	}
+	return values, quotients, cheaters
+}
+
+// checkedG1 returns p in canonical form, or an error if p is not on the curve or not in the subgroup
+func checkedG1(p *bls24317.G1Affine) (bls24317.G1Affine, error) {
+	var res bls24317.G1Affine
+	if p.X.IsZero() && p.Y.IsZero() {
+		return res, nil
+	}
+	b := p.RawBytes()
+	if _, err := res.SetBytes(b[:]); err != nil {
+		return bls24317.G1Affine{}, err
+	}
+	if !res.IsOnCurve() || !res.IsInSubGroup() {
+		return bls24317.G1Affine{}, ErrInvalidMessage
+	}
+	return res, nil
+}
+
+/*
+checkContributions checks the KZG opening proofs of the partial commitments at point, skipping the parties in skip.
+For each party, e(Cᵢ - fᵢ(point)[Lᵢ(τ₀)]G₁ + point.Hᵢ, G₂) = e(Hᵢ, [τ₁]G₂). The checks are batched with
+a random linear combination, and done one by one if the batch check fails, to identify the invalid contributions.
+*/
+func checkContributions(partialDigests []Digest, values []fr.Element, quotients []bls24317.G1Affine, point fr.Element, srs *SRS, skip []int) ([]int, error) {
+	var ranks []int
+	for i := range partialDigests {
+		if !containsRank(skip, i) {
+			ranks = append(ranks, i)
+		}
+	}
+	if len(ranks) == 0 {
+		return nil, nil
+	}
+
+	ok, err := checkOpenings(partialDigests, values, quotients, point, srs, ranks)
+	if err != nil || ok {
+		return nil, err
+	}
+
+	// the batch check failed, find the culprits
+	var cheaters []int
+	for _, i := range ranks {
+		ok, err := checkOpenings(partialDigests, values, quotients, point, srs, []int{i})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			cheaters = append(cheaters, i)
+		}
+	}
+	return cheaters, nil
+}
+
+// checkOpenings checks a random linear combination of the openings of the parties in ranks
+func checkOpenings(partialDigests []Digest, values []fr.Element, quotients []bls24317.G1Affine, point fr.Element, srs *SRS, ranks []int) (bool, error) {
+	// ∑ᵢλᵢ(Cᵢ + point.Hᵢ) - (∑ᵢλᵢfᵢ(point))[Lᵢ(τ₀)]G₁ and ∑ᵢλᵢHᵢ
+	n := len(ranks)
+	bases := make([]bls24317.G1Affine, 0, 3*n)
+	scalars := make([]fr.Element, 0, 3*n)
+	hs := make([]bls24317.G1Affine, 0, n)
+	lambdas := make([]fr.Element, 0, n)
+	var lambda, tmp fr.Element
+	for _, i := range ranks {
+		if n == 1 {
+			lambda.SetOne()
+		} else if _, err := lambda.SetRandom(); err != nil {
+			return false, err
+		}
+		bases = append(bases, partialDigests[i], quotients[i], srs.Lagrange[i])
+		tmp.Mul(&lambda, &point)
+		scalars = append(scalars, lambda, tmp)
+		tmp.Mul(&lambda, &values[i]).Neg(&tmp)
+		scalars = append(scalars, tmp)
+		hs = append(hs, quotients[i])
+		lambdas = append(lambdas, lambda)
+	}
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	var left, right bls24317.G1Affine
+	if _, err := left.MultiExp(bases, scalars, config); err != nil {
+		return false, err
+	}
+	if _, err := right.MultiExp(hs, lambdas, config); err != nil {
+		return false, err
+	}
+	right.Neg(&right)
+
+	return bls24317.PairingCheck(
+		[]bls24317.G1Affine{left, right},
+		[]bls24317.G2Affine{srs.G2[0], srs.G2[1]},
+	)
+}
+
+func containsRank(ranks []int, rank int) bool {
+	for _, r := range ranks {
+		if r == rank {
+			return true
+		}
+	}
+	return false
+}
+
+func newCheatingPartiesError(ranks []int) *CheatingPartiesError {
+	sort.Ints(ranks)
+	return &CheatingPartiesError{Ranks: ranks}
+}
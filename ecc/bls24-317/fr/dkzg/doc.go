@@ -0,0 +1,27 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+// Package dkzg provides a distributed KZG commitment scheme.
+//
+// A bivariate polynomial F(X, Y) = ∑ᵢ fᵢ(Y)Lᵢ(X) is shared among the parties of a world,
+// party i holding fᵢ, and Lᵢ being the i-th Lagrange polynomial on the party domain.
+// The parties exchange messages through a communicator.Communicator.
+//
+// The points of the party domain are the powers of the generator of fft.NewDomain, see
+// PartyDomain. The first versions of the package used a different root of unity, and
+// didn't pad the domain to a power of 2: their SRS and commitments are not compatible
+// with this basis. Their SRS encodings have no header, and are rejected by SRS.ReadFrom.
+package dkzg
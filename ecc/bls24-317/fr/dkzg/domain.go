@@ -0,0 +1,122 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/fft"
+)
+
+var ErrInvalidPartyDomain = errors.New("dkzg: party domain is not consistent with the number of parties")
+
+/*
+PartyDomain is the domain of the X variable of F(X, Y) = ∑ᵢfᵢ(Y)Lᵢ(X), the party i holding fᵢ.
+
+The points of the domain are xᵢ = g.ωⁱ, ω being the generator of the embedded fft.Domain, of size M,
+and g = 1, or g = FrMultiplicativeGen if Coset is set. M is the smallest power of 2 ≥ WorldSize, the
+points xᵢ with WorldSize ≤ i < M are held by no party: the shards are padded with empty shards, fᵢ = 0.
+*/
+type PartyDomain struct {
+	*fft.Domain
+
+	WorldSize int  // number of parties
+	Coset     bool // if set, the domain is the coset g<ω> instead of <ω>
+}
+
+// NewPartyDomain returns the party domain of worldSize parties
+func NewPartyDomain(worldSize int, coset bool) *PartyDomain {
+	return &PartyDomain{
+		Domain:    fft.NewDomain(uint64(worldSize)),
+		WorldSize: worldSize,
+		Coset:     coset,
+	}
+}
+
+// check returns an error if d is not a domain for worldSize parties
+func (d *PartyDomain) check(worldSize int) error {
+	if d == nil || d.Domain == nil || d.WorldSize != worldSize || worldSize <= 0 || d.Cardinality < uint64(worldSize) {
+		return ErrInvalidPartyDomain
+	}
+	return nil
+}
+
+// Shift returns g, such that the points of the domain are g.ωⁱ
+func (d *PartyDomain) Shift() fr.Element {
+	if d.Coset {
+		return d.FrMultiplicativeGen
+	}
+	return fr.One()
+}
+
+// Points returns the M points xᵢ = g.ωⁱ of the domain, padding included
+func (d *PartyDomain) Points() []fr.Element {
+	res := make([]fr.Element, d.Cardinality)
+	res[0] = d.Shift()
+	for i := 1; i < len(res); i++ {
+		res[i].Mul(&res[i-1], &d.Generator)
+	}
+	return res
+}
+
+// vanishing returns Z(x) = xᴹ - gᴹ and M.gᴹ = Z'(xᵢ)xᵢ, Z being the vanishing polynomial of the domain.
+// M being a power of 2, xᴹ is computed with log(M) squarings, independently of x.
+func (d *PartyDomain) vanishing(x fr.Element) (fr.Element, fr.Element) {
+	g := d.Shift()
+	for m := uint64(1); m < d.Cardinality; m <<= 1 {
+		x.Square(&x)
+		g.Square(&g)
+	}
+	var zx, mgm fr.Element
+	zx.Sub(&x, &g)
+	mgm.SetUint64(d.Cardinality).Mul(&mgm, &g)
+	return zx, mgm
+}
+
+/*
+LagrangeAt returns the Lᵢ(x) for all the M points of the domain, padding included.
+
+Lᵢ(X) = (Xᴹ - gᴹ)xᵢ / (M.gᴹ(X - xᵢ)), so that all the Lᵢ(x) are computed with a single batch
+inversion. If x = xₖ, Lᵢ(x) is 1 if i = k, 0 otherwise.
+*/
+func (d *PartyDomain) LagrangeAt(x fr.Element) []fr.Element {
+	points := d.Points()
+	n := len(points)
+
+	// x - xᵢ, and M.gᴹ inverted in the same batch
+	zx, mgm := d.vanishing(x)
+	den := make([]fr.Element, n+1)
+	for i := 0; i < n; i++ {
+		den[i].Sub(&x, &points[i])
+		if den[i].IsZero() {
+			res := make([]fr.Element, n)
+			res[i].SetOne()
+			return res
+		}
+	}
+	den[n] = mgm
+	den = fr.BatchInvert(den)
+
+	var factor fr.Element
+	factor.Mul(&zx, &den[n])
+	res := make([]fr.Element, n)
+	for i := 0; i < n; i++ {
+		res[i].Mul(&points[i], &den[i]).Mul(&res[i], &factor)
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Tianyi Liu and Tiancheng Xie
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package dkzg
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/fft"
+)
+
+func TestBatchOpenMultiPoints(t *testing.T) {
+	runParties(t, func(c communicator.Communicator) error {
+
+		// ζ and ωζ, as in a Plonk prover
+		var zeta, omegaZeta fr.Element
+		zeta.SetString("4321")
+		omegaZeta.Mul(&zeta, &fft.NewDomain(64).Generator)
+		points := [][]fr.Element{
+			{zeta},
+			{zeta, omegaZeta},
+			{omegaZeta},
+			{omegaZeta, zeta},
+		}
+
+		num := len(points)
+		fs := make([][]fr.Element, num)
+		digests := make([]Digest, num)
+		var err error
+		for k := 0; k < num; k++ {
+			fs[k] = polynomial(60-k, uint64(c.Rank()), k)
+			if digests[k], err = AllCommit(fs[k], testSRS[c.Rank()], c); err != nil {
+				return err
+			}
+		}
+
+		proof, err := BatchOpenMultiPoints(fs, digests, points, sha256.New(), testSRS[c.Rank()], c)
+		if err != nil {
+			return err
+		}
+		if c.Rank() != 0 {
+			return nil
+		}
+
+		// claimed values of the parties
+		for k := range points {
+			for j := range points[k] {
+				for i := 0; i < nbParties; i++ {
+					expected := eval(polynomial(60-k, uint64(i), k), points[k][j])
+					if !proof.ClaimedValues[k][j][i].Equal(&expected) {
+						t.Error("inconsistant claimed values")
+					}
+				}
+			}
+		}
+
+		// correct proof
+		if err := BatchVerifyMultiPointsOpening(digests, &proof, points, sha256.New(), testSRS[0]); err != nil {
+			return err
+		}
+
+		// serialization
+		testRoundTrip(t, &proof, &MultiPointsOpeningProof{})
+
+		// wrong points
+		wrongPoints := [][]fr.Element{points[0], points[1], points[0], points[3]}
+		if err := BatchVerifyMultiPointsOpening(digests, &proof, wrongPoints, sha256.New(), testSRS[0]); err == nil {
+			t.Error("verifying with wrong points should have failed")
+		}
+		{
+			// wrong claimed digest
+			saved := proof.ClaimedDigests[1][1]
+			proof.ClaimedDigests[1][1].Add(&saved, &saved)
+			if err := BatchVerifyMultiPointsOpening(digests, &proof, points, sha256.New(), testSRS[0]); err == nil {
+				t.Error("verifying wrong claimed digests should have failed")
+			}
+			proof.ClaimedDigests[1][1] = saved
+		}
+		{
+			// wrong quotient
+			saved := proof.W
+			proof.W.Add(&saved, &saved)
+			if err := BatchVerifyMultiPointsOpening(digests, &proof, points, sha256.New(), testSRS[0]); err == nil {
+				t.Error("verifying wrong quotient should have failed")
+			}
+			proof.W = saved
+		}
+		return nil
+	})
+}
+
+func TestBatchOpenMultiPointsInvalidPoints(t *testing.T) {
+	c := communicator.NewChannels(1)[0]
+	f := polynomial(10, 0)
+	var zeta fr.Element
+	zeta.SetString("4321")
+
+	if _, err := BatchOpenMultiPoints([][]fr.Element{f}, make([]Digest, 1), [][]fr.Element{{}}, sha256.New(), testSRS[0], c); err != ErrInvalidPointSet {
+		t.Fatal("an empty set of points should be rejected")
+	}
+	if _, err := BatchOpenMultiPoints([][]fr.Element{f}, make([]Digest, 1), [][]fr.Element{{zeta, zeta}}, sha256.New(), testSRS[0], c); err != ErrInvalidPointSet {
+		t.Fatal("a set of points with duplicates should be rejected")
+	}
+	if _, err := BatchOpenMultiPoints([][]fr.Element{f}, make([]Digest, 2), [][]fr.Element{{zeta}}, sha256.New(), testSRS[0], c); err != ErrInvalidNbDigests {
+		t.Fatal("inconsistent number of digests should be rejected")
+	}
+}
@@ -18,6 +18,7 @@ package fri
 
 import (
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
@@ -28,17 +29,13 @@ import (
 	"github.com/leanovate/gopter/prop"
 )
 
-// logFiber returns u, v such that {g^u, g^v} = f⁻¹((g²)^{_p})
-func logFiber(_p, _n int) (_u, _v big.Int) {
-	if _p%2 == 0 {
-		_u.SetInt64(int64(_p / 2))
-		_v.SetInt64(int64(_p/2 + _n/2))
-	} else {
-		l := (_n - 1 - _p) / 2
-		_u.SetInt64(int64(_n - 1 - l))
-		_v.SetInt64(int64(_n - 1 - l - _n/2))
-	}
-	return
+// testConfig small configuration, to keep the tests fast
+var testConfig = Config{
+	Blowup:       8,
+	NbQueries:    4,
+	FoldingArity: 2,
+	FinalDegree:  0,
+	GrindingBits: 4,
 }
 
 func randomPolynomial(size uint64, seed int32) []fr.Element {
@@ -50,18 +47,6 @@ func randomPolynomial(size uint64, seed int32) []fr.Element {
 	return p
 }
 
-// convertOrderCanonical convert the index i, an entry in a
-// sorted polynomial, to the corresponding entry in canonical
-// representation. n is the size of the polynomial.
-func convertSortedCanonical(i, n int) int {
-	if i%2 == 0 {
-		return i / 2
-	} else {
-		l := (n - 1 - i) / 2
-		return n - 1 - l
-	}
-}
-
 func TestFRI(t *testing.T) {
 
 	parameters := gopter.DefaultTestParameters()
@@ -70,12 +55,13 @@ func TestFRI(t *testing.T) {
 	properties := gopter.NewProperties(parameters)
 
 	size := 4096
+	rho := int(testConfig.Blowup)
 
 	properties.Property("verifying wrong opening should fail", prop.ForAll(
 
 		func(m int32) bool {
 
-			_s := RADIX_2_FRI.New(uint64(size), sha256.New())
+			_s := RADIX_2_FRI.New(uint64(size), sha256.New(), testConfig)
 			s := _s.(radixTwoFri)
 
 			p := randomPolynomial(uint64(size), m)
@@ -102,7 +88,7 @@ func TestFRI(t *testing.T) {
 
 		func(m int32) bool {
 
-			_s := RADIX_2_FRI.New(uint64(size), sha256.New())
+			_s := RADIX_2_FRI.New(uint64(size), sha256.New(), testConfig)
 			s := _s.(radixTwoFri)
 
 			p := randomPolynomial(uint64(size), m)
@@ -127,7 +113,7 @@ func TestFRI(t *testing.T) {
 	properties.Property("The claimed value of a polynomial should match P(x)", prop.ForAll(
 		func(m int32) bool {
 
-			_s := RADIX_2_FRI.New(uint64(size), sha256.New())
+			_s := RADIX_2_FRI.New(uint64(size), sha256.New(), testConfig)
 			s := _s.(radixTwoFri)
 
 			p := randomPolynomial(uint64(size), m)
@@ -157,37 +143,36 @@ func TestFRI(t *testing.T) {
 
 	properties.Property("Derive queries position: points should belong the correct fiber", prop.ForAll(
 
-		func(m int32) bool {
+		func(m int32, logArity uint8) bool {
 
-			_s := RADIX_2_FRI.New(uint64(size), sha256.New())
+			config := testConfig
+			config.FoldingArity = 1 << logArity
+			_s := RADIX_2_FRI.New(uint64(size), sha256.New(), config)
 			s := _s.(radixTwoFri)
 
 			var g fr.Element
 
-			_m := int(m) % size
+			_m := int(m) % int(s.domain.Cardinality)
 			pos := s.deriveQueriesPositions(_m, int(s.domain.Cardinality))
 			g.Set(&s.domain.Generator)
-			n := int(s.domain.Cardinality)
+			bArity := big.NewInt(int64(config.FoldingArity))
 
 			for i := 0; i < len(pos)-1; i++ {
 
-				u, v := logFiber(pos[i], n)
+				// (gᵢ^{pos[i]})ᵏ = gᵢ₊₁^{pos[i+1]}
+				var g1, g2 fr.Element
+				g1.Exp(g, big.NewInt(int64(pos[i]))).Exp(g1, bArity)
+				g.Exp(g, bArity)
+				g2.Exp(g, big.NewInt(int64(pos[i+1])))
 
-				var g1, g2, g3 fr.Element
-				g1.Exp(g, &u).Square(&g1)
-				g2.Exp(g, &v).Square(&g2)
-				nextPos := convertSortedCanonical(pos[i+1], n/2)
-				g3.Square(&g).Exp(g3, big.NewInt(int64(nextPos)))
-
-				if !g1.Equal(&g2) || !g1.Equal(&g3) {
+				if !g1.Equal(&g2) {
 					return false
 				}
-				g.Square(&g)
-				n = n >> 1
 			}
 			return true
 		},
 		gen.Int32Range(0, int32(rho*size)),
+		gen.UInt8Range(1, 3),
 	))
 
 	properties.Property("verifying a correctly formed proof should succeed", prop.ForAll(
@@ -196,7 +181,7 @@ func TestFRI(t *testing.T) {
 
 			p := randomPolynomial(uint64(size), s)
 
-			iop := RADIX_2_FRI.New(uint64(size), sha256.New())
+			iop := RADIX_2_FRI.New(uint64(size), sha256.New(), testConfig)
 			proof, err := iop.BuildProofOfProximity(p)
 			if err != nil {
 				t.Fatal(err)
@@ -212,6 +197,117 @@ func TestFRI(t *testing.T) {
 
 }
 
+func TestFRIConfig(t *testing.T) {
+
+	size := uint64(200)
+	p := make([]fr.Element, size)
+	for i := range p {
+		p[i].SetRandom()
+	}
+
+	for _, config := range []Config{
+		{Blowup: 2, NbQueries: 3, FoldingArity: 8, FinalDegree: 0, GrindingBits: 0},
+		{Blowup: 4, NbQueries: 3, FoldingArity: 4, FinalDegree: 7, GrindingBits: 2, CapHeight: 2},
+		{Blowup: 16, NbQueries: 3, FoldingArity: 8, FinalDegree: 3, GrindingBits: 8, CapHeight: 4},
+		{Blowup: 2, NbQueries: 3, FoldingArity: 2, FinalDegree: 1000, GrindingBits: 0, CapHeight: 32},
+	} {
+		iop := RADIX_2_FRI.New(size, sha256.New(), config)
+		proof, err := iop.BuildProofOfProximity(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := iop.VerifyProofOfProximity(proof); err != nil {
+			t.Fatal(err)
+		}
+		if len(proof.Rounds) != iop.(radixTwoFri).nbSteps {
+			t.Fatal("wrong number of rounds")
+		}
+		for i := range proof.Rounds {
+			if len(proof.Rounds[i].MerkleCap) > 1<<config.CapHeight {
+				t.Fatal("the Merkle cap is too large")
+			}
+			if len(proof.Rounds[i].Openings.Leaves) > config.NbQueries {
+				t.Fatal("the leaves should be opened once")
+			}
+		}
+
+		// the opening of a position shares the commitment of the proof
+		openingProof, err := iop.Open(p, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := iop.VerifyOpening(3, openingProof, proof); err != nil {
+			t.Fatal(err)
+		}
+
+		// tampered proofs
+		proof.Nonce++
+		if err := iop.VerifyProofOfProximity(proof); err == nil {
+			t.Fatal("verifying a proof with a wrong nonce should have failed")
+		}
+		proof.Nonce--
+		var one fr.Element
+		one.SetOne()
+		proof.FinalPolynomial[0].Add(&proof.FinalPolynomial[0], &one)
+		if err := iop.VerifyProofOfProximity(proof); err == nil {
+			t.Fatal("verifying a proof with a wrong final polynomial should have failed")
+		}
+		proof.FinalPolynomial[0].Sub(&proof.FinalPolynomial[0], &one)
+		proof.Rounds[0].Openings.Leaves[0][0] ^= 1
+		if err := iop.VerifyProofOfProximity(proof); err == nil {
+			t.Fatal("verifying a proof with a wrong opening should have failed")
+		}
+		proof.Rounds[0].Openings.Leaves[0][0] ^= 1
+		proof.Rounds[0].MerkleCap = proof.Rounds[0].MerkleCap[:len(proof.Rounds[0].MerkleCap)-1]
+		if err := iop.VerifyProofOfProximity(proof); err == nil {
+			t.Fatal("verifying a proof with a truncated Merkle cap should have failed")
+		}
+	}
+
+	// a random function is far from a low degree polynomial
+	config := Config{Blowup: 4, NbQueries: 10, FoldingArity: 4, FinalDegree: 0}
+	iop := RADIX_2_FRI.New(size, sha256.New(), config).(radixTwoFri)
+	q := make([]fr.Element, iop.domain.Cardinality)
+	for i := range q {
+		q[i].SetRandom()
+	}
+	proof, err := iop.BuildProofOfProximity(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iop.VerifyProofOfProximity(proof); err == nil {
+		t.Fatal("verifying the proximity of a random function should have failed")
+	}
+
+	// invalid configurations
+	for _, config := range []Config{
+		{Blowup: 3, NbQueries: 1, FoldingArity: 2},
+		{Blowup: 2, NbQueries: 0, FoldingArity: 2},
+		{Blowup: 2, NbQueries: 1, FoldingArity: 16},
+		{Blowup: 2, NbQueries: 1, FoldingArity: 2, GrindingBits: 33},
+		{Blowup: 2, NbQueries: 1, FoldingArity: 2, GrindingBits: 65},
+		{Blowup: 2, NbQueries: 1, FoldingArity: 2, CapHeight: -1},
+	} {
+		if err := config.Check(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatal("the configuration should be invalid")
+		}
+	}
+}
+
+func TestSecurityBits(t *testing.T) {
+	if DefaultConfig().SecurityBits() < 100 {
+		t.Fatal("the default configuration should have at least 100 bits of security")
+	}
+	config := Config{Blowup: 16, NbQueries: 20, FoldingArity: 4, GrindingBits: 16}
+	if config.SecurityBits() != 96 {
+		t.Fatal("wrong number of security bits")
+	}
+	config.NbQueries = 1000
+	if config.SecurityBits() != fr.Bits {
+		t.Fatal("the security should be capped by the size of the field")
+	}
+}
+
 // Benchmarks
 
 func BenchmarkProximityVerification(b *testing.B) {
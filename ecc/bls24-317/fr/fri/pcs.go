@@ -0,0 +1,359 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package fri
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/accumulator/merkletree"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/fft"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+)
+
+var (
+	ErrPolynomialSize  = errors.New("the polynomial is larger than the size of the commitment scheme")
+	ErrPointInDomain   = errors.New("the opening point should not be in the evaluation domain")
+	ErrInvalidPointSet = errors.New("the number of polynomials, digests, points and claimed values don't match")
+	ErrDeepQuotient    = errors.New("the DEEP quotient doesn't match the proof of proximity")
+)
+
+// PCS polynomial commitment scheme based on FRI.
+//
+// A polynomial is committed with the Merkle cap of its evaluations on the domain of
+// the Reed Solomon code, with the same leaves as the first step of the proof of
+// proximity. To prove that the polynomials pᵢ, committed in the Cᵢ, evaluate to vᵢⱼ
+// at the points zᵢⱼ (outside of the domain), the prover runs a single FRI instance on
+// the DEEP quotient
+//
+//	Q(X) = ∑ᵢⱼ αᵏ (pᵢ(X)-vᵢⱼ)/(X-zᵢⱼ)
+//
+// where α is a random challenge and k enumerates the pairs (i, j). FRI only proves that
+// its input has degree < n, n being the size of the scheme rounded up to a power of two,
+// so it runs on the degree corrected quotient (1+βX)Q(X), β being a fresh challenge, which
+// enforces deg(Q) < n-1, that is deg(pᵢ) < n. At each query x of FRI, the pᵢ(x) are
+// opened against the Cᵢ, from which the verifier recomputes (1+βx)Q(x), and compares it
+// with the value in the first step of the proof of proximity.
+type PCS struct {
+	iopp radixTwoFri
+}
+
+// BatchOpeningProof proof of the openings of several polynomials at several points
+type BatchOpeningProof struct {
+
+	// ClaimedValues[i][j] value of the i-th polynomial at its j-th point
+	ClaimedValues [][]fr.Element
+
+	// ProofOfProximity proof of proximity of the degree corrected DEEP quotient
+	ProofOfProximity ProofOfProximity
+
+	// Openings[i] opening of the i-th polynomial at the queries of the proof
+	// of proximity
+	Openings []merkletree.MultiProof
+}
+
+// NewPCS creates a new FRI based polynomial commitment scheme, for polynomials of size
+// at most size, rounded up to the next power of two. If no configuration is provided,
+// DefaultConfig is used. It panics if the configuration is not supported.
+func (iopp IOPP) NewPCS(size uint64, h hash.Hash, config ...Config) PCS {
+	return PCS{iopp: iopp.New(size, h, config...).(radixTwoFri)}
+}
+
+// Commit commits to the polynomial p, given in canonical form. The digest is the
+// concatenation of the nodes of the Merkle cap.
+func (pcs PCS) Commit(p []fr.Element) (Digest, error) {
+	evaluations, err := pcs.evaluate(p)
+	if err != nil {
+		return nil, err
+	}
+	t, err := pcs.iopp.commit(evaluations)
+	if err != nil {
+		return nil, err
+	}
+	return capDigest(t.Cap(pcs.iopp.config.CapHeight)), nil
+}
+
+// BatchOpen opens each polynomial polynomials[i] at the points points[i], outside of the
+// evaluation domain. The digests are the commitments of the polynomials.
+func (pcs PCS) BatchOpen(polynomials [][]fr.Element, digests []Digest, points [][]fr.Element) (BatchOpeningProof, error) {
+
+	var res BatchOpeningProof
+	s := pcs.iopp
+
+	if len(polynomials) != len(digests) || len(polynomials) != len(points) {
+		return res, ErrInvalidPointSet
+	}
+	if err := pcs.checkPoints(points); err != nil {
+		return res, err
+	}
+
+	// compute the claimed values
+	res.ClaimedValues = make([][]fr.Element, len(polynomials))
+	for i := range polynomials {
+		res.ClaimedValues[i] = make([]fr.Element, len(points[i]))
+		for j := range points[i] {
+			res.ClaimedValues[i][j] = eval(polynomials[i], points[i][j])
+		}
+	}
+
+	// derive α
+	fs, xis := s.newTranscript(paddNaming("alpha", fr.Bytes), paddNaming("beta", fr.Bytes))
+	alpha, err := pcs.deriveAlpha(&fs, digests, points, res.ClaimedValues)
+	if err != nil {
+		return res, err
+	}
+
+	// derive β, for the degree correction of the quotient
+	beta, err := pcs.deriveBeta(&fs)
+	if err != nil {
+		return res, err
+	}
+
+	// evaluate the polynomials on the domain, in natural order
+	evaluations := make([][]fr.Element, len(polynomials))
+	for i := range polynomials {
+		if evaluations[i], err = pcs.evaluate(polynomials[i]); err != nil {
+			return res, err
+		}
+	}
+
+	// x ↦ x, on the domain
+	x := make([]fr.Element, s.domain.Cardinality)
+	x[0].SetOne()
+	for i := 1; i < len(x); i++ {
+		x[i].Mul(&x[i-1], &s.domain.Generator)
+	}
+
+	// compute the DEEP quotient ∑ᵢⱼ αᵏ (pᵢ(X)-vᵢⱼ)/(X-zᵢⱼ), on the domain
+	quotient := make([]fr.Element, s.domain.Cardinality)
+	denominator := make([]fr.Element, s.domain.Cardinality)
+	var acc, tmp, one fr.Element
+	acc.SetOne()
+	one.SetOne()
+	for i := range points {
+		for j := range points[i] {
+			for k := range denominator {
+				denominator[k].Sub(&x[k], &points[i][j])
+			}
+			denominator = fr.BatchInvert(denominator)
+			for k := range quotient {
+				tmp.Sub(&evaluations[i][k], &res.ClaimedValues[i][j]).
+					Mul(&tmp, &denominator[k]).
+					Mul(&tmp, &acc)
+				quotient[k].Add(&quotient[k], &tmp)
+			}
+			acc.Mul(&acc, &alpha)
+		}
+	}
+
+	// degree correction (1+βX)Q(X), on the domain
+	for k := range quotient {
+		tmp.Mul(&beta, &x[k]).Add(&tmp, &one)
+		quotient[k].Mul(&quotient[k], &tmp)
+	}
+
+	// run FRI on the quotient, in the same transcript
+	var queries []int
+	res.ProofOfProximity, queries, err = s.buildProofOfProximity(&fs, xis, quotient)
+	if err != nil {
+		return res, err
+	}
+
+	// open the polynomials at the queries
+	indices := s.leafIndices(queries)[0]
+	res.Openings = make([]merkletree.MultiProof, len(polynomials))
+	for i := range evaluations {
+		t, err := s.commit(evaluations[i])
+		if err != nil {
+			return res, err
+		}
+		if res.Openings[i], err = s.openLeaves(t, evaluations[i], indices); err != nil {
+			return res, err
+		}
+	}
+
+	return res, nil
+}
+
+// BatchVerify verifies the proof that the polynomials committed in digests evaluate to
+// proof.ClaimedValues at the points. It returns an error if the verification fails.
+func (pcs PCS) BatchVerify(digests []Digest, points [][]fr.Element, proof BatchOpeningProof) error {
+
+	s := pcs.iopp
+
+	if len(digests) != len(points) || len(digests) != len(proof.ClaimedValues) || len(digests) != len(proof.Openings) {
+		return ErrInvalidPointSet
+	}
+	for i := range points {
+		if len(points[i]) != len(proof.ClaimedValues[i]) {
+			return ErrInvalidPointSet
+		}
+	}
+	if err := pcs.checkPoints(points); err != nil {
+		return err
+	}
+
+	// derive α
+	fs, xis := s.newTranscript(paddNaming("alpha", fr.Bytes), paddNaming("beta", fr.Bytes))
+	alpha, err := pcs.deriveAlpha(&fs, digests, points, proof.ClaimedValues)
+	if err != nil {
+		return err
+	}
+
+	// derive β, for the degree correction of the quotient
+	beta, err := pcs.deriveBeta(&fs)
+	if err != nil {
+		return err
+	}
+
+	// verify the proof of proximity of the quotient, in the same transcript
+	queries, quotientLeaves, err := s.verifyProofOfProximity(&fs, xis, proof.ProofOfProximity)
+	if err != nil {
+		return err
+	}
+
+	// verify the openings of the polynomials at the queries
+	indices := s.leafIndices(queries)[0]
+	nbLeaves := s.domain.Cardinality / uint64(s.config.FoldingArity)
+	leaves := make([]map[uint64][]fr.Element, len(digests))
+	for i := range digests {
+		merkleCap, err := s.splitCap(digests[i])
+		if err != nil {
+			return err
+		}
+		leaves[i], err = s.verifyLeaves(merkleCap, indices, proof.Openings[i], nbLeaves)
+		if err != nil {
+			return err
+		}
+	}
+
+	// at each query, recompute the quotient from the openings of the polynomials
+	var x, acc, tmp, one fr.Element
+	one.SetOne()
+	values := make([]fr.Element, len(digests))
+	for q := range queries {
+		slot := uint64(queries[q]) / nbLeaves
+		for i := range digests {
+			values[i] = leaves[i][indices[q]][slot]
+		}
+
+		// value of the quotient in the first step of FRI, whose Merkle proof
+		// was verified with the proof of proximity
+		v := quotientLeaves[indices[q]]
+
+		// ∑ᵢⱼ αᵏ (pᵢ(x)-vᵢⱼ)/(x-zᵢⱼ)
+		x.Exp(s.domain.Generator, big.NewInt(int64(queries[q])))
+		var quotient fr.Element
+		acc.SetOne()
+		for i := range points {
+			for j := range points[i] {
+				var den fr.Element
+				den.Sub(&x, &points[i][j]).Inverse(&den)
+				tmp.Sub(&values[i], &proof.ClaimedValues[i][j]).
+					Mul(&tmp, &den).
+					Mul(&tmp, &acc)
+				quotient.Add(&quotient, &tmp)
+				acc.Mul(&acc, &alpha)
+			}
+		}
+
+		// (1+βx)Q(x)
+		tmp.Mul(&beta, &x).Add(&tmp, &one)
+		quotient.Mul(&quotient, &tmp)
+		if !quotient.Equal(&v[slot]) {
+			return ErrDeepQuotient
+		}
+	}
+
+	return nil
+}
+
+// evaluate returns the evaluations of p on the domain, in natural order
+func (pcs PCS) evaluate(p []fr.Element) ([]fr.Element, error) {
+	s := pcs.iopp
+	if uint64(len(p))*s.config.Blowup > s.domain.Cardinality {
+		return nil, ErrPolynomialSize
+	}
+	res := make([]fr.Element, s.domain.Cardinality)
+	copy(res, p)
+	s.domain.FFT(res, fft.DIF)
+	fft.BitReverse(res)
+	return res, nil
+}
+
+// checkPoints returns an error if one of the points is in the evaluation domain
+func (pcs PCS) checkPoints(points [][]fr.Element) error {
+	bCardinality := new(big.Int).SetUint64(pcs.iopp.domain.Cardinality)
+	var one, z fr.Element
+	one.SetOne()
+	for i := range points {
+		for j := range points[i] {
+			if z.Exp(points[i][j], bCardinality).Equal(&one) {
+				return ErrPointInDomain
+			}
+		}
+	}
+	return nil
+}
+
+// deriveAlpha derives the challenge α used to combine the quotients, bound to
+// the digests, the points and the claimed values
+func (pcs PCS) deriveAlpha(fs *fiatshamir.Transcript, digests []Digest, points, claimedValues [][]fr.Element) (fr.Element, error) {
+	var alpha fr.Element
+	id := paddNaming("alpha", fr.Bytes)
+	for i := range digests {
+		if err := fs.Bind(id, digests[i]); err != nil {
+			return alpha, err
+		}
+		for j := range points[i] {
+			if err := fs.Bind(id, points[i][j].Marshal()); err != nil {
+				return alpha, err
+			}
+			if err := fs.Bind(id, claimedValues[i][j].Marshal()); err != nil {
+				return alpha, err
+			}
+		}
+	}
+	b, err := fs.ComputeChallenge(id)
+	if err != nil {
+		return alpha, err
+	}
+	alpha.SetBytes(b)
+	return alpha, nil
+}
+
+// deriveBeta derives the challenge β of the degree correction, after α
+func (pcs PCS) deriveBeta(fs *fiatshamir.Transcript) (fr.Element, error) {
+	var beta fr.Element
+	b, err := fs.ComputeChallenge(paddNaming("beta", fr.Bytes))
+	if err != nil {
+		return beta, err
+	}
+	beta.SetBytes(b)
+	return beta, nil
+}
+
+// eval returns p(x), p in canonical form
+func eval(p []fr.Element, x fr.Element) fr.Element {
+	var res fr.Element
+	for i := len(p) - 1; i >= 0; i-- {
+		res.Mul(&res, &x).Add(&res, &p[i])
+	}
+	return res
+}
@@ -0,0 +1,464 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
+package permutation
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/dkzg"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/fft"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+)
+
+var ErrNbProducts = errors.New("there should be one product per party")
+
+/*
+DistributedProof proof that the dkzg commitments of t1 and t2 come from the same vector but permuted,
+t1 and t2 being distributed among the parties: the party i holds the slices t1ᵢ and t2ᵢ, of size n.
+
+Each party builds the accumulation polynomial zᵢ of its slices, zᵢ(1) = 1 and
+zᵢ(νʲ⁺¹)(ε-t2ᵢ(νʲ)) = zᵢ(νʲ)(ε-t1ᵢ(νʲ)), ν being the generator of the domain of size n. The last
+step of the accumulation gives the product Pᵢ = ∏ⱼ(ε-t1ᵢ(νʲ))/(ε-t2ᵢ(νʲ)) of the party, and the
+vectors are permuted iff ∏ᵢPᵢ = 1. The products are the only values exchanged between the parties
+outside of the dkzg protocol.
+
+For each party, with Lⱼ the Lagrange polynomials of the domain, the quotient qᵢ = qloᵢ + Xⁿqhiᵢ is:
+
+	(L₀(zᵢ-1) + α(X-νⁿ⁻¹)(zᵢ(νX)(ε-t2ᵢ) - zᵢ(ε-t1ᵢ)) + α²Lₙ₋₁(zᵢ(ε-t1ᵢ) - Pᵢ(ε-t2ᵢ))) / (Xⁿ-1)
+*/
+type DistributedProof struct {
+
+	// size of the slices held by each party
+	size int
+
+	// generator of the fft domain, used for shifting the evaluation point
+	g fr.Element
+
+	// commitments of t1 & t2, the permuted vectors, and z, the accumulation
+	// polynomial
+	t1, t2, z dkzg.Digest
+
+	// commitments to the low and high parts of the quotient polynomial
+	qLo, qHi dkzg.Digest
+
+	// products[i] is the product of the accumulation of the party i
+	products []fr.Element
+
+	// opening proofs of t1, t2, z, qLo, qHi (in that order)
+	batchedProof dkzg.BatchOpeningProof
+
+	// shifted opening proof of z
+	shiftedProof dkzg.OpeningProof
+}
+
+// evaluateLocalAccumulation returns the values of the accumulation polynomial on the
+// domain, in natural order, and the product of the accumulation.
+func evaluateLocalAccumulation(lt1, lt2 []fr.Element, epsilon fr.Element) ([]fr.Element, fr.Element) {
+
+	s := len(lt1)
+	d := make([]fr.Element, s)
+	for i := 0; i < s; i++ {
+		d[i].Sub(&epsilon, &lt2[i])
+	}
+	d = fr.BatchInvert(d)
+
+	z := make([]fr.Element, s+1)
+	z[0].SetOne()
+	var t fr.Element
+	for i := 0; i < s; i++ {
+		t.Sub(&epsilon, &lt1[i])
+		z[i+1].Mul(&z[i], &t).Mul(&z[i+1], &d[i])
+	}
+
+	return z[:s], z[s]
+}
+
+// evaluateDistributedQuotient returns the values of the quotient of the party on the coset
+// of the domain d2 of size 2n, in natural order, from the values of t1, t2 and z on that coset.
+func evaluateDistributedQuotient(lt1, lt2, lz []fr.Element, product, epsilon, alpha fr.Element, d *fft.Domain, d2 *fft.Domain) []fr.Element {
+
+	s := len(lz)
+	n := int(d.Cardinality)
+
+	// u - 1, u - νⁿ⁻¹ for the points u of the coset, uⁿ - 1 (which only takes 2 values) and n,
+	// inverted in the same batch
+	var one, u fr.Element
+	one.SetOne()
+	den := make([]fr.Element, 2*s+3)
+	u.Set(&d2.FrMultiplicativeGen)
+	for k := 0; k < s; k++ {
+		den[k].Sub(&u, &one)
+		den[s+k].Sub(&u, &d.GeneratorInv)
+		u.Mul(&u, &d2.Generator)
+	}
+	den[2*s].Exp(d2.FrMultiplicativeGen, big.NewInt(int64(n)))
+	den[2*s+1].Neg(&den[2*s]).Sub(&den[2*s+1], &one)
+	den[2*s].Sub(&den[2*s], &one)
+	den[2*s+2].SetUint64(uint64(n))
+	den = fr.BatchInvert(den)
+
+	var alphaSquare, nInv, lastInv fr.Element
+	alphaSquare.Square(&alpha)
+	nInv.Set(&den[2*s+2])
+	lastInv.Mul(&d.GeneratorInv, &nInv)
+
+	res := make([]fr.Element, s)
+	var a, b, c fr.Element
+	u.Set(&d2.FrMultiplicativeGen)
+	for k := 0; k < s; k++ {
+
+		// L₀(u)(z-1) / (uⁿ-1)
+		res[k].Sub(&lz[k], &one).Mul(&res[k], &den[k]).Mul(&res[k], &nInv)
+
+		// z(νu)(ε-t2) - z(ε-t1), νu being the point k+2 of the coset
+		a.Sub(&epsilon, &lt2[k]).Mul(&a, &lz[(k+2)%s])
+		b.Sub(&epsilon, &lt1[k]).Mul(&b, &lz[k])
+		c.Sub(&a, &b).
+			Mul(&c, &alpha).
+			Mul(&c, &den[2*s+k%2])
+		a.Sub(&u, &d.GeneratorInv)
+		c.Mul(&c, &a)
+		res[k].Add(&res[k], &c)
+
+		// Lₙ₋₁(u)(z(ε-t1) - P(ε-t2)) / (uⁿ-1)
+		a.Sub(&epsilon, &lt2[k]).Mul(&a, &product)
+		c.Sub(&b, &a).
+			Mul(&c, &alphaSquare).
+			Mul(&c, &den[s+k]).
+			Mul(&c, &lastInv)
+		res[k].Add(&res[k], &c)
+
+		u.Mul(&u, &d2.Generator)
+	}
+	return res
+}
+
+// evaluateOnCoset returns the values of the polynomial of coefficients p on the coset of d2, in natural order
+func evaluateOnCoset(p []fr.Element, d2 *fft.Domain) []fr.Element {
+	res := make([]fr.Element, d2.Cardinality)
+	copy(res, p)
+	d2.FFT(res, fft.DIF, true)
+	fft.BitReverse(res)
+	return res
+}
+
+// bindProducts binds the products of the parties to challenge
+func bindProducts(fs *fiatshamir.Transcript, challenge string, products []fr.Element) error {
+	for i := range products {
+		b := products[i].Bytes()
+		if err := fs.Bind(challenge, b[:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProveDistributed generates a proof that t1 and t2 are the same but permuted, the party
+// holding the slices t1 and t2 of the vectors. The size of t1 and t2 should be the same
+// and a power of 2, for all the parties.
+//
+// It's a collective call. The root node returns the proof, the other nodes return an empty proof.
+func ProveDistributed(srs *dkzg.SRS, t1, t2 []fr.Element, c communicator.Communicator) (DistributedProof, error) {
+
+	// res
+	var proof DistributedProof
+	var err error
+
+	// size checking
+	if len(t1) != len(t2) {
+		return proof, ErrIncompatibleSize
+	}
+
+	// create the domains
+	d := fft.NewDomain(uint64(len(t1)))
+	if d.Cardinality != uint64(len(t1)) {
+		return proof, ErrSize
+	}
+	s := int(d.Cardinality)
+	d2 := fft.NewDomain(uint64(2 * s))
+	proof.size = s
+	proof.g.Set(&d.Generator)
+
+	// hash function for Fiat Shamir
+	hFunc := sha256.New()
+
+	// transcript to derive the challenge, all the parties derive the same challenges
+	fs := fiatshamir.NewTranscript(hFunc, "epsilon", "alpha", "eta")
+
+	// commit t1, t2
+	ct1 := make([]fr.Element, s)
+	ct2 := make([]fr.Element, s)
+	copy(ct1, t1)
+	copy(ct2, t2)
+	d.FFTInverse(ct1, fft.DIF)
+	d.FFTInverse(ct2, fft.DIF)
+	fft.BitReverse(ct1)
+	fft.BitReverse(ct2)
+	proof.t1, err = dkzg.AllCommit(ct1, srs, c)
+	if err != nil {
+		return proof, err
+	}
+	proof.t2, err = dkzg.AllCommit(ct2, srs, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// derive challenge for z
+	epsilon, err := deriveRandomness(&fs, "epsilon", &proof.t1, &proof.t2)
+	if err != nil {
+		return proof, err
+	}
+
+	// compute Z and commit it
+	cz, product := evaluateLocalAccumulation(t1, t2, epsilon)
+	d.FFTInverse(cz, fft.DIF)
+	fft.BitReverse(cz)
+	proof.z, err = dkzg.AllCommit(cz, srs, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// exchange the products of the parties
+	productBytes := product.Bytes()
+	bufs := make([][]byte, c.Size())
+	for i := range bufs {
+		bufs[i] = productBytes[:]
+	}
+	received, err := c.AllToAll(bufs)
+	if err != nil {
+		return proof, err
+	}
+	proof.products = make([]fr.Element, len(received))
+	for i := range received {
+		proof.products[i].SetBytes(received[i])
+	}
+
+	// derive challenge used for the folding
+	if err = bindProducts(&fs, "alpha", proof.products); err != nil {
+		return proof, err
+	}
+	alpha, err := deriveRandomness(&fs, "alpha", &proof.z)
+	if err != nil {
+		return proof, err
+	}
+
+	// compute the quotient on the coset of size 2n, and commit its low and high parts
+	lq := evaluateDistributedQuotient(
+		evaluateOnCoset(ct1, d2),
+		evaluateOnCoset(ct2, d2),
+		evaluateOnCoset(cz, d2),
+		product, epsilon, alpha, d, d2,
+	)
+	fft.BitReverse(lq)
+	d2.FFTInverse(lq, fft.DIT, true)
+	cqLo, cqHi := lq[:s], lq[s:]
+	proof.qLo, err = dkzg.AllCommit(cqLo, srs, c)
+	if err != nil {
+		return proof, err
+	}
+	proof.qHi, err = dkzg.AllCommit(cqHi, srs, c)
+	if err != nil {
+		return proof, err
+	}
+
+	// derive the evaluation challenge
+	eta, err := deriveRandomness(&fs, "eta", &proof.qLo, &proof.qHi)
+	if err != nil {
+		return proof, err
+	}
+
+	// compute the opening proofs
+	proof.batchedProof, _, err = dkzg.BatchOpenSinglePoint(
+		[][]fr.Element{
+			ct1,
+			ct2,
+			cz,
+			cqLo,
+			cqHi,
+		},
+		[]dkzg.Digest{
+			proof.t1,
+			proof.t2,
+			proof.z,
+			proof.qLo,
+			proof.qHi,
+		},
+		eta,
+		hFunc,
+		srs,
+		c,
+	)
+	if err != nil {
+		return proof, err
+	}
+
+	var shiftedEta fr.Element
+	shiftedEta.Mul(&eta, &d.Generator)
+	proof.shiftedProof, _, err = dkzg.Open(
+		cz,
+		shiftedEta,
+		srs,
+		c,
+	)
+	if err != nil {
+		return proof, err
+	}
+
+	if c.Rank() != 0 {
+		return DistributedProof{}, nil
+	}
+
+	// done
+	return proof, nil
+
+}
+
+// VerifyDistributed verifies a distributed permutation proof.
+func VerifyDistributed(srs *dkzg.SRS, proof DistributedProof) error {
+
+	// hash function that is used for Fiat Shamir
+	hFunc := sha256.New()
+
+	// transcript to derive the challenge
+	fs := fiatshamir.NewTranscript(hFunc, "epsilon", "alpha", "eta")
+
+	// derive the challenges
+	epsilon, err := deriveRandomness(&fs, "epsilon", &proof.t1, &proof.t2)
+	if err != nil {
+		return err
+	}
+
+	if err = bindProducts(&fs, "alpha", proof.products); err != nil {
+		return err
+	}
+	alpha, err := deriveRandomness(&fs, "alpha", &proof.z)
+	if err != nil {
+		return err
+	}
+
+	eta, err := deriveRandomness(&fs, "eta", &proof.qLo, &proof.qHi)
+	if err != nil {
+		return err
+	}
+
+	// the products of the parties
+	if len(proof.products) != srs.WorldSize {
+		return ErrNbProducts
+	}
+	var one, product fr.Element
+	one.SetOne()
+	product.SetOne()
+	for i := range proof.products {
+		product.Mul(&product, &proof.products[i])
+	}
+	if !product.Equal(&one) {
+		return ErrPermutationProof
+	}
+
+	// the values of the parties
+	values := proof.batchedProof.ClaimedValues
+	if len(values) != 5 || len(proof.batchedProof.ClaimedDigests) != 5 {
+		return dkzg.ErrInvalidNbDigests
+	}
+	for k := range values {
+		if err := dkzg.VerifyClaimedValues(&proof.batchedProof.ClaimedDigests[k], values[k], srs); err != nil {
+			return err
+		}
+	}
+	shiftedValues := proof.shiftedProof.ClaimedValues
+	if err := dkzg.VerifyClaimedValues(&proof.shiftedProof.ClaimedDigest, shiftedValues, srs); err != nil {
+		return err
+	}
+
+	// check the relation for each party
+	var zeta, etaN, l0, last, gInv, alphaSquare, a, b, lhs, rhs fr.Element
+	bs := big.NewInt(int64(proof.size))
+	etaN.Exp(eta, bs)
+	zeta.Sub(&etaN, &one)
+	gInv.Inverse(&proof.g)
+	alphaSquare.Square(&alpha)
+	a.SetUint64(uint64(proof.size))
+	l0.Sub(&eta, &one).Mul(&l0, &a).Inverse(&l0).Mul(&l0, &zeta)
+	last.Sub(&eta, &gInv).Mul(&last, &a).Inverse(&last).Mul(&last, &zeta).Mul(&last, &gInv)
+	for i := range proof.products {
+		t1, t2, z, qLo, qHi := values[0][i], values[1][i], values[2][i], values[3][i], values[4][i]
+
+		// L₀(η)(z(η)-1)
+		lhs.Sub(&z, &one).Mul(&lhs, &l0)
+
+		// α(η-νⁿ⁻¹)(z(νη)(ε-t2(η)) - z(η)(ε-t1(η)))
+		a.Sub(&epsilon, &t2).Mul(&a, &shiftedValues[i])
+		b.Sub(&epsilon, &t1).Mul(&b, &z)
+		rhs.Sub(&a, &b).Mul(&rhs, &alpha)
+		a.Sub(&eta, &gInv)
+		rhs.Mul(&rhs, &a)
+		lhs.Add(&lhs, &rhs)
+
+		// α²Lₙ₋₁(η)(z(η)(ε-t1(η)) - Pᵢ(ε-t2(η)))
+		a.Sub(&epsilon, &t2).Mul(&a, &proof.products[i])
+		rhs.Sub(&b, &a).Mul(&rhs, &alphaSquare).Mul(&rhs, &last)
+		lhs.Add(&lhs, &rhs)
+
+		// (ηⁿ-1)(qlo(η) + ηⁿqhi(η))
+		rhs.Mul(&etaN, &qHi).Add(&rhs, &qLo).Mul(&rhs, &zeta)
+		if !lhs.Equal(&rhs) {
+			return ErrPermutationProof
+		}
+	}
+
+	// check the opening proofs
+	err = dkzg.BatchVerifySinglePoint(
+		[]dkzg.Digest{
+			proof.t1,
+			proof.t2,
+			proof.z,
+			proof.qLo,
+			proof.qHi,
+		},
+		&proof.batchedProof,
+		eta,
+		hFunc,
+		srs,
+	)
+	if err != nil {
+		return err
+	}
+
+	var shiftedEta fr.Element
+	shiftedEta.Mul(&eta, &proof.g)
+	err = dkzg.Verify(&proof.z, &proof.shiftedProof, shiftedEta, srs)
+	if err != nil {
+		return err
+	}
+
+	// check the generator is correct
+	var checkOrder fr.Element
+	checkOrder.Exp(proof.g, big.NewInt(int64(proof.size/2)))
+	if checkOrder.Equal(&one) {
+		return ErrGenerator
+	}
+	checkOrder.Square(&checkOrder)
+	if !checkOrder.Equal(&one) {
+		return ErrGenerator
+	}
+
+	return nil
+}
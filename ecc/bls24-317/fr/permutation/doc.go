@@ -14,5 +14,6 @@
 
 // Code generated by consensys/gnark-crypto DO NOT EDIT
 
-// Package permutation provides an API to build permutation proofs.
+// Package permutation provides an API to build permutation proofs, with kzg commitments, or with
+// dkzg commitments when the permuted vectors are distributed among several parties.
 package permutation
@@ -12,6 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Code generated by consensys/gnark-crypto DO NOT EDIT
+
 package dkzg
 
 import (
@@ -19,28 +21,51 @@ import (
 	"hash"
 	"math/big"
 
+	"github.com/consensys/gnark-crypto/dkzg/communicator"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark-crypto/internal/parallel"
-	"github.com/sunblaze-ucb/simpleMPI/mpi"
 )
 
 var (
 	ErrInvalidNbDigests              = errors.New("dkzg: number of digests is not the same as the number of polynomials")
 	ErrInvalidPolynomialSize         = errors.New("dkzg: invalid polynomial size (larger than SRS or == 0)")
+	ErrInvalidMessage                = errors.New("dkzg: invalid message")
 	ErrVerifyOpeningProof            = errors.New("dkzg: can't verify opening proof")
 	ErrVerifyBatchOpeningSinglePoint = errors.New("dkzg: can't verify batch opening proof at single point")
 	ErrMinSRSSize                    = errors.New("dkzg: minimum srs size is 2")
+	ErrVerifyClaimedValues           = errors.New("dkzg: claimed values are not consistent with the claimed digest")
 )
 
 // Digest commitment of a polynomial.
+//
+// Digests, and slices of digests, are encoded with the Encoder and Decoder of the curve package.
 type Digest = bn254.G1Affine
 
 type SRS struct {
 	G1 []bn254.G1Affine  // G1[i][j] = g^(L_i(\tau[0]) * \tau[1]^j)
-	G2 [2]bn254.G2Affine // G2[0] = g2, G2[1] = g2^tau[0], G2[2] = g2^tau[1]
+	G2 [3]bn254.G2Affine // G2[0] = g2, G2[1] = g2^tau[1], G2[2] = g2^tau[0]
+
+	// Lagrange[k] = g^(L_k(\tau[0])) for all the points of the party domain, Lagrange[Rank] = G1[0].
+	// The root node needs them to open F(X, y), see OpenBivariate.
+	Lagrange []bn254.G1Affine
+
+	// H[0] = g^(h * L_i(\tau[0])), H[1] = g^(h * L_i(\tau[0]) * \tau[1]) blind the share of the party,
+	// HLagrange[k] = g^(h * L_k(\tau[0])) for all the points of the party domain.
+	// They are zero, and nil, if the SRS is not hiding, see NewHidingSRS.
+	H         [2]bn254.G1Affine
+	HLagrange []bn254.G1Affine
+
+	Rank      int  // rank i of the party owning the SRS
+	WorldSize int  // number of parties
+	Coset     bool // the party domain is a coset, see PartyDomain
+}
+
+// PartyDomain returns the party domain of the SRS
+func (srs *SRS) PartyDomain() *PartyDomain {
+	return NewPartyDomain(srs.WorldSize, srs.Coset)
 }
 
 // eval returns p(point) where p is interpreted as a polynomial
@@ -55,66 +80,52 @@ func eval(p []fr.Element, point fr.Element) fr.Element {
 	return res
 }
 
-func init() {
-	mpi.WorldInit("_", "_", "_")
-}
-
-func lagrangeCalc(t uint64, tau0 fr.Element, omega *fr.Element) fr.Element {
-	m := big.NewInt(int64(mpi.WorldSize))
-	mField := new(fr.Element).SetBigInt(m)
-	if omega == nil {
-		fieldSize := fr.Modulus()
-		multiplicativeGroupSize := new(big.Int).Sub(fieldSize, big.NewInt(1))
-		omegaPow := new(big.Int).Div(multiplicativeGroupSize, m)
-		omegaBigInt, _ := new(big.Int).SetString("19103219067921713944291392827692070036145651957329286315305642004821462161904", 10)
-		omega = new(fr.Element).SetBigInt(omegaBigInt)
-		// BUG: side channel attack
-		omega.Exp(*omega, omegaPow)
-	}
-	// R_t(tau0) = ((tau[0]^m - 1) * omega^t) / (m * (tau[0] - omega^t))
-	var lagTau0, omegaPowT, denominator fr.Element
-	omegaPowT.Exp(*omega, big.NewInt(int64(t)))
-	one := fr.One()
-	denominator.Sub(&tau0, &omegaPowT).Mul(&denominator, mField)
-	lagTau0.Exp(tau0, m).Sub(&lagTau0, &one).Mul(&lagTau0, &omegaPowT).Div(&lagTau0, &denominator)
-	return lagTau0
-}
-
-// NewSRS returns a new SRS using alpha as randomness source
+// NewSRS returns the SRS of the party c.Rank() using tau as randomness source
+//
+// domain is the party domain of the c.Size() parties, the default one if nil, see PartyDomain.
 //
 // In production, a SRS generated through MPC should be used.
 //
 // implements io.ReaderFrom and io.WriterTo
-func NewSRS(size uint64, tau []*big.Int, domainGenY *fr.Element) (*SRS, error) {
+func NewSRS(size uint64, tau []*big.Int, domain *PartyDomain, c communicator.Communicator) (*SRS, error) {
+	if domain == nil {
+		domain = NewPartyDomain(c.Size(), false)
+	}
+	if err := domain.check(c.Size()); err != nil {
+		return nil, err
+	}
 
 	_, _, gen1Aff, gen2Aff := bn254.Generators()
 	tau0 := new(fr.Element).SetBigInt(tau[0])
-	// Lagrange Polynomial
-	lagTau0 := lagrangeCalc(mpi.SelfRank, *tau0, domainGenY)
+	// Lagrange polynomials of all the points of the party domain, at tau[0]
+	lagTau0 := domain.LagrangeAt(*tau0)
 
 	var srs SRS
+	srs.Rank = c.Rank()
+	srs.WorldSize = c.Size()
+	srs.Coset = domain.Coset
 
 	var alpha fr.Element
 	alpha.SetBigInt(tau[1])
 
 	srs.G2[0] = gen2Aff
 	srs.G2[1].ScalarMultiplication(&gen2Aff, tau[1])
-
-	lagBigInt := new(big.Int)
-	lagTau0.ToBigIntRegular(lagBigInt)
-	srs.G1 = make([]bn254.G1Affine, size)
-	srs.G1[0].ScalarMultiplication(&gen1Aff, lagBigInt)
+	srs.G2[2].ScalarMultiplication(&gen2Aff, tau[0])
 
 	alphas := make([]fr.Element, size)
-	alphas[0].SetBigInt(lagBigInt)
+	alphas[0].Set(&lagTau0[c.Rank()])
 	for i := 1; i < len(alphas); i++ {
 		alphas[i].Mul(&alphas[i-1], &alpha)
 	}
 	for i := 0; i < len(alphas); i++ {
 		alphas[i].FromMont()
 	}
-	g1s := bn254.BatchScalarMultiplicationG1(&gen1Aff, alphas)
-	copy(srs.G1, g1s)
+	srs.G1 = bn254.BatchScalarMultiplicationG1(&gen1Aff, alphas)
+
+	for i := range lagTau0 {
+		lagTau0[i].FromMont()
+	}
+	srs.Lagrange = bn254.BatchScalarMultiplicationG1(&gen1Aff, lagTau0)
 	return &srs, nil
 }
 
@@ -123,15 +134,45 @@ The distributed commit algorithm computes the following:
  1. Each node has a polynomial f_i(y) = \sum_{j=0}^{N-1} f_{i, j} * y^j.
  2. The commit algorithm commits to: F(x, y) = \sum_{i=0}^{M-1} f_i(y) * L_i(x), where L_i(x) is the Lagrange basis polynomial of i.
  3. The commitment is g^{F(\tau[0], \tau[1])} = \Pi_{i=0}^{M-1} g^{f_i(\tau[1]) * L_i(\tau[0])} = \Pi_{i=0}^{M-1}\Pi_{j=0}^{N-1} g^{f_{i, j} * \tau{1}^j * L_i(\tau[0])} = \Pi_{i=0}^{M-1}\Pi_{j=0}^{N-1} (g^{L_i(\tau[0])*\tau[1]^j}) ^ f_{i, j} = \Pi_{i=0}^{M-1}\Pi_{j=0}^{N-1} U ^ f_{i, j}, where U = srs.G1[j].
+
+Only the root node returns the commitment, the other nodes return an empty Digest, see AllCommit.
 */
-func Commit(p []fr.Element, srs *SRS, nbTasks ...int) (Digest, error) {
-	// Each compute node computes the commitment of its own polynomial
-	// and sends the commitment to the root node
-	// The root node computes the final commitment
-	// and sends the final commitment to all compute nodes
+func Commit(p []fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (Digest, error) {
+	// Each compute node computes the commitment of its own polynomial,
+	// the commitments are summed along a tree rooted at the root node
+	subCom, err := commitShare(p, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, err
+	}
+	subComBytes := G1AffineToBytes(subCom)
+	if err := c.Reduce(subComBytes, 0, addG1); err != nil {
+		return Digest{}, err
+	}
+	if c.Rank() != 0 {
+		// Only the root node returns the final commitment
+		return Digest{}, nil
+	}
+	return BytesToG1Affine(subComBytes), nil
+}
 
+// AllCommit is Commit, with the commitment returned to all the nodes so that
+// each of them can keep running the protocol transcript.
+func AllCommit(p []fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (Digest, error) {
+	subCom, err := commitShare(p, srs, nbTasks...)
+	if err != nil {
+		return Digest{}, err
+	}
+	subComBytes := G1AffineToBytes(subCom)
+	if err := c.AllReduce(subComBytes, addG1); err != nil {
+		return Digest{}, err
+	}
+	return BytesToG1Affine(subComBytes), nil
+}
+
+// commitShare commits to the polynomial of the node, that is f_i(Y) * L_i(\tau[0])
+func commitShare(p []fr.Element, srs *SRS, nbTasks ...int) (bn254.G1Affine, error) {
 	if len(p) == 0 || len(p) > len(srs.G1) {
-		return Digest{}, ErrInvalidPolynomialSize
+		return bn254.G1Affine{}, ErrInvalidPolynomialSize
 	}
 
 	var res bn254.G1Affine
@@ -142,34 +183,24 @@ func Commit(p []fr.Element, srs *SRS, nbTasks ...int) (Digest, error) {
 	}
 
 	if _, err := res.MultiExp(srs.G1[:len(p)], p, config); err != nil {
-		return Digest{}, err
+		return bn254.G1Affine{}, err
 	}
+	return res, nil
+}
 
-	// Aggregate commitments
-	if mpi.SelfRank == 0 {
-		//Root node
-		subCom := make([]Digest, mpi.WorldSize)
-		subCom[0] = res
-		for i := 1; i < int(mpi.WorldSize); i++ {
-			subComBytes, err := mpi.ReceiveBytes(bn254.SizeOfG1AffineUncompressed, uint64(i))
-			if err != nil {
-				return Digest{}, err
-			}
-			subCom[i] = BytesToG1Affine(subComBytes)
-		}
-		finalRes := subCom[0]
-		for i := 1; i < int(mpi.WorldSize); i++ {
-			finalRes.Add(&finalRes, &subCom[i])
-		}
-		return finalRes, nil
+// addG1 is the communicator.ReduceOp summing G1 points encoded with G1AffineToBytes,
+// or arrays of them, element wise
+func addG1(acc, buf []byte) error {
+	if len(acc) != len(buf) || len(acc)%bn254.SizeOfG1AffineUncompressed != 0 {
+		return ErrInvalidMessage
 	}
-
-	// Other nodes
-	if err := mpi.SendBytes(G1AffineToBytes(res), 0); err != nil {
-		return Digest{}, err
+	for i := 0; i < len(acc); i += bn254.SizeOfG1AffineUncompressed {
+		a := BytesToG1Affine(acc[i:])
+		b := BytesToG1Affine(buf[i:])
+		a.Add(&a, &b)
+		copy(acc[i:], G1AffineToBytes(a))
 	}
-	// Only the root node returns the final commitment
-	return Digest{}, nil
+	return nil
 }
 
 // implements io.ReaderFrom and io.WriterTo
@@ -179,6 +210,9 @@ type OpeningProof struct {
 
 	// ClaimedDigest purported the digest of value
 	ClaimedDigest bn254.G1Affine
+
+	// ClaimedValues purported values f_i(y), ClaimedValues[i] is the value of the party i
+	ClaimedValues []fr.Element
 }
 
 // dividePolyByXminusA computes (f-f(a))/(x-a), in canonical basis, in regular form
@@ -208,7 +242,7 @@ New commitment is g^{F'(\tau[0])} = \Pi_{i=0}^{M-1} g^{f_i(y_0) * L_i(\tau[0])}
 Proof that old commitment is consistent with new commitment:
 F(\tau[0], \tau[1]) - F(x, \tau[1]) / (\tau[0] - x) = h(x)
 */
-func Open(p []fr.Element, y fr.Element, srs *SRS, nbTasks ...int) (OpeningProof, []fr.Element, error) {
+func Open(p []fr.Element, y fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (OpeningProof, []fr.Element, error) {
 	if len(p) == 0 || len(p) > len(srs.G1) {
 		return OpeningProof{}, nil, ErrInvalidPolynomialSize
 	}
@@ -237,51 +271,37 @@ func Open(p []fr.Element, y fr.Element, srs *SRS, nbTasks ...int) (OpeningProof,
 	_p = nil // h re-use this memory
 
 	// commit to H
-	comH, err := Commit(h, srs)
+	comH, err := Commit(h, srs, c)
 	if err != nil {
 		return OpeningProof{}, nil, err
 	}
 
 	// Send the new commitment to the root node
-	if mpi.SelfRank == 0 {
-		// Root node
-		allFY := make([]fr.Element, mpi.WorldSize)
-		allComFY := make([]bn254.G1Affine, mpi.WorldSize)
-		allFY[0] = fY
-		allComFY[0] = comFY
-		for i := 1; i < int(mpi.WorldSize); i++ {
-			fYBytes, err := mpi.ReceiveBytes(fr.Bytes, uint64(i))
-			if err != nil {
-				return OpeningProof{}, nil, err
-			}
-			allFY[i].SetBytes(fYBytes)
-			comFyBytes, err := mpi.ReceiveBytes(bn254.SizeOfG1AffineUncompressed, uint64(i))
-			if err != nil {
-				return OpeningProof{}, nil, err
-			}
-			allComFY[i] = BytesToG1Affine(comFyBytes)
-		}
-
-		comFY = allComFY[0]
-		for i := 1; i < int(mpi.WorldSize); i++ {
-			comFY.Add(&comFY, &allComFY[i])
-		}
-
-		return OpeningProof{
-			H:             comH,
-			ClaimedDigest: comFY,
-		}, allFY, nil
-	}
-
-	// Other nodes
 	fYBytes := fY.Bytes()
-	if err := mpi.SendBytes(fYBytes[:], 0); err != nil {
+	allFYBytes, err := c.Gather(fYBytes[:], 0)
+	if err != nil {
 		return OpeningProof{}, nil, err
 	}
-	if err := mpi.SendBytes(G1AffineToBytes(comFY), 0); err != nil {
+	comFYBytes := G1AffineToBytes(comFY)
+	if err := c.Reduce(comFYBytes, 0, addG1); err != nil {
 		return OpeningProof{}, nil, err
 	}
-	return OpeningProof{}, nil, nil
+	if c.Rank() != 0 {
+		return OpeningProof{}, nil, nil
+	}
+
+	// Root node
+	allFY := make([]fr.Element, c.Size())
+	for i := 0; i < c.Size(); i++ {
+		allFY[i].SetBytes(allFYBytes[i])
+	}
+	comFY = BytesToG1Affine(comFYBytes)
+
+	return OpeningProof{
+		H:             comH,
+		ClaimedDigest: comFY,
+		ClaimedValues: allFY,
+	}, allFY, nil
 }
 
 // BatchOpeningProof opening proof for many polynomials at the same point
@@ -291,8 +311,11 @@ type BatchOpeningProof struct {
 	// H quotient polynomial Sum_i gamma**i*(f - f(z))/(x-z)
 	H bn254.G1Affine
 
-	// ClaimedValues purported values
+	// ClaimedDigests purported digests of the values
 	ClaimedDigests []bn254.G1Affine
+
+	// ClaimedValues purported values, ClaimedValues[k][i] is the value of the k-th polynomial of the party i
+	ClaimedValues [][]fr.Element
 }
 
 // Verify verifies a KZG opening proof at a single point
@@ -342,13 +365,217 @@ func Verify(commitment *Digest, proof *OpeningProof, point fr.Element, srs *SRS)
 	return nil
 }
 
+// VerifyClaimedValues checks that claimedDigest = ∑ᵢvalues[i][Lᵢ(τ₀)]G₁, values[i] being the value
+// of the party i, as in OpeningProof.ClaimedValues. Verify only checks claimedDigest.
+func VerifyClaimedValues(claimedDigest *Digest, values []fr.Element, srs *SRS) error {
+	if len(values) != srs.WorldSize || len(srs.Lagrange) < len(values) {
+		return ErrInvalidNbDigests
+	}
+	var digest Digest
+	if _, err := digest.MultiExp(srs.Lagrange[:len(values)], values, ecc.MultiExpConfig{ScalarsMont: true}); err != nil {
+		return err
+	}
+	if !digest.Equal(claimedDigest) {
+		return ErrVerifyClaimedValues
+	}
+	return nil
+}
+
+// BivariateOpeningProof opening proof of F(X, Y) at a point (x, y)
+//
+// implements io.ReaderFrom and io.WriterTo
+type BivariateOpeningProof struct {
+	// HY quotient polynomial (F(X, Y) - F(X, y)) / (Y - y)
+	HY bn254.G1Affine
+
+	// ClaimedDigest digest of F(X, y)
+	ClaimedDigest bn254.G1Affine
+
+	// HX quotient polynomial (F(X, y) - F(x, y)) / (X - x)
+	HX bn254.G1Affine
+
+	// ClaimedValue purported value F(x, y)
+	ClaimedValue fr.Element
+}
+
+/*
+OpenBivariate computes an opening proof of F(X, Y) at (x, y).
+
+The first level is the opening of F(X, Y) at Y = y, see Open.
+The root node then opens F'(X) = F(X, y) = \sum_{i=0}^{M-1} f_i(y) * L_i(X) at X = x.
+The quotient h'(X) = (F'(X) - F'(x)) / (X - x) is computed in Lagrange form on the party domain,
+and committed using the g^{L_i(\tau[0])} of srs.Lagrange.
+
+Only the root node returns the proof, the other nodes return an empty proof.
+*/
+func OpenBivariate(p []fr.Element, x, y fr.Element, srs *SRS, c communicator.Communicator, nbTasks ...int) (BivariateOpeningProof, error) {
+	proofY, fY, err := Open(p, y, srs, c, nbTasks...)
+	if err != nil {
+		return BivariateOpeningProof{}, err
+	}
+
+	if c.Rank() != 0 {
+		return BivariateOpeningProof{}, nil
+	}
+
+	// Root node
+	domain := srs.PartyDomain()
+	if len(srs.Lagrange) != int(domain.Cardinality) {
+		return BivariateOpeningProof{}, ErrInvalidPartyDomain
+	}
+
+	// the padded parties hold fᵢ = 0
+	fYPadded := make([]fr.Element, domain.Cardinality)
+	copy(fYPadded, fY)
+	claimedValue, h := divideLagrangeByXminusA(fYPadded, x, domain)
+
+	config := ecc.MultiExpConfig{ScalarsMont: true}
+	if len(nbTasks) > 0 {
+		config.NbTasks = nbTasks[0]
+	}
+	var comH bn254.G1Affine
+	if _, err := comH.MultiExp(srs.Lagrange, h, config); err != nil {
+		return BivariateOpeningProof{}, err
+	}
+
+	return BivariateOpeningProof{
+		HY:            proofY.H,
+		ClaimedDigest: proofY.ClaimedDigest,
+		HX:            comH,
+		ClaimedValue:  claimedValue,
+	}, nil
+}
+
+// divideLagrangeByXminusA returns f(a) and (f-f(a))/(x-a), f and the result being given by their
+// values on the points of domain, padding included.
+func divideLagrangeByXminusA(f []fr.Element, a fr.Element, domain *PartyDomain) (fr.Element, []fr.Element) {
+	n := len(f)
+	points := domain.Points()
+
+	// a - xᵢ, and M.gᴹ inverted in the same batch
+	za, mgm := domain.vanishing(a)
+	aMinusPoints := make([]fr.Element, n+1)
+	k := -1
+	for i := 0; i < n; i++ {
+		aMinusPoints[i].Sub(&a, &points[i])
+		if aMinusPoints[i].IsZero() {
+			k = i
+		}
+	}
+	aMinusPoints[n] = mgm
+	aMinusPointsInv := fr.BatchInvert(aMinusPoints)
+
+	var fa fr.Element
+	if k == -1 {
+		// f(a) = (aᴹ - gᴹ) / (M.gᴹ) * ∑ᵢxᵢfᵢ/(a - xᵢ)
+		var t fr.Element
+		for i := 0; i < n; i++ {
+			t.Mul(&points[i], &aMinusPointsInv[i]).Mul(&t, &f[i])
+			fa.Add(&fa, &t)
+		}
+		fa.Mul(&fa, &za).Mul(&fa, &aMinusPointsInv[n])
+	} else {
+		fa.Set(&f[k])
+	}
+
+	// hᵢ = (fᵢ - f(a)) / (xᵢ - a) when xᵢ ≠ a
+	h := make([]fr.Element, n)
+	for i := 0; i < n; i++ {
+		if i == k {
+			continue
+		}
+		h[i].Sub(&fa, &f[i]).Mul(&h[i], &aMinusPointsInv[i])
+	}
+
+	// if a = xₖ, hₖ = -∑_{i≠k}(xᵢ/xₖ)hᵢ = -∑_{i≠k}ωⁱ⁻ᵏhᵢ
+	if k != -1 {
+		var t fr.Element
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			t.Mul(&points[(i-k+n)%n], &h[i])
+			h[k].Sub(&h[k], &t)
+		}
+		if domain.Coset {
+			// points[j] = g.ωʲ
+			h[k].Mul(&h[k], &domain.FrMultiplicativeGenInv)
+		}
+	}
+
+	return fa, h
+}
+
+// VerifyBivariate verifies an opening proof of F(X, Y) at (x, y)
+func VerifyBivariate(commitment *Digest, proof *BivariateOpeningProof, x, y fr.Element, srs *SRS) error {
+	// first level, F(\tau[0], \tau[1]) -> F(\tau[0], y):
+	// e([F(τ₀, τ₁) - F(τ₀, y) + yH_Y]G₁, G₂).e([-H_Y]G₁, [τ₁]G₂) ==? 1
+	// second level, F(\tau[0], y) -> F(x, y):
+	// e([F(τ₀, y) - F(x, y) + xH_X]G₁, G₂).e([-H_X]G₁, [τ₀]G₂) ==? 1
+	// both checks are folded using a random λ
+
+	_, _, gen1Aff, _ := bn254.Generators()
+
+	var lambda fr.Element
+	if _, err := lambda.SetRandom(); err != nil {
+		return err
+	}
+	var yBigInt, xBigInt, valueBigInt, lambdaBigInt big.Int
+	y.ToBigIntRegular(&yBigInt)
+	x.ToBigIntRegular(&xBigInt)
+	proof.ClaimedValue.ToBigIntRegular(&valueBigInt)
+	lambda.ToBigIntRegular(&lambdaBigInt)
+
+	// [F(τ₀, τ₁) - F(τ₀, y) + yH_Y]G₁
+	var first, tmp bn254.G1Jac
+	first.FromAffine(commitment)
+	tmp.FromAffine(&proof.ClaimedDigest)
+	first.SubAssign(&tmp)
+	tmp.FromAffine(&proof.HY)
+	tmp.ScalarMultiplication(&tmp, &yBigInt)
+	first.AddAssign(&tmp)
+
+	// [F(τ₀, y) - F(x, y) + xH_X]G₁
+	var second bn254.G1Jac
+	second.FromAffine(&proof.ClaimedDigest)
+	tmp.FromAffine(&gen1Aff)
+	tmp.ScalarMultiplication(&tmp, &valueBigInt)
+	second.SubAssign(&tmp)
+	tmp.FromAffine(&proof.HX)
+	tmp.ScalarMultiplication(&tmp, &xBigInt)
+	second.AddAssign(&tmp)
+
+	// first + λ.second
+	second.ScalarMultiplication(&second, &lambdaBigInt)
+	first.AddAssign(&second)
+	var foldedAff bn254.G1Affine
+	foldedAff.FromJacobian(&first)
+
+	// [-H_Y]G₁, [-λH_X]G₁
+	var negHY, negLambdaHX bn254.G1Affine
+	negHY.Neg(&proof.HY)
+	negLambdaHX.ScalarMultiplication(&proof.HX, &lambdaBigInt).Neg(&negLambdaHX)
+
+	check, err := bn254.PairingCheck(
+		[]bn254.G1Affine{foldedAff, negHY, negLambdaHX},
+		[]bn254.G2Affine{srs.G2[0], srs.G2[1], srs.G2[2]},
+	)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return ErrVerifyOpeningProof
+	}
+	return nil
+}
+
 // BatchOpenSinglePoint creates a batch opening proof at point of a list of polynomials.
 // It's an interactive protocol, made non interactive using Fiat Shamir.
 //
 // * point is the point at which the polynomials are opened.
 // * digests is the list of committed polynomials to open, need to derive the challenge using Fiat Shamir.
 // * polynomials is the list of polynomials to open, they are supposed to be of the same size.
-func BatchOpenSinglePoint(polynomials [][]fr.Element, digests []Digest, point fr.Element, hf hash.Hash, srs *SRS) (BatchOpeningProof, [][]fr.Element, error) {
+func BatchOpenSinglePoint(polynomials [][]fr.Element, digests []Digest, point fr.Element, hf hash.Hash, srs *SRS, c communicator.Communicator) (BatchOpeningProof, [][]fr.Element, error) {
 	// check for invalid sizes
 	nbDigests := len(digests)
 	if nbDigests != len(polynomials) {
@@ -376,7 +603,7 @@ func BatchOpenSinglePoint(polynomials [][]fr.Element, digests []Digest, point fr
 		claimedDigests[i].ScalarMultiplication(&srs.G1[0], &claimedValueBigInt)
 	}
 	// derive the challenge γ, binded to the point and the commitments
-	gamma, err := deriveGamma(point, digests, hf, false)
+	gamma, err := deriveGamma(point, digests, hf, c)
 	if err != nil {
 		return BatchOpeningProof{}, nil, err
 	}
@@ -411,60 +638,52 @@ func BatchOpenSinglePoint(polynomials [][]fr.Element, digests []Digest, point fr
 	h := dividePolyByXminusA(foldedPolynomials, fY, point)
 	foldedPolynomials = nil // same memory as h
 
-	comH, err := Commit(h, srs)
+	comH, err := Commit(h, srs, c)
 	if err != nil {
 		return BatchOpeningProof{}, nil, err
 	}
 
-	// Send the new commitment to the root node
-	if mpi.SelfRank == 0 {
-		// Root node
-		allClaimedValues := make([][]fr.Element, nbDigests)
-		allClaimedDigests := make([][]bn254.G1Affine, nbDigests)
-		for k := 0; k < nbDigests; k++ {
-			allClaimedValues[k] = make([]fr.Element, mpi.WorldSize)
-			allClaimedDigests[k] = make([]bn254.G1Affine, mpi.WorldSize)
-			allClaimedValues[k][0] = claimedValues[k]
-			allClaimedDigests[k][0] = claimedDigests[k]
-			for i := 1; i < int(mpi.WorldSize); i++ {
-				claimedValueBytes, err := mpi.ReceiveBytes(fr.Bytes, uint64(i))
-				if err != nil {
-					return BatchOpeningProof{}, nil, err
-				}
-				allClaimedValues[k][i].SetBytes(claimedValueBytes)
-				claimedDigestBytes, err := mpi.ReceiveBytes(bn254.SizeOfG1AffineUncompressed, uint64(i))
-				if err != nil {
-					return BatchOpeningProof{}, nil, err
-				}
-				allClaimedDigests[k][i] = BytesToG1Affine(claimedDigestBytes)
-			}
-		}
-
-		for k := range allClaimedDigests {
-			claimedDigests[k] = allClaimedDigests[k][0]
-			for i := 1; i < int(mpi.WorldSize); i++ {
-				claimedDigests[k].Add(&claimedDigests[k], &allClaimedDigests[k][i])
-			}
-		}
-
-		return BatchOpeningProof{
-			H:              comH,
-			ClaimedDigests: claimedDigests,
-		}, allClaimedValues, nil
+	// Send the claimed values and digests of the node to the root node, in a single frame
+	frame := (&batchOpeningMessage{
+		ClaimedValues:  claimedValues,
+		ClaimedDigests: claimedDigests,
+	}).marshal()
+	frames, err := c.Gather(frame, 0)
+	if err != nil {
+		return BatchOpeningProof{}, nil, err
+	}
+	if c.Rank() != 0 {
+		return BatchOpeningProof{}, nil, nil
 	}
 
-	// Other nodes
+	// Root node
+	allClaimedValues := make([][]fr.Element, nbDigests)
 	for k := 0; k < nbDigests; k++ {
-		claimedValueBytes := claimedValues[k].Bytes()
-		if err := mpi.SendBytes(claimedValueBytes[:], 0); err != nil {
+		allClaimedValues[k] = make([]fr.Element, c.Size())
+	}
+	claimedDigestsJac := make([]bn254.G1Jac, nbDigests)
+	for i := 0; i < c.Size(); i++ {
+		var msg batchOpeningMessage
+		if err := msg.unmarshal(frames[i]); err != nil {
 			return BatchOpeningProof{}, nil, err
 		}
-		if err := mpi.SendBytes(G1AffineToBytes(claimedDigests[k]), 0); err != nil {
-			return BatchOpeningProof{}, nil, err
+		if len(msg.ClaimedValues) != nbDigests {
+			return BatchOpeningProof{}, nil, ErrInvalidMessage
+		}
+		for k := 0; k < nbDigests; k++ {
+			allClaimedValues[k][i] = msg.ClaimedValues[k]
+			claimedDigestsJac[k].AddMixed(&msg.ClaimedDigests[k])
 		}
-
 	}
-	return BatchOpeningProof{}, nil, nil
+	for k := 0; k < nbDigests; k++ {
+		claimedDigests[k].FromJacobian(&claimedDigestsJac[k])
+	}
+
+	return BatchOpeningProof{
+		H:              comH,
+		ClaimedDigests: claimedDigests,
+		ClaimedValues:  allClaimedValues,
+	}, allClaimedValues, nil
 }
 
 // FoldProof fold the digests and the proofs in batchOpeningProof using Fiat Shamir
@@ -482,7 +701,7 @@ func FoldProof(digests []Digest, batchOpeningProof *BatchOpeningProof, point fr.
 	}
 
 	// derive the challenge γ, binded to the point and the commitments
-	gamma, err := deriveGamma(point, digests, hf, true)
+	gamma, err := computeGamma(point, digests, hf)
 	if err != nil {
 		return OpeningProof{}, Digest{}, ErrInvalidNbDigests
 	}
@@ -505,6 +724,25 @@ func FoldProof(digests []Digest, batchOpeningProof *BatchOpeningProof, point fr.
 	res.ClaimedDigest.Set(&foldedEvaluations)
 	res.H.Set(&batchOpeningProof.H)
 
+	// fold the values of each party, if any
+	if len(batchOpeningProof.ClaimedValues) != 0 {
+		if len(batchOpeningProof.ClaimedValues) != nbDigests {
+			return OpeningProof{}, Digest{}, ErrInvalidNbDigests
+		}
+		worldSize := len(batchOpeningProof.ClaimedValues[0])
+		res.ClaimedValues = make([]fr.Element, worldSize)
+		var t fr.Element
+		for k := 0; k < nbDigests; k++ {
+			if len(batchOpeningProof.ClaimedValues[k]) != worldSize {
+				return OpeningProof{}, Digest{}, ErrInvalidNbDigests
+			}
+			for i := 0; i < worldSize; i++ {
+				t.Mul(&batchOpeningProof.ClaimedValues[k][i], &gammai[k])
+				res.ClaimedValues[i].Add(&res.ClaimedValues[i], &t)
+			}
+		}
+	}
+
 	return res, foldedDigests, nil
 }
 
@@ -637,41 +875,48 @@ func fold(di []Digest, fai []bn254.G1Affine, ci []fr.Element) (Digest, Digest, e
 
 }
 
-// deriveGamma derives a challenge using Fiat Shamir to fold proofs.
-func deriveGamma(point fr.Element, digests []Digest, hf hash.Hash, notSend bool) (fr.Element, error) {
-	if mpi.SelfRank == 0 {
-		// derive the challenge gamma, binded to the point and the commitments
-		fs := fiatshamir.NewTranscript(hf, "gamma")
-		if err := fs.Bind("gamma", point.Marshal()); err != nil {
-			return fr.Element{}, err
-		}
-		for i := 0; i < len(digests); i++ {
-			if err := fs.Bind("gamma", digests[i].Marshal()); err != nil {
-				return fr.Element{}, err
-			}
-		}
-		gammaByte, err := fs.ComputeChallenge("gamma")
-		if err != nil {
+// computeGamma derives a challenge using Fiat Shamir to fold proofs.
+func computeGamma(point fr.Element, digests []Digest, hf hash.Hash) (fr.Element, error) {
+	// derive the challenge gamma, binded to the point and the commitments
+	fs := fiatshamir.NewTranscript(hf, "gamma")
+	if err := fs.Bind("gamma", point.Marshal()); err != nil {
+		return fr.Element{}, err
+	}
+	for i := 0; i < len(digests); i++ {
+		if err := fs.Bind("gamma", digests[i].Marshal()); err != nil {
 			return fr.Element{}, err
 		}
-		var gamma fr.Element
-		gamma.SetBytes(gammaByte)
-		gammaByte = nil
-		if notSend {
-			return gamma, nil
-		}
-		buf := gamma.Bytes()
-		for i := 1; i < int(mpi.WorldSize); i++ {
-			mpi.SendBytes(buf[:], uint64(i))
-		}
-		return gamma, nil
-	} else {
-		buf, err := mpi.ReceiveBytes(32, 0)
-		if err != nil {
+	}
+	gammaByte, err := fs.ComputeChallenge("gamma")
+	if err != nil {
+		return fr.Element{}, err
+	}
+	var gamma fr.Element
+	gamma.SetBytes(gammaByte)
+	return gamma, nil
+}
+
+// deriveGamma derives the challenge on the root node, and sends it to all the nodes.
+func deriveGamma(point fr.Element, digests []Digest, hf hash.Hash, c communicator.Communicator) (fr.Element, error) {
+	var gamma fr.Element
+	if c.Rank() == 0 {
+		var err error
+		if gamma, err = computeGamma(point, digests, hf); err != nil {
 			return fr.Element{}, err
 		}
-		var gamma fr.Element
-		gamma.SetBytes(buf)
-		return gamma, nil
 	}
+	if err := broadcastElement(&gamma, c); err != nil {
+		return fr.Element{}, err
+	}
+	return gamma, nil
+}
+
+// broadcastElement sends e from the root node to all the nodes
+func broadcastElement(e *fr.Element, c communicator.Communicator) error {
+	buf := e.Bytes()
+	if err := c.Broadcast(buf[:], 0); err != nil {
+		return err
+	}
+	e.SetBytes(buf[:])
+	return nil
 }
@@ -14,5 +14,6 @@
 
 // Code generated by consensys/gnark-crypto DO NOT EDIT
 
-// Package plookup provides an API to build plookup proofs.
+// Package plookup provides an API to build plookup proofs, with kzg commitments, or with
+// dkzg commitments when the looked up vector is distributed among several parties.
 package plookup
@@ -11,6 +11,7 @@ require (
 	github.com/sunblaze-ucb/simpleMPI v0.0.0-20221116051826-70e801eec087
 	golang.org/x/crypto v0.1.0
 	golang.org/x/sys v0.1.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -18,6 +19,5 @@ require (
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	rsc.io/tmplfunc v0.0.3 // indirect
 )
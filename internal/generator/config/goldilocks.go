@@ -0,0 +1,13 @@
+package config
+
+// Goldilocks is the field of modulus 2⁶⁴ - 2³² + 1. It isn't attached to a curve, its
+// configuration is used to generate the packages which only depend on the scalar field
+// (fft, polynomial).
+var Goldilocks = Curve{
+	Name:      "goldilocks",
+	FrModulus: "18446744069414584321",
+}
+
+func init() {
+	Goldilocks.FrInfo = newFieldInfo(Goldilocks.FrModulus)
+}
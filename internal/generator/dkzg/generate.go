@@ -0,0 +1,33 @@
+package dkzg
+
+import (
+	"path/filepath"
+
+	"github.com/consensys/bavard"
+	"github.com/consensys/gnark-crypto/internal/generator/config"
+)
+
+func Generate(conf config.Curve, baseDir string, bgen *bavard.BatchGenerator) error {
+
+	// distributed kzg commitment scheme
+	conf.Package = "dkzg"
+	entries := []bavard.Entry{
+		{File: filepath.Join(baseDir, "doc.go"), Templates: []string{"doc.go.tmpl"}},
+		{File: filepath.Join(baseDir, "accountable.go"), Templates: []string{"accountable.go.tmpl"}},
+		{File: filepath.Join(baseDir, "accountable_test.go"), Templates: []string{"accountable.test.go.tmpl"}},
+		{File: filepath.Join(baseDir, "dkzg.go"), Templates: []string{"dkzg.go.tmpl"}},
+		{File: filepath.Join(baseDir, "dkzg_test.go"), Templates: []string{"dkzg.test.go.tmpl"}},
+		{File: filepath.Join(baseDir, "domain.go"), Templates: []string{"domain.go.tmpl"}},
+		{File: filepath.Join(baseDir, "fft.go"), Templates: []string{"fft.go.tmpl"}},
+		{File: filepath.Join(baseDir, "fft_test.go"), Templates: []string{"fft.test.go.tmpl"}},
+		{File: filepath.Join(baseDir, "hiding.go"), Templates: []string{"hiding.go.tmpl"}},
+		{File: filepath.Join(baseDir, "hiding_test.go"), Templates: []string{"hiding.test.go.tmpl"}},
+		{File: filepath.Join(baseDir, "marshal.go"), Templates: []string{"marshal.go.tmpl"}},
+		{File: filepath.Join(baseDir, "multipoints.go"), Templates: []string{"multipoints.go.tmpl"}},
+		{File: filepath.Join(baseDir, "multipoints_test.go"), Templates: []string{"multipoints.test.go.tmpl"}},
+		{File: filepath.Join(baseDir, "setup.go"), Templates: []string{"setup.go.tmpl"}},
+		{File: filepath.Join(baseDir, "setup_test.go"), Templates: []string{"setup.test.go.tmpl"}},
+	}
+	return bgen.Generate(conf, conf.Package, "./dkzg/template/", entries...)
+
+}
@@ -16,5 +16,12 @@ func Generate(conf config.Curve, baseDir string, bgen *bavard.BatchGenerator) er
 		{File: filepath.Join(baseDir, "fft_test.go"), Templates: []string{"tests/fft.go.tmpl", "imports.go.tmpl"}},
 		{File: filepath.Join(baseDir, "fft.go"), Templates: []string{"fft.go.tmpl", "imports.go.tmpl"}},
 	}
+	// the FFTs of points are only generated for curves
+	if conf.CurvePackage != "" {
+		entries = append(entries,
+			bavard.Entry{File: filepath.Join(baseDir, "fftgroup_test.go"), Templates: []string{"tests/fftgroup.go.tmpl", "imports.go.tmpl"}},
+			bavard.Entry{File: filepath.Join(baseDir, "fftgroup.go"), Templates: []string{"fftgroup.go.tmpl", "imports.go.tmpl"}},
+		)
+	}
 	return bgen.Generate(conf, conf.Package, "./fft/template/", entries...)
 }
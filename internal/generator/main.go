@@ -12,6 +12,7 @@ import (
 	"github.com/consensys/gnark-crypto/internal/field/generator"
 	"github.com/consensys/gnark-crypto/internal/generator/config"
 	"github.com/consensys/gnark-crypto/internal/generator/crypto/hash/mimc"
+	"github.com/consensys/gnark-crypto/internal/generator/dkzg"
 	"github.com/consensys/gnark-crypto/internal/generator/ecc"
 	"github.com/consensys/gnark-crypto/internal/generator/edwards"
 	"github.com/consensys/gnark-crypto/internal/generator/edwards/eddsa"
@@ -33,6 +34,9 @@ const (
 
 var bgen = bavard.NewBatchGenerator(copyrightHolder, copyrightYear, "consensys/gnark-crypto")
 
+// dkzgGen keeps the copyright of the original authors of the distributed KZG scheme
+var dkzgGen = bavard.NewBatchGenerator("Tianyi Liu and Tiancheng Xie", 2023, "consensys/gnark-crypto")
+
 //go:generate go run main.go
 func main() {
 	var wg sync.WaitGroup
@@ -68,6 +72,9 @@ func main() {
 			// generate kzg on fr
 			assertNoError(kzg.Generate(conf, filepath.Join(curveDir, "fr", "kzg"), bgen))
 
+			// generate distributed kzg on fr
+			assertNoError(dkzg.Generate(conf, filepath.Join(curveDir, "fr", "dkzg"), dkzgGen))
+
 			// generate plookup on fr
 			assertNoError(plookup.Generate(conf, filepath.Join(curveDir, "fr", "plookup"), bgen))
 
@@ -110,6 +117,11 @@ func main() {
 
 	wg.Wait()
 
+	// generate fft and polynomial on the goldilocks field
+	goldilocksDir := filepath.Join(baseDir, "field", "goldilocks")
+	assertNoError(fft.Generate(config.Goldilocks, filepath.Join(goldilocksDir, "fft"), bgen))
+	assertNoError(polynomial.Generate(config.Goldilocks, filepath.Join(goldilocksDir, "polynomial"), bgen))
+
 	// format the whole directory
 
 	cmd := exec.Command("gofmt", "-s", "-w", baseDir)